@@ -1,16 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
 	"github.com/HarshKanjiya/escape-form-api/docs"
+	"github.com/HarshKanjiya/escape-form-api/internal/auth"
 	"github.com/HarshKanjiya/escape-form-api/internal/config"
 	"github.com/HarshKanjiya/escape-form-api/internal/database"
+	"github.com/HarshKanjiya/escape-form-api/internal/mailer"
 	"github.com/HarshKanjiya/escape-form-api/internal/middlewares"
 	"github.com/HarshKanjiya/escape-form-api/internal/routes"
 	"github.com/HarshKanjiya/escape-form-api/internal/storage"
-	"github.com/clerk/clerk-sdk-go/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
@@ -38,8 +40,6 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
-	clerk.SetKey(cfg.Clerk.SecretKey)
-
 	// Setup logger
 	zerolog.TimeFieldFormat = time.RFC3339
 	if cfg.Logging.Level == "debug" {
@@ -50,6 +50,18 @@ func main() {
 		zerolog.SetGlobalLevel(zerolog.WarnLevel)
 	}
 
+	// Select how session tokens are verified
+	authenticator, err := auth.NewAuthenticator(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up authentication:", err)
+	}
+
+	// Select how emails are delivered
+	mail, err := mailer.NewMailer(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up email delivery:", err)
+	}
+
 	// Connect to database
 	if err := database.Connect(cfg); err != nil {
 		log.Fatal("Failed to connect to database:", err)
@@ -66,6 +78,11 @@ func main() {
 	// 	log.Fatal("Failed to run migrations:", err)
 	// }
 
+	// Apply schema migrations
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to apply migrations:", err)
+	}
+
 	app := fiber.New(fiber.Config{
 		AppName:               cfg.App.Name,
 		ErrorHandler:          middlewares.ErrorHandler,
@@ -74,7 +91,12 @@ func main() {
 
 	middlewares.SetupMiddlewares(app, cfg)
 
-	routes.SetupRoutes(app, cfg)
+	scheduler := routes.SetupRoutes(app, cfg, authenticator, mail)
+
+	// Run scheduled form transitions and the trash purge in the background
+	if cfg.Scheduler.Enabled {
+		go scheduler.Start(context.Background())
+	}
 
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
@@ -0,0 +1,36 @@
+// Command token signs an HS256 session token for AUTH_PROVIDER=jwt, for local
+// development and e2e tests:
+//
+//	go run ./cmd/token -sub user_123 -email dev@example.com
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/auth"
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	sub := flag.String("sub", "", "user ID to sign the token for")
+	email := flag.String("email", "", "email claim, optional")
+	expiry := flag.Duration("expiry", cfg.JWT.Expiry, "token lifetime")
+	flag.Parse()
+
+	if *sub == "" {
+		log.Fatal("-sub is required")
+	}
+
+	token, err := auth.SignToken(&cfg.JWT, *sub, *email, *expiry)
+	if err != nil {
+		log.Fatal("Failed to sign token:", err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+)
+
+// APIKeyVerifier resolves a presented API key to the active key it belongs to
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+// APIKeyAuthenticator authenticates team API keys as their creator
+type APIKeyAuthenticator struct {
+	keys APIKeyVerifier
+}
+
+func NewAPIKeyAuthenticator(keys APIKeyVerifier) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{keys: keys}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	key, err := a.keys.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		UserID:   key.CreatedBy,
+		Provider: ProviderAPIKey,
+		APIKey:   key,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+)
+
+// Provider is how a request was authenticated
+type Provider string
+
+const (
+	ProviderClerk  Provider = "clerk"
+	ProviderJWT    Provider = "jwt"
+	ProviderAPIKey Provider = "api_key"
+)
+
+// IdentityKey is the Fiber locals key holding the request's *Identity
+const IdentityKey = "identity"
+
+// Identity is the caller of an authenticated request
+type Identity struct {
+	UserID string
+	// Email is empty when the provider's tokens do not carry it
+	Email    string
+	Provider Provider
+	// APIKey is set when the request uses an API key; UserID is then its creator
+	APIKey *models.APIKey
+}
+
+// Authenticator verifies a bearer token and resolves who presented it
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// NewAuthenticator returns the session authenticator selected by AUTH_PROVIDER
+func NewAuthenticator(cfg *config.Config) (Authenticator, error) {
+	switch Provider(strings.ToLower(cfg.Auth.Provider)) {
+	case ProviderClerk:
+		return NewClerkAuthenticator(cfg.Clerk.SecretKey), nil
+	case ProviderJWT:
+		return NewJWTAuthenticator(&cfg.JWT)
+	default:
+		return nil, fmt.Errorf("unknown auth provider %q", cfg.Auth.Provider)
+	}
+}
+
+// FromContext returns the identity of the request, if any. Fiber locals are request
+// user values, which c.Context() exposes through Value.
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(IdentityKey).(*Identity)
+	return identity
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/jwt"
+)
+
+// ClerkAuthenticator verifies Clerk session tokens
+type ClerkAuthenticator struct{}
+
+func NewClerkAuthenticator(secretKey string) *ClerkAuthenticator {
+	clerk.SetKey(secretKey)
+	return &ClerkAuthenticator{}
+}
+
+func (a *ClerkAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	claims, err := jwt.Verify(ctx, &jwt.VerifyParams{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		UserID:   claims.Subject,
+		Provider: ProviderClerk,
+	}, nil
+}
@@ -14,12 +14,24 @@ import (
 type Config struct {
 	App       AppConfig
 	Database  DatabaseConfig
+	Auth      AuthConfig
 	JWT       JWTConfig
 	CORS      CORSConfig
 	RateLimit RateLimitConfig
 	Logging   LoggingConfig
 	Clerk     ClerkConfig
 	AWS       AWSConfig
+	Prefill   PrefillConfig
+	Domains   DomainsConfig
+	Scheduler SchedulerConfig
+	Trash     TrashConfig
+	Mail      MailConfig
+	Invites   InvitesConfig
+	Teams     TeamsConfig
+	Support   SupportConfig
+	Audit     AuditConfig
+	Usage     UsageConfig
+	Billing   BillingConfig
 }
 
 // application-level configuration
@@ -40,11 +52,22 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
-// JWT authentication configuration
+// authentication configuration
+type AuthConfig struct {
+	// Provider verifies session tokens: "clerk", or "jwt" for self-issued tokens
+	Provider string
+}
+
+// self-issued JWT configuration, used when the auth provider is "jwt"
 type JWTConfig struct {
-	Secret        string
-	Expiry        time.Duration
-	RefreshExpiry time.Duration
+	Algorithm string
+	Secret    string
+	// PublicKey is the PEM encoded RS256 verification key
+	PublicKey string
+	Issuer    string
+	Audience  string
+	// Expiry is the lifetime of tokens signed by cmd/token
+	Expiry time.Duration
 }
 
 // CORS configuration
@@ -70,6 +93,90 @@ type ClerkConfig struct {
 	SecretKey string
 }
 
+// signed prefill link configuration
+type PrefillConfig struct {
+	// Secret signs prefill links; they can be neither created nor used while it is empty
+	Secret    string
+	Expiry    time.Duration
+	MaxExpiry time.Duration
+}
+
+// form hosting and custom domain configuration
+type DomainsConfig struct {
+	FormHost    string
+	CNAMETarget string
+	Resolver    string
+	// StaticRecords seeds the static resolver, as "TXT name value;CNAME name target"
+	StaticRecords string
+}
+
+// background job scheduler configuration
+type SchedulerConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	Lease     time.Duration
+	BatchSize int
+}
+
+// soft-deleted item retention configuration
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+	PurgeBatch    int
+}
+
+// outgoing email configuration
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// team invitation configuration
+type InvitesConfig struct {
+	// URL is the dashboard page accepting invitations; the token is appended as ?token=
+	URL    string
+	Expiry time.Duration
+}
+
+// team ownership configuration
+type TeamsConfig struct {
+	// TransferExpiry is how long a proposed ownership transfer can be accepted
+	TransferExpiry time.Duration
+}
+
+// support staff configuration
+type SupportConfig struct {
+	// AdminIDs are the user IDs allowed to override team ownership
+	AdminIDs []string
+}
+
+// audit log configuration
+type AuditConfig struct {
+	// Retention is how long audit entries are kept; they are purged with the trash
+	Retention time.Duration
+}
+
+// usage metering configuration
+type UsageConfig struct {
+	// AggregateInterval is how often usage events are rolled up into usage records
+	AggregateInterval time.Duration
+}
+
+// subscription billing cycle configuration
+type BillingConfig struct {
+	// Interval is how often subscriptions are checked for ended cycles and limits
+	Interval time.Duration
+	// GracePeriod is how long a team over its limits or with a failed payment has
+	// before it is blocked
+	GracePeriod time.Duration
+	// FreePlanID is the plan a team falls back to when its subscription is cancelled
+	FreePlanID string
+}
+
 type AWSConfig struct {
 	AccessKey  string
 	SecretKey  string
@@ -106,10 +213,16 @@ func Load() (*Config, error) {
 			DBName:   getEnv("DB_NAME", "escape_form"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
+		Auth: AuthConfig{
+			Provider: getEnv("AUTH_PROVIDER", "clerk"),
+		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "default-secret-change-this"),
-			Expiry:        parseDuration(getEnv("JWT_EXPIRY", "24h")),
-			RefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h")),
+			Algorithm: getEnv("JWT_ALGORITHM", "HS256"),
+			Secret:    getEnv("JWT_SECRET", ""),
+			PublicKey: strings.ReplaceAll(getEnv("JWT_PUBLIC_KEY", ""), `\n`, "\n"),
+			Issuer:    getEnv("JWT_ISSUER", ""),
+			Audience:  getEnv("JWT_AUDIENCE", ""),
+			Expiry:    parseDuration(getEnv("JWT_EXPIRY", "24h")),
 		},
 		CORS: CORSConfig{
 			Origins: splitAndTrim(getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:8080,http://127.0.0.1:3000,http://127.0.0.1:8080,https://escform.com,https://dashboard.escform.com,https://form.escform.com")),
@@ -133,6 +246,63 @@ func Load() (*Config, error) {
 			BucketName: getEnv("AWS_BUCKET_NAME", ""),
 			EndPoint:   getEnv("AWS_ENDPOINT", ""),
 		},
+		Prefill: PrefillConfig{
+			Secret:    getEnv("PREFILL_SECRET", ""),
+			Expiry:    parseDuration(getEnv("PREFILL_EXPIRY", "168h")),
+			MaxExpiry: parseDuration(getEnv("PREFILL_MAX_EXPIRY", "2160h")),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:   getEnv("SCHEDULER_ENABLED", "true") == "true",
+			Interval:  parseDuration(getEnv("SCHEDULER_INTERVAL", "30s")),
+			Lease:     parseDuration(getEnv("SCHEDULER_LEASE", "5m")),
+			BatchSize: getEnvAsInt("SCHEDULER_BATCH_SIZE", 20),
+		},
+		Trash: TrashConfig{
+			Retention:     time.Duration(getEnvAsInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			PurgeInterval: parseDuration(getEnv("TRASH_PURGE_INTERVAL", "1h")),
+			PurgeBatch:    getEnvAsInt("TRASH_PURGE_BATCH", 50),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@escform.com"),
+			SMTPHost:     getEnv("MAIL_SMTP_HOST", ""),
+			SMTPPort:     getEnvAsInt("MAIL_SMTP_PORT", 587),
+			SMTPUsername: getEnv("MAIL_SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("MAIL_SMTP_PASSWORD", ""),
+		},
+		Invites: InvitesConfig{
+			URL:    getEnv("INVITE_URL", "https://dashboard.escform.com/invite"),
+			Expiry: parseDuration(getEnv("INVITE_EXPIRY", "168h")),
+		},
+		Teams: TeamsConfig{
+			TransferExpiry: parseDuration(getEnv("OWNERSHIP_TRANSFER_EXPIRY", "168h")),
+		},
+		Support: SupportConfig{
+			AdminIDs: splitAndTrim(getEnv("SUPPORT_ADMIN_IDS", "")),
+		},
+		Audit: AuditConfig{
+			Retention: time.Duration(getEnvAsInt("AUDIT_RETENTION_DAYS", 365)) * 24 * time.Hour,
+		},
+		Usage: UsageConfig{
+			AggregateInterval: parseDuration(getEnv("USAGE_AGGREGATE_INTERVAL", "5m")),
+		},
+		Billing: BillingConfig{
+			Interval:    parseDuration(getEnv("BILLING_INTERVAL", "1h")),
+			GracePeriod: parseDuration(getEnv("BILLING_GRACE_PERIOD", "168h")),
+			FreePlanID:  getEnv("BILLING_FREE_PLAN_ID", ""),
+		},
+		Domains: DomainsConfig{
+			FormHost:      getEnv("FORM_HOST", "form.escform.com"),
+			CNAMETarget:   getEnv("DOMAIN_CNAME_TARGET", getEnv("FORM_HOST", "form.escform.com")),
+			Resolver:      getEnv("DOMAIN_RESOLVER", "net"),
+			StaticRecords: getEnv("DOMAIN_STATIC_RECORDS", ""),
+		},
+	}
+
+	// Without a secret no prefill link could be signed or checked, so refuse to start
+	// rather than fail on the first one
+	if cfg.Prefill.Secret == "" {
+		return nil, fmt.Errorf("PREFILL_SECRET is required")
 	}
 
 	return cfg, nil
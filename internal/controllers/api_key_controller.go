@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyController struct {
+	validator     *validator.Validate
+	apiKeyService services.IAPIKeyService
+}
+
+func NewAPIKeyController(service services.IAPIKeyService) *APIKeyController {
+	return &APIKeyController{
+		validator:     validator.New(),
+		apiKeyService: service,
+	}
+}
+
+// @Summary Create an API key
+// @Description Create a key acting for the current user in the team, limited to its scopes. The key is only returned in this response.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param body body types.CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} types.CreateAPIKeyResponse
+// @Router /teams/{id}/api-keys [post]
+func (ac *APIKeyController) Create(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	body := new(types.CreateAPIKeyRequest)
+	if err := c.BodyParser(body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := ac.validator.Struct(body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	key, err := ac.apiKeyService.Create(c.Context(), userId, teamId, body)
+	if err != nil {
+		return err
+	}
+	return utils.Created(c, key, "API key created successfully")
+}
+
+// @Summary Get API keys
+// @Description Retrieve the team's API keys, including revoked and expired ones
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {array} types.APIKeyResponse
+// @Router /teams/{id}/api-keys [get]
+func (ac *APIKeyController) List(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	keys, err := ac.apiKeyService.List(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, keys, "API keys fetched successfully")
+}
+
+// @Summary Revoke an API key
+// @Description Revoke an API key immediately
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/api-keys/{keyId} [delete]
+func (ac *APIKeyController) Revoke(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	keyId := c.Params("keyId")
+	if teamId == "" || keyId == "" {
+		return errors.BadRequest("Team ID and API key ID are required")
+	}
+
+	if err := ac.apiKeyService.Revoke(c.Context(), userId, teamId, keyId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "API key revoked successfully")
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditController struct {
+	auditService services.IAuditService
+}
+
+func NewAuditController(service services.IAuditService) *AuditController {
+	return &AuditController{
+		auditService: service,
+	}
+}
+
+// @Summary Get the audit log
+// @Description Retrieve the team's audit log, newest first. Each entry holds the changed fields with their values before and after.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param actorId query string false "User who made the change"
+// @Param resourceType query string false "Resource type, e.g. FORM or QUESTION"
+// @Param resourceId query string false "Resource ID"
+// @Param action query string false "Action, e.g. UPDATE or PUBLISH"
+// @Param from query string false "Earliest time, RFC 3339"
+// @Param to query string false "Latest time (exclusive), RFC 3339"
+// @Success 200 {array} types.AuditLogResponse
+// @Router /teams/{id}/audit [get]
+func (ac *AuditController) List(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	pagination := &types.PaginationQuery{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+	query := &types.AuditLogQuery{
+		ActorID:      c.Query("actorId"),
+		ResourceType: c.Query("resourceType"),
+		ResourceID:   c.Query("resourceId"),
+		Action:       c.Query("action"),
+		From:         c.Query("from"),
+		To:           c.Query("to"),
+	}
+
+	entries, totalCount, err := ac.auditService.List(c.Context(), userId, teamId, query, pagination)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, entries, "Audit log fetched successfully", totalCount)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BillingController struct {
+	billingService services.IBillingService
+}
+
+func NewBillingController(service services.IBillingService) *BillingController {
+	return &BillingController{
+		billingService: service,
+	}
+}
+
+// @Summary Record a failed payment
+// @Description Support staff only. Marks the team's subscription as unpaid, which puts the team into its grace period and blocks it when the period ends.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/teams/{id}/payment-failed [post]
+func (bc *BillingController) PaymentFailed(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	if err := bc.billingService.PaymentFailed(c.Context(), userId, teamId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Payment failure recorded successfully")
+}
+
+// @Summary Record a recovered payment
+// @Description Support staff only. Clears a failed payment, making the team active again when it is within its limits.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/teams/{id}/payment-recovered [post]
+func (bc *BillingController) PaymentRecovered(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	if err := bc.billingService.PaymentRecovered(c.Context(), userId, teamId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Payment recovery recorded successfully")
+}
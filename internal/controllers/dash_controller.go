@@ -59,12 +59,17 @@ func (pc *DashController) GetAnalytics(c *fiber.Ctx) error {
 // @Router /dashboard/{formId}/questions [get]
 func (pc *DashController) GetQuestions(c *fiber.Ctx) error {
 
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
 	formId := c.Params("formId", "")
 	if formId == "" {
 		return errors.BadRequest("Form ID is required")
 	}
 
-	questions, err := pc.dashService.GetQuestions(c.Context(), formId)
+	questions, err := pc.dashService.GetQuestions(c.Context(), userId, formId)
 	if err != nil {
 		return err
 	}
@@ -100,6 +105,131 @@ func (pc *DashController) GetResponses(c *fiber.Ctx) error {
 	return utils.Success(c, responses, "Responses fetched successfully")
 }
 
+// @Summary Update a response
+// @Description Replace the tags of a response. Requires the responses:edit permission.
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param responseId path string true "Response ID"
+// @Param body body types.UpdateResponseRequest true "Response changes"
+// @Success 200 {object} models.Response
+// @Router /dashboard/{formId}/responses/{responseId} [patch]
+func (pc *DashController) UpdateResponse(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	responseId := c.Params("responseId", "")
+	if formId == "" || responseId == "" {
+		return errors.BadRequest("Form ID and Response ID are required")
+	}
+
+	var body types.UpdateResponseRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	response, err := pc.dashService.UpdateResponse(c.Context(), userId, formId, responseId, &body)
+	if err != nil {
+		return err
+	}
+
+	return utils.Success(c, response, "Response updated successfully")
+}
+
+// @Summary Delete a response
+// @Description Remove a response from reports, analytics and exports. Requires the responses:edit permission.
+// @Tags dashboard
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param responseId path string true "Response ID"
+// @Success 200 {object} types.ResponseObj
+// @Router /dashboard/{formId}/responses/{responseId} [delete]
+func (pc *DashController) DeleteResponse(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	responseId := c.Params("responseId", "")
+	if formId == "" || responseId == "" {
+		return errors.BadRequest("Form ID and Response ID are required")
+	}
+
+	if err := pc.dashService.DeleteResponse(c.Context(), userId, formId, responseId); err != nil {
+		return err
+	}
+
+	return utils.Success(c, nil, "Response deleted successfully")
+}
+
+// @Summary Export form responses
+// @Description Download all responses as CSV, with one column per question and per computed variable
+// @Tags dashboard
+// @Produce text/csv
+// @Param formId path string true "Form ID"
+// @Success 200 {string} string "CSV file"
+// @Router /dashboard/{formId}/responses/export [get]
+func (pc *DashController) ExportResponses(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	content, err := pc.dashService.ExportResponses(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="responses-`+formId+`.csv"`)
+	return c.Send(content)
+}
+
+// @Summary Get quiz difficulty report
+// @Description Per-question percent correct across graded responses, hardest first
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {object} types.QuizReport
+// @Router /dashboard/{formId}/quiz [get]
+func (pc *DashController) GetQuizReport(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	report, err := pc.dashService.GetQuizReport(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+
+	return utils.Success(c, report, "Quiz report fetched successfully")
+}
+
 // @Summary Get form passwords
 // @Description Retrieve passwords for a form
 // @Tags dashboard
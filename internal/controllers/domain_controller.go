@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DomainController struct {
+	validator     *validator.Validate
+	domainService services.IDomainService
+}
+
+func NewDomainController(service services.IDomainService) *DomainController {
+	return &DomainController{
+		validator:     validator.New(),
+		domainService: service,
+	}
+}
+
+// @Summary Get custom domain
+// @Description Retrieve the custom domain of a form, its verification status and the DNS records to create
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {object} types.CustomDomainResponse
+// @Router /forms/{formId}/domain [get]
+func (dc *DomainController) Get(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	domain, err := dc.domainService.Get(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, domain, "Custom domain fetched successfully")
+}
+
+// @Summary Set custom domain
+// @Description Claim a custom domain for a form. The form answers on it once the TXT challenge is verified.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.SetCustomDomainRequest true "Domain"
+// @Success 200 {object} types.CustomDomainResponse
+// @Router /forms/{formId}/domain [put]
+func (dc *DomainController) Set(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.SetCustomDomainRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := dc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	domain, err := dc.domainService.Set(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, domain, "Custom domain saved successfully")
+}
+
+// @Summary Verify custom domain
+// @Description Check the DNS challenge of the form's custom domain
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {object} types.CustomDomainResponse
+// @Router /forms/{formId}/domain/verify [post]
+func (dc *DomainController) Verify(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	domain, err := dc.domainService.Verify(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, domain, "Custom domain checked successfully")
+}
+
+// @Summary Remove custom domain
+// @Description Remove the custom domain of a form
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/domain [delete]
+func (dc *DomainController) Delete(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	if err := dc.domainService.Delete(c.Context(), userId, formId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Custom domain removed successfully")
+}
@@ -3,7 +3,6 @@ package controllers
 import (
 	"log"
 
-	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/internal/services"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
@@ -119,13 +118,14 @@ func (pc *FormController) GetById(c *fiber.Ctx) error {
 }
 
 // @Summary Update form status
-// @Description Update the status of a form by ID
+// @Description Move a form to another status. Allowed: DRAFT to PUBLISHED or ARCHIVED, PUBLISHED to CLOSED or DRAFT, CLOSED to PUBLISHED, DRAFT or ARCHIVED, ARCHIVED to DRAFT. Moving to PUBLISHED reopens the current version.
 // @Tags forms
 // @Accept json
 // @Produce json
 // @Param id path string true "Form ID"
+// @Param body body types.UpdateFormStatusRequest true "New status"
 // @Success 200 {object} map[string]interface{}
-// @Router /forms/{id}/status [patch]
+// @Router /forms/{id}/status [post]
 func (pc *FormController) UpdateStatus(c *fiber.Ctx) error {
 
 	userId, ok := utils.GetUserId(c)
@@ -138,9 +138,7 @@ func (pc *FormController) UpdateStatus(c *fiber.Ctx) error {
 		return errors.BadRequest("Form ID is required")
 	}
 
-	var body struct {
-		Action string `json:"action"`
-	}
+	var body types.UpdateFormStatusRequest
 
 	if err := c.BodyParser(&body); err != nil {
 		log.Println("Error parsing body:", err)
@@ -149,7 +147,7 @@ func (pc *FormController) UpdateStatus(c *fiber.Ctx) error {
 	if err := pc.validator.Struct(&body); err != nil {
 		return errors.BadRequest("Validation failed: " + err.Error())
 	}
-	err := pc.formService.UpdateStatus(c.Context(), userId, formId, models.FormStatus(body.Action))
+	err := pc.formService.UpdateStatus(c.Context(), userId, formId, body.Status)
 	if err != nil {
 		return err
 	}
@@ -236,7 +234,7 @@ func (pc *FormController) Publish(c *fiber.Ctx) error {
 		return err
 	}
 
-	form, err := pc.formService.Publish(c.Context(), formId)
+	form, err := pc.formService.Publish(c.Context(), userId, formId)
 	if err != nil {
 		return err
 	}
@@ -269,9 +267,337 @@ func (pc *FormController) Unpublish(c *fiber.Ctx) error {
 		return err
 	}
 
-	form, err := pc.formService.Unpublish(c.Context(), formId)
+	form, err := pc.formService.Unpublish(c.Context(), userId, formId)
 	if err != nil {
 		return err
 	}
 	return utils.Success(c, form, "Form unpublished successfully")
 }
+
+// @Summary Update form variables
+// @Description Replace the calculated variables of a form. Variables are evaluated in order on submit.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.UpdateVariablesRequest true "Variables"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/variables [put]
+func (pc *FormController) UpdateVariables(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.UpdateVariablesRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	if err := pc.formService.UpdateVariables(c.Context(), userId, formId, &body); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Variables updated successfully")
+}
+
+// @Summary Update quiz settings
+// @Description Configure quiz mode: answer key, points, time limit and pass mark
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.QuizSettings true "Quiz settings"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/quiz [put]
+func (pc *FormController) UpdateQuiz(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.QuizSettings
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	if err := pc.formService.UpdateQuiz(c.Context(), userId, formId, &body); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Quiz settings updated successfully")
+}
+
+// @Summary Update hidden fields
+// @Description Declare the URL parameters, such as UTM tags or CRM IDs, captured with each response
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.UpdateHiddenFieldsRequest true "Hidden fields"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/hidden-fields [put]
+func (pc *FormController) UpdateHiddenFields(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.UpdateHiddenFieldsRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	if err := pc.formService.UpdateHiddenFields(c.Context(), userId, formId, &body); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Hidden fields updated successfully")
+}
+
+// @Summary Check slug availability
+// @Description Check whether a slug follows the format rules and is free for this form
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param slug query string true "Slug"
+// @Success 200 {object} types.SlugAvailabilityResponse
+// @Router /forms/{formId}/slug/availability [get]
+func (pc *FormController) CheckSlug(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	slug := c.Query("slug")
+	if slug == "" {
+		return errors.BadRequest("Slug is required")
+	}
+
+	result, err := pc.formService.CheckSlug(c.Context(), userId, formId, slug)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, result, "Slug checked successfully")
+}
+
+// @Summary Update form slug
+// @Description Set a custom slug for the form URL. The previous slug keeps redirecting for 30 days.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.UpdateSlugRequest true "Slug"
+// @Success 200 {object} types.FormResponse
+// @Router /forms/{formId}/slug [put]
+func (pc *FormController) UpdateSlug(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.UpdateSlugRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	form, err := pc.formService.UpdateSlug(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, form, "Slug updated successfully")
+}
+
+// @Summary Move a form
+// @Description Move a form to another project, possibly in another team. Responses, versions and uploads stay with the form.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.MoveFormRequest true "Target project"
+// @Success 200 {object} types.FormResponse
+// @Router /forms/{formId}/move [post]
+func (pc *FormController) Move(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.MoveFormRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	form, err := pc.formService.Move(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, form, "Form moved successfully")
+}
+
+// @Summary Get form status history
+// @Description List the status transitions of a form, newest first
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {array} models.FormStatusTransition
+// @Router /forms/{formId}/status/history [get]
+func (pc *FormController) GetStatusHistory(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	history, err := pc.formService.GetStatusHistory(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, history, "Status history fetched successfully")
+}
+
+// @Summary Get scheduled jobs
+// @Description List the scheduled publish, open and close jobs of a form
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {array} models.ScheduledJob
+// @Router /forms/{formId}/schedule [get]
+func (pc *FormController) GetScheduledJobs(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	jobs, err := pc.formService.GetScheduledJobs(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, jobs, "Scheduled jobs fetched successfully")
+}
+
+// @Summary Schedule a publish
+// @Description Publish the editor state of the form at a future time. Replaces a pending scheduled publish.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.SchedulePublishRequest true "Publish time"
+// @Success 201 {object} models.ScheduledJob
+// @Router /forms/{formId}/schedule/publish [post]
+func (pc *FormController) SchedulePublish(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.SchedulePublishRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	job, err := pc.formService.SchedulePublish(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+	return utils.Created(c, job, "Publish scheduled successfully")
+}
+
+// @Summary Cancel a scheduled job
+// @Description Cancel a pending scheduled job of a form
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/schedule/{jobId} [delete]
+func (pc *FormController) CancelScheduledJob(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	jobId := c.Params("jobId", "")
+	if formId == "" || jobId == "" {
+		return errors.BadRequest("Form ID and job ID are required")
+	}
+
+	if err := pc.formService.CancelScheduledJob(c.Context(), userId, formId, jobId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Scheduled job cancelled successfully")
+}
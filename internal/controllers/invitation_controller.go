@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type InvitationController struct {
+	validator         *validator.Validate
+	invitationService services.IInvitationService
+}
+
+func NewInvitationController(service services.IInvitationService) *InvitationController {
+	return &InvitationController{
+		validator:         validator.New(),
+		invitationService: service,
+	}
+}
+
+// @Summary Invite a member
+// @Description Email an invitation to join the team with a role below the inviter's. Pending invitations count towards the plan's member limit.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param body body types.CreateInvitationRequest true "Invitation data"
+// @Success 201 {object} types.InvitationResponse
+// @Router /teams/{id}/invitations [post]
+func (ic *InvitationController) Create(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	body := new(types.CreateInvitationRequest)
+	if err := c.BodyParser(body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := ic.validator.Struct(body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	invitation, err := ic.invitationService.Create(c.Context(), userId, teamId, body)
+	if err != nil {
+		return err
+	}
+	return utils.Created(c, invitation, "Invitation sent successfully")
+}
+
+// @Summary Get pending invitations
+// @Description Retrieve the team's pending invitations, including those that have expired
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {array} types.InvitationResponse
+// @Router /teams/{id}/invitations [get]
+func (ic *InvitationController) List(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	invitations, err := ic.invitationService.List(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, invitations, "Invitations fetched successfully")
+}
+
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so its link can no longer be used
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/invitations/{invitationId} [delete]
+func (ic *InvitationController) Revoke(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	invitationId := c.Params("invitationId")
+	if teamId == "" || invitationId == "" {
+		return errors.BadRequest("Team ID and invitation ID are required")
+	}
+
+	if err := ic.invitationService.Revoke(c.Context(), userId, teamId, invitationId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Invitation revoked successfully")
+}
+
+// @Summary Resend an invitation
+// @Description Email a new link for a pending invitation and restart its expiry. The previous link stops working.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} types.InvitationResponse
+// @Router /teams/{id}/invitations/{invitationId}/resend [post]
+func (ic *InvitationController) Resend(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	invitationId := c.Params("invitationId")
+	if teamId == "" || invitationId == "" {
+		return errors.BadRequest("Team ID and invitation ID are required")
+	}
+
+	invitation, err := ic.invitationService.Resend(c.Context(), userId, teamId, invitationId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, invitation, "Invitation resent successfully")
+}
+
+// @Summary Accept an invitation
+// @Description Join the team with the token from an invitation email. The token can only be used once.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param body body types.AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} types.InvitationResponse
+// @Router /invitations/accept [post]
+func (ic *InvitationController) Accept(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	body := new(types.AcceptInvitationRequest)
+	if err := c.BodyParser(body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := ic.validator.Struct(body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	invitation, err := ic.invitationService.Accept(c.Context(), userId, body)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, invitation, "Invitation accepted successfully")
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PrefillController struct {
+	validator      *validator.Validate
+	prefillService services.IPrefillService
+}
+
+func NewPrefillController(service services.IPrefillService) *PrefillController {
+	return &PrefillController{
+		validator:      validator.New(),
+		prefillService: service,
+	}
+}
+
+// @Summary Create a prefill link token
+// @Description Sign answer values for the published form. Pass the token as the prefill query parameter of the form URL. Locked answers cannot be changed by the respondent.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.CreatePrefillTokenRequest true "Prefilled answers"
+// @Success 201 {object} types.PrefillTokenResponse
+// @Router /forms/{formId}/prefill [post]
+func (pc *PrefillController) CreateToken(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId", "")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.CreatePrefillTokenRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	token, err := pc.prefillService.CreateToken(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+	return utils.Created(c, token, "Prefill token created successfully")
+}
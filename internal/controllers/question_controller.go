@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"strings"
+
 	"github.com/HarshKanjiya/escape-form-api/internal/services"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
@@ -295,3 +297,171 @@ func (pc *QuestionController) DeleteOption(c *fiber.Ctx) error {
 	}
 	return utils.NoContent(c)
 }
+
+// @Summary Bulk create question options
+// @Description Create options from pasted lines or CSV (label,value); duplicate labels are skipped and missing values are generated
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param questionId path string true "Question ID"
+// @Param body body types.BulkCreateOptionsRequest true "Options content"
+// @Success 201 {array} models.QuestionOption
+// @Router /forms/{formId}/questions/{questionId}/options/bulk [post]
+func (pc *QuestionController) BulkCreateOptions(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	questionId := c.Params("questionId")
+	formId := c.Params("formId")
+
+	if questionId == "" || formId == "" {
+		return errors.BadRequest("Question ID and Form ID are required")
+	}
+
+	var body types.BulkCreateOptionsRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	options, err := pc.questionService.BulkCreateOptions(c.Context(), userId, formId, questionId, &body)
+	if err != nil {
+		return err
+	}
+
+	return utils.Created(c, options, "Options created successfully")
+}
+
+// @Summary Replace question options
+// @Description Atomically replace all options of a question
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param questionId path string true "Question ID"
+// @Param body body types.ReplaceOptionsRequest true "New options"
+// @Success 200 {array} models.QuestionOption
+// @Router /forms/{formId}/questions/{questionId}/options [put]
+func (pc *QuestionController) ReplaceOptions(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	questionId := c.Params("questionId")
+	formId := c.Params("formId")
+
+	if questionId == "" || formId == "" {
+		return errors.BadRequest("Question ID and Form ID are required")
+	}
+
+	var body types.ReplaceOptionsRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	// A blank label must fail the required check rather than pass it as spaces
+	for _, option := range body.Options {
+		if option != nil {
+			option.Label = strings.TrimSpace(option.Label)
+		}
+	}
+
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	options, err := pc.questionService.ReplaceOptions(c.Context(), userId, formId, questionId, &body)
+	if err != nil {
+		return err
+	}
+
+	return utils.Success(c, options, "Options replaced successfully")
+}
+
+// @Summary Reorder question options
+// @Description Update the sort order of a question's options
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param questionId path string true "Question ID"
+// @Param body body types.UpdateSequenceRequest true "New option order"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/questions/{questionId}/options/sequence [post]
+func (pc *QuestionController) UpdateOptionSequence(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	questionId := c.Params("questionId")
+	formId := c.Params("formId")
+
+	if questionId == "" || formId == "" {
+		return errors.BadRequest("Question ID and Form ID are required")
+	}
+
+	var sequenceDto types.UpdateSequenceRequest
+	if err := c.BodyParser(&sequenceDto); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	if err := pc.validator.Struct(&sequenceDto); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	err := pc.questionService.UpdateOptionSequence(c.Context(), userId, formId, questionId, sequenceDto.Sequence)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Options reordered successfully")
+}
+
+// @Summary Copy or move questions to another form
+// @Description Copy or move questions with their options and the edges between them into another form. Edges that would dangle are dropped and reported.
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Source form ID"
+// @Param body body types.TransferQuestionsRequest true "Transfer data"
+// @Success 200 {object} types.TransferQuestionsResponse
+// @Router /forms/{formId}/questions/transfer [post]
+func (pc *QuestionController) TransferQuestions(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.TransferQuestionsRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	res, err := pc.questionService.TransferQuestions(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+
+	return utils.Success(c, res, "Questions transferred successfully")
+}
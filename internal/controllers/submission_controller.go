@@ -1,18 +1,24 @@
 package controllers
 
 import (
+	"strings"
+
 	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
 	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type SubmissionController struct {
+	validator         *validator.Validate
 	submissionService services.ISubmissionService
 }
 
 func NewSubmissionController(service services.ISubmissionService) *SubmissionController {
 	return &SubmissionController{
+		validator:         validator.New(),
 		submissionService: service,
 	}
 }
@@ -23,19 +29,162 @@ func NewSubmissionController(service services.ISubmissionService) *SubmissionCon
 // @Accept json
 // @Produce json
 // @Param domain path string true "Form Domain"
+// @Param locale query string false "Preferred locale, overrides Accept-Language"
+// @Param prefill query string false "Signed prefill token"
 // @Success 200 {object} int64
 // @Router /submission/{domain} [get]
 func (pc *SubmissionController) GetForm(c *fiber.Ctx) error {
 
-	domain := c.Params("domain", "")
+	domain := c.Params("domain", utils.GetFormDomain(c))
 	if domain == "" {
 		return errors.BadRequest("Id is required")
 	}
 
-	form, err := pc.submissionService.GetForm(c.Context(), domain)
+	form, err := pc.submissionService.GetForm(c.Context(), domain, c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage), c.Query("prefill"))
 	if err != nil {
-		return err
+		return withMovedLocation(c, err)
 	}
 
 	return utils.Success(c, form, "Form fetched successfully")
 }
+
+// @Summary Submit a response
+// @Description Validate and store a completed response for a published form
+// @Tags submission
+// @Accept json
+// @Produce json
+// @Param domain path string true "Form Domain"
+// @Param body body types.SubmitResponseRequest true "Answers keyed by question ID"
+// @Param utm_source query string false "Any hidden field declared on the form can be passed as a query parameter"
+// @Success 201 {object} types.SubmitResponseResponse
+// @Router /submissions/{domain} [post]
+func (pc *SubmissionController) Submit(c *fiber.Ctx) error {
+
+	domain := c.Params("domain", utils.GetFormDomain(c))
+	if domain == "" {
+		return errors.BadRequest("Id is required")
+	}
+
+	var body types.SubmitResponseRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	response, err := pc.submissionService.Submit(c.Context(), domain, c.Queries(), &body)
+	if err != nil {
+		return withMovedLocation(c, err)
+	}
+
+	return utils.Created(c, response, "Response submitted successfully")
+}
+
+// @Summary Validate an answer
+// @Description Run the server-side answer rules of a question against a single value
+// @Tags submission
+// @Accept json
+// @Produce json
+// @Param domain path string true "Form Domain"
+// @Param body body types.ValidateAnswerRequest true "Question ID and answer value"
+// @Success 200 {object} types.ValidateAnswerResponse
+// @Router /submissions/{domain}/validate [post]
+func (pc *SubmissionController) ValidateAnswer(c *fiber.Ctx) error {
+
+	domain := c.Params("domain", utils.GetFormDomain(c))
+	if domain == "" {
+		return errors.BadRequest("Id is required")
+	}
+
+	var body types.ValidateAnswerRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := pc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	result, err := pc.submissionService.ValidateAnswer(c.Context(), domain, &body)
+	if err != nil {
+		return withMovedLocation(c, err)
+	}
+
+	return utils.Success(c, result, "Answer validated successfully")
+}
+
+// @Summary Get the next question
+// @Description Resolve the question that follows the current one for the given answers and hidden fields, with answer placeholders rendered. Omit currentQuestionId to get the first question.
+// @Tags submission
+// @Accept json
+// @Produce json
+// @Param domain path string true "Form Domain"
+// @Param locale query string false "Preferred locale, overrides Accept-Language"
+// @Param body body types.NextQuestionRequest true "Current question and answers so far"
+// @Success 200 {object} types.NextQuestionResponse
+// @Router /submissions/{domain}/next [post]
+func (pc *SubmissionController) NextQuestion(c *fiber.Ctx) error {
+
+	domain := c.Params("domain", utils.GetFormDomain(c))
+	if domain == "" {
+		return errors.BadRequest("Id is required")
+	}
+
+	var body types.NextQuestionRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	result, err := pc.submissionService.NextQuestion(c.Context(), domain, c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage), &body)
+	if err != nil {
+		return withMovedLocation(c, err)
+	}
+
+	return utils.Success(c, result, "Next question resolved successfully")
+}
+
+// @Summary Start a response
+// @Description Open a response before answering. Timed quizzes measure their time limit from this call.
+// @Tags submission
+// @Accept json
+// @Produce json
+// @Param domain path string true "Form Domain"
+// @Success 201 {object} types.StartResponseResponse
+// @Router /submissions/{domain}/start [post]
+func (pc *SubmissionController) Start(c *fiber.Ctx) error {
+
+	domain := c.Params("domain", utils.GetFormDomain(c))
+	if domain == "" {
+		return errors.BadRequest("Id is required")
+	}
+
+	result, err := pc.submissionService.Start(c.Context(), domain)
+	if err != nil {
+		return withMovedLocation(c, err)
+	}
+
+	return utils.Created(c, result, "Response started successfully")
+}
+
+// withMovedLocation points the Location header of a moved form at its new slug, on the
+// path for /submissions/:domain and on the host for forms served from their subdomain.
+// A redirect is never sent without a Location, so a moved form that cannot be pointed at
+// is not found.
+func withMovedLocation(c *fiber.Ctx, err error) error {
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.StatusCode != fiber.StatusTemporaryRedirect {
+		return err
+	}
+	details, _ := appErr.Details.(map[string]string)
+	slug := details["slug"]
+
+	if old := c.Params("domain"); old != "" && slug != "" {
+		c.Location(strings.Replace(c.OriginalURL(), "/submissions/"+old, "/submissions/"+slug, 1))
+		return err
+	}
+	if old := utils.GetFormDomain(c); old != "" && slug != "" && strings.HasPrefix(c.Hostname(), old+".") {
+		c.Location(c.Protocol() + "://" + slug + strings.TrimPrefix(c.Hostname(), old) + c.OriginalURL())
+		return err
+	}
+	return errors.NotFound("Form")
+}
@@ -145,3 +145,298 @@ func (tc *TeamController) Delete(c *fiber.Ctx) error {
 	}
 	return utils.Success(c, nil, "Team deleted successfully")
 }
+
+// @Summary Get team members
+// @Description Retrieve the members of a team and their roles
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {array} types.TeamMemberResponse
+// @Router /teams/{id}/members [get]
+func (tc *TeamController) GetMembers(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	members, err := tc.teamService.GetMembers(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, members, "Members fetched successfully")
+}
+
+// @Summary Update a member's role
+// @Description Change the role of a team member. Only roles below your own can be granted.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param userId path string true "Member user ID"
+// @Param body body types.UpdateMemberRoleRequest true "Role"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/members/{userId} [patch]
+func (tc *TeamController) UpdateMemberRole(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	memberId := c.Params("userId")
+	if teamId == "" || memberId == "" {
+		return errors.BadRequest("Team ID and user ID are required")
+	}
+
+	body := new(types.UpdateMemberRoleRequest)
+	if err := c.BodyParser(body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := tc.validator.Struct(body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	if err := tc.teamService.UpdateMemberRole(c.Context(), userId, teamId, memberId, body); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Member role updated successfully")
+}
+
+// @Summary Remove a member
+// @Description Remove a member from a team
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param userId path string true "Member user ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/members/{userId} [delete]
+func (tc *TeamController) RemoveMember(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	memberId := c.Params("userId")
+	if teamId == "" || memberId == "" {
+		return errors.BadRequest("Team ID and user ID are required")
+	}
+
+	if err := tc.teamService.RemoveMember(c.Context(), userId, teamId, memberId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Member removed successfully")
+}
+
+// @Summary Leave a team
+// @Description Remove your own membership. The owner has to transfer ownership first.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/leave [post]
+func (tc *TeamController) Leave(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	if err := tc.teamService.LeaveTeam(c.Context(), userId, teamId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Left the team successfully")
+}
+
+// @Summary Get the pending ownership transfer
+// @Description Retrieve the team's pending ownership transfer, including one that has expired
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} types.OwnershipTransferResponse
+// @Router /teams/{id}/ownership-transfer [get]
+func (tc *TeamController) GetOwnershipTransfer(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	transfer, err := tc.teamService.GetOwnershipTransfer(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, transfer, "Ownership transfer fetched successfully")
+}
+
+// @Summary Transfer ownership
+// @Description Offer the team to another member. Ownership moves once they accept, and the current owner stays on as an admin.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param body body types.TransferOwnershipRequest true "New owner"
+// @Success 201 {object} types.OwnershipTransferResponse
+// @Router /teams/{id}/ownership-transfer [post]
+func (tc *TeamController) TransferOwnership(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	body := new(types.TransferOwnershipRequest)
+	if err := c.BodyParser(body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := tc.validator.Struct(body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	transfer, err := tc.teamService.TransferOwnership(c.Context(), userId, teamId, body)
+	if err != nil {
+		return err
+	}
+	return utils.Created(c, transfer, "Ownership transfer proposed successfully")
+}
+
+// @Summary Cancel an ownership transfer
+// @Description Withdraw the team's pending ownership transfer
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/ownership-transfer [delete]
+func (tc *TeamController) CancelOwnershipTransfer(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	if err := tc.teamService.CancelOwnershipTransfer(c.Context(), userId, teamId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Ownership transfer cancelled successfully")
+}
+
+// @Summary Accept an ownership transfer
+// @Description Become the owner of a team whose ownership was offered to you
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} types.OwnershipTransferResponse
+// @Router /teams/{id}/ownership-transfer/accept [post]
+func (tc *TeamController) AcceptOwnershipTransfer(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	transfer, err := tc.teamService.AcceptOwnershipTransfer(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, transfer, "Ownership transfer accepted successfully")
+}
+
+// @Summary Decline an ownership transfer
+// @Description Turn down the ownership of a team offered to you
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/ownership-transfer/decline [post]
+func (tc *TeamController) DeclineOwnershipTransfer(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	if err := tc.teamService.DeclineOwnershipTransfer(c.Context(), userId, teamId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Ownership transfer declined successfully")
+}
+
+// @Summary Override a team's owner
+// @Description Support staff only. Hand the team to a user without the current owner, adding them to the team if needed. The previous owner stays on as an admin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param body body types.OverrideOwnerRequest true "New owner and reason"
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/teams/{id}/owner [put]
+func (tc *TeamController) OverrideOwner(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	body := new(types.OverrideOwnerRequest)
+	if err := c.BodyParser(body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+	if err := tc.validator.Struct(body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	if err := tc.teamService.OverrideOwner(c.Context(), userId, teamId, body); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Team owner updated successfully")
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TranslationController struct {
+	validator          *validator.Validate
+	translationService services.ITranslationService
+}
+
+func NewTranslationController(service services.ITranslationService) *TranslationController {
+	return &TranslationController{
+		validator:          validator.New(),
+		translationService: service,
+	}
+}
+
+// @Summary Get form locales
+// @Description Retrieve the locales configured for a form
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {array} models.FormLocale
+// @Router /forms/{formId}/locales [get]
+func (tc *TranslationController) GetLocales(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	locales, err := tc.translationService.GetLocales(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, locales, "Locales fetched successfully")
+}
+
+// @Summary Add a form locale
+// @Description Add a locale to a form. The first locale becomes the default.
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param body body types.CreateLocaleRequest true "Locale data"
+// @Success 201 {object} models.FormLocale
+// @Router /forms/{formId}/locales [post]
+func (tc *TranslationController) CreateLocale(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	var body types.CreateLocaleRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	if err := tc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	locale, err := tc.translationService.CreateLocale(c.Context(), userId, formId, &body)
+	if err != nil {
+		return err
+	}
+	return utils.Created(c, locale, "Locale added successfully")
+}
+
+// @Summary Delete a form locale
+// @Description Remove a locale and its translations from a form
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param locale path string true "Locale"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/locales/{locale} [delete]
+func (tc *TranslationController) DeleteLocale(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	locale := c.Params("locale")
+	if formId == "" || locale == "" {
+		return errors.BadRequest("Form ID and locale are required")
+	}
+
+	if err := tc.translationService.DeleteLocale(c.Context(), userId, formId, locale); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Locale deleted successfully")
+}
+
+// @Summary Set the default form locale
+// @Description Mark a locale as the one the form's own content is written in
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param locale path string true "Locale"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/locales/{locale}/default [post]
+func (tc *TranslationController) SetDefaultLocale(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	locale := c.Params("locale")
+	if formId == "" || locale == "" {
+		return errors.BadRequest("Form ID and locale are required")
+	}
+
+	if err := tc.translationService.SetDefaultLocale(c.Context(), userId, formId, locale); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Default locale updated successfully")
+}
+
+// @Summary Get form translations
+// @Description Retrieve the translations of a form, optionally for a single locale
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param locale query string false "Locale"
+// @Success 200 {array} models.FormTranslation
+// @Router /forms/{formId}/translations [get]
+func (tc *TranslationController) GetTranslations(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	translations, err := tc.translationService.GetTranslations(c.Context(), userId, formId, c.Query("locale"))
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, translations, "Translations fetched successfully")
+}
+
+// @Summary Save form translations
+// @Description Create or update translations for a locale. An empty value removes the translation.
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Param locale path string true "Locale"
+// @Param body body types.UpsertTranslationsRequest true "Translations"
+// @Success 200 {object} types.ResponseObj
+// @Router /forms/{formId}/translations/{locale} [put]
+func (tc *TranslationController) UpsertTranslations(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	locale := c.Params("locale")
+	if formId == "" || locale == "" {
+		return errors.BadRequest("Form ID and locale are required")
+	}
+
+	var body types.UpsertTranslationsRequest
+	if err := c.BodyParser(&body); err != nil {
+		return errors.BadRequest("Invalid request body")
+	}
+
+	if err := tc.validator.Struct(&body); err != nil {
+		return errors.BadRequest("Validation failed: " + err.Error())
+	}
+
+	if err := tc.translationService.UpsertTranslations(c.Context(), userId, formId, locale, &body); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Translations saved successfully")
+}
+
+// @Summary Get translation completeness
+// @Description Report how much of the form is translated for each locale, with the missing strings
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param formId path string true "Form ID"
+// @Success 200 {object} types.TranslationCompletenessResponse
+// @Router /dashboard/{formId}/translations [get]
+func (tc *TranslationController) GetCompleteness(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("formId")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	report, err := tc.translationService.GetCompleteness(c.Context(), userId, formId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, report, "Translation completeness fetched successfully")
+}
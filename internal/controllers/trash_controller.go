@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashController struct {
+	trashService services.ITrashService
+}
+
+func NewTrashController(service services.ITrashService) *TrashController {
+	return &TrashController{
+		trashService: service,
+	}
+}
+
+// @Summary Get deleted teams
+// @Description Retrieve the teams of the user that are in the trash
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Success 200 {array} types.TrashItem
+// @Router /teams/trash [get]
+func (tc *TrashController) GetDeletedTeams(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teams, err := tc.trashService.GetDeletedTeams(c.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, teams, "Deleted teams fetched successfully")
+}
+
+// @Summary Get team trash
+// @Description Retrieve the deleted projects and forms of a team. Forms deleted with their project are restored with it and not listed.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} types.TeamTrashResponse
+// @Router /teams/{id}/trash [get]
+func (tc *TrashController) GetTeamTrash(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	trash, err := tc.trashService.GetTeamTrash(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, trash, "Trash fetched successfully")
+}
+
+// @Summary Restore a team
+// @Description Restore a deleted team with the projects and forms deleted along with it
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /teams/{id}/restore [post]
+func (tc *TrashController) RestoreTeam(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	if err := tc.trashService.RestoreTeam(c.Context(), userId, teamId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Team restored successfully")
+}
+
+// @Summary Restore a project
+// @Description Restore a deleted project with the forms deleted along with it
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/{id}/restore [post]
+func (tc *TrashController) RestoreProject(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	projectId := c.Params("id")
+	if projectId == "" {
+		return errors.BadRequest("Project ID is required")
+	}
+
+	if err := tc.trashService.RestoreProject(c.Context(), userId, projectId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Project restored successfully")
+}
+
+// @Summary Restore a form
+// @Description Restore a deleted form. Its project and team must not be in the trash.
+// @Tags forms
+// @Accept json
+// @Produce json
+// @Param id path string true "Form ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /forms/{id}/restore [post]
+func (tc *TrashController) RestoreForm(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	formId := c.Params("id")
+	if formId == "" {
+		return errors.BadRequest("Form ID is required")
+	}
+
+	if err := tc.trashService.RestoreForm(c.Context(), userId, formId); err != nil {
+		return err
+	}
+	return utils.Success(c, nil, "Form restored successfully")
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/internal/services"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UsageController struct {
+	usageService services.IUsageService
+}
+
+func NewUsageController(service services.IUsageService) *UsageController {
+	return &UsageController{
+		usageService: service,
+	}
+}
+
+// @Summary Get team usage
+// @Description Retrieve the team's usage in its current billing cycle against its plan limits. A null max means the limit is not capped; storage is in bytes.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} types.UsageResponse
+// @Router /teams/{id}/usage [get]
+func (uc *UsageController) Get(c *fiber.Ctx) error {
+
+	userId, ok := utils.GetUserId(c)
+	if ok == false {
+		return errors.Unauthorized("")
+	}
+
+	teamId := c.Params("id")
+	if teamId == "" {
+		return errors.BadRequest("Team ID is required")
+	}
+
+	usage, err := uc.usageService.Get(c.Context(), userId, teamId)
+	if err != nil {
+		return err
+	}
+	return utils.Success(c, usage, "Usage fetched successfully")
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLock is the advisory lock key held while migrating, so that instances
+// starting together apply each migration once
+const migrationLock = 7578
+
+// Migrate applies the SQL files in migrations/ that have not been applied yet, in file
+// name order, and records each in schema_migrations. Everything runs in one transaction,
+// so a failing migration leaves the schema as it was.
+func Migrate() error {
+	if DB == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLock).Error; err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version varchar(255) PRIMARY KEY,
+			"appliedAt" timestamptz(6) NOT NULL DEFAULT now()
+		)`).Error
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+
+		var applied []string
+		if err := tx.Table("schema_migrations").Pluck("version", &applied).Error; err != nil {
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		done := make(map[string]bool, len(applied))
+		for _, version := range applied {
+			done[version] = true
+		}
+
+		for _, entry := range entries {
+			version := strings.TrimSuffix(entry.Name(), ".sql")
+			if done[version] {
+				continue
+			}
+
+			sql, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+			if err != nil {
+				return fmt.Errorf("failed to read migration %s: %w", version, err)
+			}
+			if err := tx.Exec(string(sql)).Error; err != nil {
+				return fmt.Errorf("failed to apply migration %s: %w", version, err)
+			}
+			if err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version).Error; err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", version, err)
+			}
+			log.Printf("Applied migration %s", version)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+-- Locales a form is offered in, and translated text of its fields per locale
+CREATE TABLE IF NOT EXISTS form_locales (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "formId" uuid NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    locale varchar(16) NOT NULL,
+    "isDefault" boolean NOT NULL DEFAULT false,
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS form_locales_form_locale_key
+    ON form_locales ("formId", locale);
+
+CREATE TABLE IF NOT EXISTS form_translations (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "formId" uuid NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    locale varchar(16) NOT NULL,
+    "resourceType" varchar(16) NOT NULL,
+    "resourceId" uuid NOT NULL,
+    field text NOT NULL,
+    value text NOT NULL,
+    "updatedAt" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+-- UpsertTranslations relies on this index for ON CONFLICT
+CREATE UNIQUE INDEX IF NOT EXISTS form_translations_resource_key
+    ON form_translations ("formId", locale, "resourceType", "resourceId", field);
@@ -0,0 +1,5 @@
+-- Points an option adds to the form's variables when it is chosen
+ALTER TABLE questions_options ADD COLUMN IF NOT EXISTS score double precision;
+
+-- Values of the form's calculated variables at submission
+ALTER TABLE responses ADD COLUMN IF NOT EXISTS variables jsonb NOT NULL DEFAULT '{}';
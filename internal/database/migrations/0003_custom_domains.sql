@@ -0,0 +1,20 @@
+-- Domains claimed for forms and the state of their DNS verification
+CREATE TABLE IF NOT EXISTS custom_domains (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "formId" uuid NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    domain varchar(253) NOT NULL,
+    token text NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'PENDING',
+    "lastError" text,
+    "lastCheckedAt" timestamptz(6),
+    "verifiedAt" timestamptz(6),
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS custom_domains_form_id_key ON custom_domains ("formId");
+CREATE INDEX IF NOT EXISTS custom_domains_domain_idx ON custom_domains (domain);
+
+-- Any number of forms may claim a domain, but only one can have it verified. Verify
+-- relies on this index to refuse the second.
+CREATE UNIQUE INDEX IF NOT EXISTS custom_domains_verified_domain_key
+    ON custom_domains (domain) WHERE status = 'VERIFIED';
@@ -0,0 +1,16 @@
+-- Slugs are unique regardless of case, which also closes the race between checking a
+-- slug and saving it
+CREATE UNIQUE INDEX IF NOT EXISTS forms_unique_subdomain_lower_key
+    ON forms (lower("uniqueSubdomain"));
+
+-- Previous slugs of a form, redirecting to it until they expire
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug varchar(63) NOT NULL,
+    "formId" uuid NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    "expiresAt" timestamptz(6) NOT NULL,
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS slug_redirects_slug_key ON slug_redirects (slug);
+CREATE INDEX IF NOT EXISTS slug_redirects_form_id_idx ON slug_redirects ("formId");
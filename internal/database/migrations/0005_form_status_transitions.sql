@@ -0,0 +1,13 @@
+-- History of form status changes
+CREATE TABLE IF NOT EXISTS form_status_transitions (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "formId" uuid NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    "fromStatus" varchar(16) NOT NULL,
+    "toStatus" varchar(16) NOT NULL,
+    "actorId" varchar NOT NULL,
+    "versionId" uuid,
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS form_status_transitions_form_id_idx
+    ON form_status_transitions ("formId", "createdAt");
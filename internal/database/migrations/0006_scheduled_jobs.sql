@@ -0,0 +1,26 @@
+-- Form status changes to run at a later time
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "formId" uuid NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    type varchar(16) NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'PENDING',
+    "runAt" timestamptz(6) NOT NULL,
+    "lockedUntil" timestamptz(6),
+    attempts integer NOT NULL DEFAULT 0,
+    "lastError" text,
+    "versionId" uuid,
+    revision integer,
+    "createdBy" varchar NOT NULL,
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now(),
+    "finishedAt" timestamptz(6)
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_jobs_form_id_idx ON scheduled_jobs ("formId");
+
+-- A form has at most one pending job of each type. Enqueue relies on this index for
+-- ON CONFLICT.
+CREATE UNIQUE INDEX IF NOT EXISTS scheduled_jobs_pending_key
+    ON scheduled_jobs ("formId", type) WHERE status = 'PENDING';
+
+-- ClaimDue looks jobs up by status and runAt
+CREATE INDEX IF NOT EXISTS scheduled_jobs_due_idx ON scheduled_jobs (status, "runAt");
@@ -0,0 +1,9 @@
+-- When a team, project or form was moved to the trash. Everything deleted with it
+-- shares the same deletedAt, which is how restore finds it again.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS "deletedAt" timestamptz(6);
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS "deletedAt" timestamptz(6);
+ALTER TABLE forms ADD COLUMN IF NOT EXISTS "deletedAt" timestamptz(6);
+
+CREATE INDEX IF NOT EXISTS teams_deleted_at_idx ON teams ("deletedAt");
+CREATE INDEX IF NOT EXISTS projects_deleted_at_idx ON projects ("deletedAt");
+CREATE INDEX IF NOT EXISTS forms_deleted_at_idx ON forms ("deletedAt");
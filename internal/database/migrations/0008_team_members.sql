@@ -0,0 +1,17 @@
+-- Members of a team and their roles
+CREATE TABLE IF NOT EXISTS team_members (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "teamId" uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    "userId" varchar NOT NULL,
+    role varchar(16) NOT NULL,
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now(),
+    "updatedAt" timestamptz(6)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS team_members_team_user_key ON team_members ("teamId", "userId");
+CREATE INDEX IF NOT EXISTS team_members_user_id_idx ON team_members ("userId");
+
+-- Teams created before memberships existed get their owner's row
+INSERT INTO team_members ("teamId", "userId", role)
+SELECT id, "ownerId", 'OWNER' FROM teams WHERE "ownerId" IS NOT NULL
+ON CONFLICT ("teamId", "userId") DO NOTHING;
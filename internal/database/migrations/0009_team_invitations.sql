@@ -0,0 +1,22 @@
+-- Invitations to join a team, addressed by email
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "teamId" uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    email varchar NOT NULL,
+    role varchar(16) NOT NULL,
+    "invitedBy" varchar NOT NULL,
+    "tokenHash" varchar(64) NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'PENDING',
+    "expiresAt" timestamptz(6) NOT NULL,
+    "acceptedBy" varchar,
+    "acceptedAt" timestamptz(6),
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now(),
+    "updatedAt" timestamptz(6)
+);
+
+CREATE INDEX IF NOT EXISTS team_invitations_team_id_idx ON team_invitations ("teamId");
+CREATE UNIQUE INDEX IF NOT EXISTS team_invitations_token_hash_key ON team_invitations ("tokenHash");
+
+-- An address has at most one pending invitation per team
+CREATE UNIQUE INDEX IF NOT EXISTS team_invitations_pending_key
+    ON team_invitations ("teamId", email) WHERE status = 'PENDING';
@@ -0,0 +1,18 @@
+-- Team-scoped API keys. Only the SHA-256 of a key is stored.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "teamId" uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    "keyHash" varchar(64) NOT NULL,
+    scopes text[] NOT NULL DEFAULT '{}',
+    "createdBy" varchar NOT NULL,
+    "expiresAt" timestamptz(6),
+    "lastUsedAt" timestamptz(6),
+    "revokedAt" timestamptz(6),
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now(),
+    "updatedAt" timestamptz(6)
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_team_id_idx ON api_keys ("teamId");
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_key_hash_key ON api_keys ("keyHash");
@@ -0,0 +1,20 @@
+-- Changes made in a team. There is no foreign key to teams so that entries outlive the
+-- team until retention removes them.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "teamId" uuid NOT NULL,
+    "actorId" varchar,
+    "apiKeyId" uuid,
+    "resourceType" varchar(32) NOT NULL,
+    "resourceId" varchar NOT NULL,
+    action varchar(32) NOT NULL,
+    changes jsonb NOT NULL DEFAULT '{}',
+    ip varchar(64),
+    "requestId" varchar(128),
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_team_created_idx ON audit_logs ("teamId", "createdAt");
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_idx ON audit_logs ("actorId");
+CREATE INDEX IF NOT EXISTS audit_logs_resource_id_idx ON audit_logs ("resourceId");
+CREATE INDEX IF NOT EXISTS audit_logs_created_at_idx ON audit_logs ("createdAt");
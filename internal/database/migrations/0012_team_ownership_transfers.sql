@@ -0,0 +1,19 @@
+-- Offers to hand a team over to one of its members
+CREATE TABLE IF NOT EXISTS team_ownership_transfers (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "teamId" uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    "fromUserId" varchar NOT NULL,
+    "toUserId" varchar NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'PENDING',
+    "expiresAt" timestamptz(6) NOT NULL,
+    "respondedAt" timestamptz(6),
+    "createdAt" timestamptz(6) NOT NULL DEFAULT now(),
+    "updatedAt" timestamptz(6)
+);
+
+CREATE INDEX IF NOT EXISTS team_ownership_transfers_team_id_idx ON team_ownership_transfers ("teamId");
+CREATE INDEX IF NOT EXISTS team_ownership_transfers_to_user_id_idx ON team_ownership_transfers ("toUserId");
+
+-- A team has at most one pending offer
+CREATE UNIQUE INDEX IF NOT EXISTS team_ownership_transfers_pending_key
+    ON team_ownership_transfers ("teamId") WHERE status = 'PENDING';
@@ -0,0 +1,8 @@
+-- One usage record per team and billing cycle. SaveRecord relies on this index for
+-- ON CONFLICT.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_records_team_cycle
+    ON usage_records ("teamId", "cycleStart");
+
+-- Aggregation sums a team's events over a cycle, and storage is looked up per form
+CREATE INDEX IF NOT EXISTS usage_events_team_created_idx ON usage_events ("teamId", "createdAt");
+CREATE INDEX IF NOT EXISTS usage_events_ref_id_idx ON usage_events ("refId");
@@ -0,0 +1,4 @@
+-- A downgrade applied at the end of the cycle, and the state of a team in grace
+ALTER TABLE team_subscriptions ADD COLUMN IF NOT EXISTS "nextPlanId" uuid;
+ALTER TABLE team_subscriptions ADD COLUMN IF NOT EXISTS "paymentFailedAt" timestamptz(6);
+ALTER TABLE team_subscriptions ADD COLUMN IF NOT EXISTS "graceEndsAt" timestamptz(6);
@@ -0,0 +1,93 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+)
+
+// Resolver looks up the DNS records used to verify custom domains
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, name string) (string, error)
+}
+
+// NewResolver returns the resolver selected by DOMAIN_RESOLVER
+func NewResolver(cfg *config.Config) Resolver {
+	if cfg.Domains.Resolver == "static" {
+		resolver := NewStaticResolver(cfg.Domains.CNAMETarget)
+		resolver.Load(cfg.Domains.StaticRecords)
+		return resolver
+	}
+	return &NetResolver{resolver: net.DefaultResolver}
+}
+
+// NetResolver queries the system DNS
+type NetResolver struct {
+	resolver *net.Resolver
+}
+
+func (r *NetResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return r.resolver.LookupTXT(ctx, name)
+}
+
+func (r *NetResolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	return r.resolver.LookupCNAME(ctx, name)
+}
+
+// StaticResolver answers from in-memory records, for local development where the
+// claimed domains do not exist. Names without a CNAME record resolve to the fallback.
+type StaticResolver struct {
+	TXT           map[string][]string
+	CNAME         map[string]string
+	FallbackCNAME string
+}
+
+func NewStaticResolver(fallbackCNAME string) *StaticResolver {
+	return &StaticResolver{
+		TXT:           make(map[string][]string),
+		CNAME:         make(map[string]string),
+		FallbackCNAME: fallbackCNAME,
+	}
+}
+
+// Load adds records written as "TXT name value;CNAME name target"
+func (r *StaticResolver) Load(records string) {
+	for _, record := range strings.Split(records, ";") {
+		fields := strings.Fields(record)
+		if len(fields) != 3 {
+			continue
+		}
+		name := normalizeName(fields[1])
+		switch strings.ToUpper(fields[0]) {
+		case "TXT":
+			r.TXT[name] = append(r.TXT[name], fields[2])
+		case "CNAME":
+			r.CNAME[name] = normalizeName(fields[2])
+		}
+	}
+}
+
+func (r *StaticResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := r.TXT[normalizeName(name)]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func (r *StaticResolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	if target, ok := r.CNAME[normalizeName(name)]; ok {
+		return target + ".", nil
+	}
+	if r.FallbackCNAME != "" {
+		return r.FallbackCNAME + ".", nil
+	}
+	return "", &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
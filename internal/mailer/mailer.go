@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Driver selects how emails are delivered
+type Driver string
+
+const (
+	DriverLog  Driver = "log"
+	DriverSMTP Driver = "smtp"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional emails such as team invitations
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer returns the mailer selected by MAIL_DRIVER, failing for an unknown driver
+// or an incomplete configuration so that a misconfigured server does not start
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch Driver(strings.ToLower(cfg.Mail.Driver)) {
+	case DriverLog:
+		return &LogMailer{from: cfg.Mail.From}, nil
+	case DriverSMTP:
+		return NewSMTPMailer(&cfg.Mail)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+	}
+}
+
+// secretPattern matches links and token parameters, which may grant access on their own
+var secretPattern = regexp.MustCompile(`https?://\S+|token=\S+`)
+
+// LogMailer writes emails to the log instead of sending them, for local development.
+// Links and tokens are redacted, since anyone reading the log could otherwise use them.
+type LogMailer struct {
+	from string
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	log.Info().
+		Str("from", m.from).
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", secretPattern.ReplaceAllString(msg.Body, "[redacted]")).
+		Msg("Email not sent, logged instead")
+	return nil
+}
+
+// SMTPMailer delivers emails through an SMTP server, authenticating when a username
+// is configured
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(cfg *config.MailConfig) (*SMTPMailer, error) {
+	if cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("MAIL_SMTP_HOST is required for the smtp mail driver")
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("MAIL_FROM is required for the smtp mail driver")
+	}
+
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from: cfg.From,
+	}
+	if cfg.SMTPUsername != "" {
+		m.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("email headers must not contain line breaks")
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", m.from)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(body.String()))
+}
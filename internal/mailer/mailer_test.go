@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"testing"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+)
+
+func TestSecretPatternRedactsLinksAndTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"invite link", "Accept: https://dashboard.escform.com/invite?token=abc123\n\nThanks", "Accept: [redacted]\n\nThanks"},
+		{"plain http", "Go to http://localhost:3000/x now", "Go to [redacted] now"},
+		{"bare token", "Use token=abc123 to join", "Use [redacted] to join"},
+		{"nothing secret", "You have been invited to join Acme as editor.", "You have been invited to join Acme as editor."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secretPattern.ReplaceAllString(tt.body, "[redacted]"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		mail    config.MailConfig
+		wantErr bool
+	}{
+		{"log", config.MailConfig{Driver: "log"}, false},
+		{"driver is case insensitive", config.MailConfig{Driver: "LOG"}, false},
+		{"smtp", config.MailConfig{Driver: "smtp", From: "a@b.c", SMTPHost: "smtp.example.com", SMTPPort: 587}, false},
+		{"smtp without host", config.MailConfig{Driver: "smtp", From: "a@b.c"}, true},
+		{"smtp without sender", config.MailConfig{Driver: "smtp", SMTPHost: "smtp.example.com"}, true},
+		{"unknown driver", config.MailConfig{Driver: "ses"}, true},
+		{"empty driver", config.MailConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMailer(&config.Config{Mail: tt.mail})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,67 +3,57 @@ package middlewares
 import (
 	"strings"
 
+	"github.com/HarshKanjiya/escape-form-api/internal/auth"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
-	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
 )
 
-func JWTMiddleware(jwtSecret string) fiber.Handler {
+// Authenticate accepts a session token from the configured provider, or an API key sent
+// as a bearer token or in X-API-Key. The caller is stored in the identity local and
+// their user ID in user_id; for an API key that is its creator, and permissions are
+// limited to the key's team and scopes.
+func Authenticate(sessions auth.Authenticator, apiKeys auth.Authenticator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get token
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return errors.Unauthorized("Missing authorization token")
+		token := c.Get("X-API-Key")
+		if token == "" {
+			authHeader := c.Get("Authorization")
+			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Missing or invalid token",
+				})
+			}
+			token = strings.TrimPrefix(authHeader, "Bearer ")
 		}
 
-		// Extract token from "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return errors.Unauthorized("Invalid authorization header format")
+		authenticator := sessions
+		if strings.HasPrefix(token, models.APIKeyPrefix) {
+			authenticator = apiKeys
 		}
 
-		token := parts[1]
-
-		// Validate token
-		claims, err := utils.ValidateToken(token, jwtSecret)
+		identity, err := authenticator.Authenticate(c.Context(), token)
 		if err != nil {
-			log.Error().Err(err).Msg("Token validation failed")
-			return errors.Unauthorized("Invalid or expired token")
+			if appErr, ok := err.(*errors.AppError); ok {
+				return appErr
+			}
+			log.Debug().Err(err).Msg("Token verification failed")
+			return errors.Unauthorized("")
 		}
-
-		// Store user info in context for use in handlers
-		c.Locals("userID", claims.UserID)
-		c.Locals("userEmail", claims.Email)
-		c.Locals("userRole", claims.Role)
+		c.Locals(auth.IdentityKey, identity)
+		c.Locals("user_id", identity.UserID)
 
 		return c.Next()
 	}
 }
 
-// extract the user ID from the Fiber context
-func GetUserID(c *fiber.Ctx) uint {
-	userID, ok := c.Locals("userID").(uint)
-	if !ok {
-		return 0
-	}
-	return userID
-}
-
-// extract the user email from the Fiber context
-func GetUserEmail(c *fiber.Ctx) string {
-	email, ok := c.Locals("userEmail").(string)
-	if !ok {
-		return ""
-	}
-	return email
-}
-
-// extract the user role from the Fiber context
-func GetUserRole(c *fiber.Ctx) string {
-	role, ok := c.Locals("userRole").(string)
-	if !ok {
-		return ""
+// UserOnly rejects API keys on routes that act across teams or on the user themselves,
+// such as listing teams or managing keys
+func UserOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if identity, ok := c.Locals(auth.IdentityKey).(*auth.Identity); ok && identity.APIKey != nil {
+			return errors.Unauthorized("This endpoint cannot be used with an API key")
+		}
+		return c.Next()
 	}
-	return role
 }
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"net"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// FormHost resolves the form domain from the Host header, so that a published form can
+// be served on <subdomain>.<formHost> or on a verified custom domain. The domain is
+// stored in the form_domain local; whether a form answers on it is checked on lookup.
+func FormHost(formHost string) fiber.Handler {
+	formHost = strings.ToLower(strings.TrimSuffix(formHost, "."))
+
+	return func(c *fiber.Ctx) error {
+		host := strings.ToLower(c.Hostname())
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.TrimSuffix(host, ".")
+
+		var domain string
+		switch {
+		case host == "" || host == formHost:
+			return errors.NotFound("Form")
+		case strings.HasSuffix(host, "."+formHost):
+			domain = strings.TrimSuffix(host, "."+formHost)
+			if strings.Contains(domain, ".") {
+				return errors.NotFound("Form")
+			}
+		default:
+			domain = host
+		}
+
+		c.Locals("form_domain", domain)
+		return c.Next()
+	}
+}
@@ -50,7 +50,10 @@ func Logger() fiber.Handler {
 			logEvent = log.Error().Err(err)
 		}
 
+		requestId, _ := c.Locals("request_id").(string)
+
 		logEvent.
+			Str("request_id", requestId).
 			Str("method", c.Method()).
 			Str("path", c.Path()).
 			Int("status", c.Response().StatusCode()).
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxRequestIDLength caps a request ID passed in by a proxy or client
+const maxRequestIDLength = 128
+
+// RequestID tags each request with an ID, reusing a sane X-Request-ID from upstream, and
+// echoes it in the response. The ID and the client IP are stored in the request_id and
+// client_ip locals for logging and the audit log.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = utils.GenerateUUID()
+		}
+
+		c.Locals("request_id", id)
+		c.Locals("client_ip", c.IP())
+		c.Set(fiber.HeaderXRequestID, id)
+
+		return c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -14,6 +14,8 @@ import (
 func SetupMiddlewares(app *fiber.App, cfg *config.Config) {
 	app.Use(recover.New())
 
+	app.Use(RequestID())
+
 	app.Use(Logger())
 
 	// CORS
@@ -22,6 +24,7 @@ func SetupMiddlewares(app *fiber.App, cfg *config.Config) {
 		AllowMethods:     strings.Join(cfg.CORS.Methods, ","),
 		AllowHeaders:     strings.Join(cfg.CORS.Headers, ","),
 		AllowCredentials: true,
+		ExposeHeaders:    fiber.HeaderXRequestID,
 	}))
 
 	// Rate limiting
@@ -37,7 +40,7 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	if appErr, ok := err.(*errors.AppError); ok {
 		return c.Status(appErr.StatusCode).JSON(fiber.Map{
 			"type":       "error",
-			"data":       nil,
+			"data":       appErr.Details,
 			"totalCount": 0,
 			"message":    appErr.Message,
 		})
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// APIKeyPrefix starts every API key, telling them apart from Clerk session tokens
+const APIKeyPrefix = "ef_"
+
+// APIKey lets a backend call the API on behalf of the user who created it, limited to
+// one team and to its scopes. Only the SHA-256 of the key is stored; Prefix is kept to
+// tell keys apart in listings.
+type APIKey struct {
+	ID         string     `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	TeamID     string     `gorm:"type:uuid;index;column:teamId" json:"teamId"`
+	Name       string     `gorm:"type:varchar(100);column:name" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);column:prefix" json:"prefix"`
+	KeyHash    string     `gorm:"type:varchar(64);uniqueIndex;column:keyHash" json:"-"`
+	Scopes     []string   `gorm:"type:text[];column:scopes" json:"scopes"`
+	CreatedBy  string     `gorm:"type:varchar;column:createdBy" json:"createdBy"`
+	ExpiresAt  *time.Time `gorm:"type:timestamptz(6);column:expiresAt" json:"expiresAt"`
+	LastUsedAt *time.Time `gorm:"type:timestamptz(6);column:lastUsedAt" json:"lastUsedAt"`
+	RevokedAt  *time.Time `gorm:"type:timestamptz(6);column:revokedAt" json:"revokedAt"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	UpdatedAt  *time.Time `gorm:"type:timestamptz(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	Team       Team       `gorm:"foreignKey:TeamID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
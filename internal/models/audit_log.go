@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// AuditLog records one change made in a team. Changes maps each changed field to its
+// value before and after; a nil ActorID is the system, such as a scheduled publish.
+// There is no foreign key to the team so that entries outlive it until retention.
+type AuditLog struct {
+	ID           string            `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	TeamID       string            `gorm:"type:uuid;index:audit_logs_team_created_idx,priority:1;column:teamId" json:"teamId"`
+	ActorID      *string           `gorm:"type:varchar;index;column:actorId" json:"actorId"`
+	APIKeyID     *string           `gorm:"type:uuid;column:apiKeyId" json:"apiKeyId"`
+	ResourceType AuditResourceType `gorm:"type:varchar(32);column:resourceType" json:"resourceType"`
+	ResourceID   string            `gorm:"type:varchar;index;column:resourceId" json:"resourceId"`
+	Action       AuditAction       `gorm:"type:varchar(32);column:action" json:"action"`
+	Changes      datatypes.JSON    `gorm:"type:jsonb;default:'{}';column:changes" json:"changes"`
+	IP           *string           `gorm:"type:varchar(64);column:ip" json:"ip"`
+	RequestID    *string           `gorm:"type:varchar(128);column:requestId" json:"requestId"`
+	CreatedAt    time.Time         `gorm:"type:timestamptz(6);default:now();index;index:audit_logs_team_created_idx,priority:2;column:createdAt" json:"createdAt"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CustomDomain is a domain claimed for a form. The form only answers on it once the
+// DNS challenge has been verified, and a domain can be verified for one form at a time.
+type CustomDomain struct {
+	ID            string       `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	FormID        string       `gorm:"type:uuid;uniqueIndex;column:formId" json:"formId"`
+	Domain        string       `gorm:"type:varchar(253);index;uniqueIndex:custom_domains_verified_domain_key,where:status = 'VERIFIED';column:domain" json:"domain"`
+	Token         string       `gorm:"column:token" json:"-"`
+	Status        DomainStatus `gorm:"default:'PENDING';column:status" json:"status"`
+	LastError     *string      `gorm:"column:lastError" json:"lastError"`
+	LastCheckedAt *time.Time   `gorm:"type:timestamptz(6);column:lastCheckedAt" json:"lastCheckedAt"`
+	VerifiedAt    *time.Time   `gorm:"type:timestamptz(6);column:verifiedAt" json:"verifiedAt"`
+	CreatedAt     time.Time    `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	Form          Form         `gorm:"foreignKey:FormID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (CustomDomain) TableName() string {
+	return "custom_domains"
+}
@@ -104,4 +104,143 @@ const (
 	TeamSubscriptionStatusActive  TeamSubscriptionStatus = "ACTIVE"
 	TeamSubscriptionStatusGrace   TeamSubscriptionStatus = "GRACE"
 	TeamSubscriptionStatusBlocked TeamSubscriptionStatus = "BLOCKED"
-)
\ No newline at end of file
+)
+// TranslationResourceType enum
+type TranslationResourceType string
+
+const (
+	TranslationResourceForm     TranslationResourceType = "FORM"
+	TranslationResourceQuestion TranslationResourceType = "QUESTION"
+	TranslationResourceOption   TranslationResourceType = "OPTION"
+)
+
+// DomainStatus enum
+type DomainStatus string
+
+const (
+	DomainStatusPending  DomainStatus = "PENDING"
+	DomainStatusVerified DomainStatus = "VERIFIED"
+	DomainStatusFailed   DomainStatus = "FAILED"
+)
+
+// ScheduledJobType enum
+type ScheduledJobType string
+
+const (
+	ScheduledJobPublish ScheduledJobType = "PUBLISH"
+	ScheduledJobOpen    ScheduledJobType = "OPEN"
+	ScheduledJobClose   ScheduledJobType = "CLOSE"
+)
+
+// ScheduledJobStatus enum
+type ScheduledJobStatus string
+
+const (
+	ScheduledJobStatusPending   ScheduledJobStatus = "PENDING"
+	ScheduledJobStatusRunning   ScheduledJobStatus = "RUNNING"
+	ScheduledJobStatusDone      ScheduledJobStatus = "DONE"
+	ScheduledJobStatusFailed    ScheduledJobStatus = "FAILED"
+	ScheduledJobStatusCancelled ScheduledJobStatus = "CANCELLED"
+)
+
+// TeamRole enum
+type TeamRole string
+
+const (
+	TeamRoleOwner   TeamRole = "OWNER"
+	TeamRoleAdmin   TeamRole = "ADMIN"
+	TeamRoleEditor  TeamRole = "EDITOR"
+	TeamRoleAnalyst TeamRole = "ANALYST"
+	TeamRoleViewer  TeamRole = "VIEWER"
+)
+
+// InvitationStatus enum
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "PENDING"
+	InvitationStatusAccepted InvitationStatus = "ACCEPTED"
+	InvitationStatusRevoked  InvitationStatus = "REVOKED"
+	InvitationStatusExpired  InvitationStatus = "EXPIRED"
+)
+
+// OwnershipTransferStatus enum
+type OwnershipTransferStatus string
+
+const (
+	OwnershipTransferStatusPending   OwnershipTransferStatus = "PENDING"
+	OwnershipTransferStatusAccepted  OwnershipTransferStatus = "ACCEPTED"
+	OwnershipTransferStatusDeclined  OwnershipTransferStatus = "DECLINED"
+	OwnershipTransferStatusCancelled OwnershipTransferStatus = "CANCELLED"
+	OwnershipTransferStatusExpired   OwnershipTransferStatus = "EXPIRED"
+)
+
+// APIKeyScope enum
+type APIKeyScope string
+
+const (
+	APIKeyScopeFormsRead      APIKeyScope = "forms:read"
+	APIKeyScopeFormsWrite     APIKeyScope = "forms:write"
+	APIKeyScopeResponsesRead  APIKeyScope = "responses:read"
+	APIKeyScopeResponsesWrite APIKeyScope = "responses:write"
+	APIKeyScopeTeamManage     APIKeyScope = "team:manage"
+)
+
+// AuditResourceType enum
+type AuditResourceType string
+
+const (
+	AuditResourceTeam         AuditResourceType = "TEAM"
+	AuditResourceMember       AuditResourceType = "MEMBER"
+	AuditResourceInvitation   AuditResourceType = "INVITATION"
+	AuditResourceAPIKey       AuditResourceType = "API_KEY"
+	AuditResourceProject      AuditResourceType = "PROJECT"
+	AuditResourceForm         AuditResourceType = "FORM"
+	AuditResourceQuestion     AuditResourceType = "QUESTION"
+	AuditResourceOption       AuditResourceType = "OPTION"
+	AuditResourceEdge         AuditResourceType = "EDGE"
+	AuditResourcePassword     AuditResourceType = "PASSWORD"
+	AuditResourceDomain       AuditResourceType = "DOMAIN"
+	AuditResourceLocale       AuditResourceType = "LOCALE"
+	AuditResourceTranslation  AuditResourceType = "TRANSLATION"
+	AuditResourceScheduledJob AuditResourceType = "SCHEDULED_JOB"
+	AuditResourceFile         AuditResourceType = "FILE"
+	AuditResourceResponse     AuditResourceType = "RESPONSE"
+	// AuditResourceOwnershipTransfer is a proposed change of the team's owner
+	AuditResourceOwnershipTransfer AuditResourceType = "OWNERSHIP_TRANSFER"
+	AuditResourceSubscription      AuditResourceType = "SUBSCRIPTION"
+)
+
+// AuditAction enum
+type AuditAction string
+
+const (
+	AuditActionCreate    AuditAction = "CREATE"
+	AuditActionUpdate    AuditAction = "UPDATE"
+	AuditActionDelete    AuditAction = "DELETE"
+	AuditActionRestore   AuditAction = "RESTORE"
+	AuditActionMove      AuditAction = "MOVE"
+	AuditActionPublish   AuditAction = "PUBLISH"
+	AuditActionUnpublish AuditAction = "UNPUBLISH"
+	AuditActionSchedule  AuditAction = "SCHEDULE"
+	AuditActionCancel    AuditAction = "CANCEL"
+	AuditActionVerify    AuditAction = "VERIFY"
+	AuditActionResend    AuditAction = "RESEND"
+	AuditActionRevoke    AuditAction = "REVOKE"
+	AuditActionAccept    AuditAction = "ACCEPT"
+	AuditActionDecline   AuditAction = "DECLINE"
+	AuditActionLeave     AuditAction = "LEAVE"
+	// AuditActionOverride is a change made by support staff outside the team's roles
+	AuditActionOverride AuditAction = "OVERRIDE"
+)
+
+// UsageEventType enum
+type UsageEventType string
+
+const (
+	UsageEventSubmission     UsageEventType = "SUBMISSION"
+	UsageEventFormCreated    UsageEventType = "FORM_CREATED"
+	UsageEventProjectCreated UsageEventType = "PROJECT_CREATED"
+	UsageEventStorageBytes   UsageEventType = "STORAGE_BYTES"
+	UsageEventAITokens       UsageEventType = "AI_TOKENS"
+)
@@ -18,7 +18,7 @@ type Form struct {
 	OpenAt              *time.Time       `gorm:"type:timestamptz(6);column:openAt" json:"openAt"`
 	CloseAt             *time.Time       `gorm:"type:timestamptz(6);column:closeAt" json:"closeAt"`
 	Status              *FormStatus      `gorm:"column:status" json:"status"`
-	UniqueSubdomain     *string          `gorm:"column:uniqueSubdomain" json:"uniqueSubdomain"`
+	UniqueSubdomain     *string          `gorm:"uniqueIndex:forms_unique_subdomain_lower_key,expression:lower(\"uniqueSubdomain\");column:uniqueSubdomain" json:"uniqueSubdomain"`
 	CustomDomain        *string          `gorm:"column:customDomain" json:"customDomain"`
 	RequireConsent      *bool            `gorm:"column:requireConsent" json:"requireConsent"`
 	AllowAnonymous      *bool            `gorm:"column:allowAnonymous" json:"allowAnonymous"`
@@ -30,6 +30,7 @@ type Form struct {
 	CreatedBy           string           `gorm:"column:createdBy" json:"createdBy"`
 	CreatedAt           *time.Time       `gorm:"type:timestamptz(6);column:createdAt" json:"createdAt"`
 	UpdatedAt           *time.Time       `gorm:"type:timestamp(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	DeletedAt           *time.Time       `gorm:"type:timestamptz(6);index;column:deletedAt" json:"deletedAt"`
 	FormPageType        FormPageType     `gorm:"default:'STEPPER';column:formPageType" json:"formPageType"`
 	PublishedVersionID  *string         `gorm:"type:uuid;index;column:publishedVersionId" json:"publishedVersionId"`
 	EditorRevision      *int            `gorm:"column:editorRevision" json:"editorRevision"`
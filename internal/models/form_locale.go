@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type FormLocale struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	FormID    string    `gorm:"type:uuid;uniqueIndex:form_locales_form_locale_key;column:formId" json:"formId"`
+	Locale    string    `gorm:"type:varchar(16);uniqueIndex:form_locales_form_locale_key;column:locale" json:"locale"`
+	IsDefault bool      `gorm:"default:false;column:isDefault" json:"isDefault"`
+	CreatedAt time.Time `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	Form      Form      `gorm:"foreignKey:FormID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (FormLocale) TableName() string {
+	return "form_locales"
+}
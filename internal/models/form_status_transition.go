@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// FormStatusTransition records a change of a form's status, who made it and when
+type FormStatusTransition struct {
+	ID         string     `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	FormID     string     `gorm:"type:uuid;index;column:formId" json:"formId"`
+	FromStatus FormStatus `gorm:"column:fromStatus" json:"fromStatus"`
+	ToStatus   FormStatus `gorm:"column:toStatus" json:"toStatus"`
+	ActorID    string     `gorm:"column:actorId" json:"actorId"`
+	VersionID  *string    `gorm:"type:uuid;column:versionId" json:"versionId"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	Form       Form       `gorm:"foreignKey:FormID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (FormStatusTransition) TableName() string {
+	return "form_status_transitions"
+}
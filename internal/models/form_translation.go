@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type FormTranslation struct {
+	ID           string                  `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	FormID       string                  `gorm:"type:uuid;index;uniqueIndex:form_translations_resource_key;column:formId" json:"formId"`
+	Locale       string                  `gorm:"type:varchar(16);uniqueIndex:form_translations_resource_key;column:locale" json:"locale"`
+	ResourceType TranslationResourceType `gorm:"uniqueIndex:form_translations_resource_key;column:resourceType" json:"resourceType"`
+	ResourceID   string                  `gorm:"type:uuid;uniqueIndex:form_translations_resource_key;column:resourceId" json:"resourceId"`
+	Field        string                  `gorm:"uniqueIndex:form_translations_resource_key;column:field" json:"field"`
+	Value        string                  `gorm:"column:value" json:"value"`
+	UpdatedAt    time.Time               `gorm:"type:timestamptz(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	Form         Form                    `gorm:"foreignKey:FormID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (FormTranslation) TableName() string {
+	return "form_translations"
+}
@@ -10,6 +10,7 @@ type Project struct {
 	Valid       bool       `gorm:"default:true;column:valid" json:"valid"`
 	CreatedAt   *time.Time `gorm:"type:timestamptz(6);column:createdAt" json:"createdAt"`
 	UpdatedAt   *time.Time `gorm:"type:timestamp(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	DeletedAt   *time.Time `gorm:"type:timestamptz(6);index;column:deletedAt" json:"deletedAt"`
 	Forms       []Form     `gorm:"foreignKey:ProjectID" json:"forms"`
 	Team        Team       `gorm:"foreignKey:TeamID;references:ID;onDelete:CASCADE" json:"team"`
 }
@@ -6,6 +6,7 @@ type QuestionOption struct {
 	Label      string   `gorm:"column:label" json:"label"`
 	Value      string   `gorm:"column:value" json:"value"`
 	SortOrder  int      `gorm:"default:0;column:sortOrder" json:"sortOrder"`
+	Score      *float64 `gorm:"column:score" json:"score"`
 	Question   Question `gorm:"foreignKey:QuestionID;references:ID;onDelete:CASCADE" json:"question"`
 }
 
@@ -12,6 +12,7 @@ type Response struct {
 	UserID      *string         `gorm:"type:uuid;column:userId" json:"userId"`
 	Data        datatypes.JSON  `gorm:"type:jsonb;default:'{}';column:data" json:"data"`
 	MetaData    datatypes.JSON  `gorm:"type:jsonb;default:'{}';column:metaData" json:"metaData"`
+	Variables   datatypes.JSON  `gorm:"type:jsonb;default:'{}';column:variables" json:"variables"`
 	Tags        []string        `gorm:"type:text[];column:tags" json:"tags"`
 	Status      *ResponseStatus `gorm:"column:status" json:"status"`
 	PartialSave *bool           `gorm:"column:partialSave" json:"partialSave"`
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ScheduledJob is a form status change to run at RunAt. A form has at most one pending
+// job of each type, and a running job is leased until LockedUntil so that only one API
+// instance runs it. A PUBLISH job carries the version it promotes, snapshotted when it
+// was scheduled, and the editor revision that version was taken from.
+type ScheduledJob struct {
+	ID          string             `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	FormID      string             `gorm:"type:uuid;index;uniqueIndex:scheduled_jobs_pending_key,where:status = 'PENDING';column:formId" json:"formId"`
+	Type        ScheduledJobType   `gorm:"uniqueIndex:scheduled_jobs_pending_key,where:status = 'PENDING';column:type" json:"type"`
+	Status      ScheduledJobStatus `gorm:"default:'PENDING';index:scheduled_jobs_due_idx,priority:1;column:status" json:"status"`
+	RunAt       time.Time          `gorm:"type:timestamptz(6);index:scheduled_jobs_due_idx,priority:2;column:runAt" json:"runAt"`
+	LockedUntil *time.Time         `gorm:"type:timestamptz(6);column:lockedUntil" json:"lockedUntil"`
+	Attempts    int                `gorm:"default:0;column:attempts" json:"attempts"`
+	LastError   *string            `gorm:"column:lastError" json:"lastError"`
+	VersionID   *string            `gorm:"type:uuid;column:versionId" json:"versionId"`
+	Revision    *int               `gorm:"column:revision" json:"revision"`
+	CreatedBy   string             `gorm:"column:createdBy" json:"createdBy"`
+	CreatedAt   time.Time          `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	FinishedAt  *time.Time         `gorm:"type:timestamptz(6);column:finishedAt" json:"finishedAt"`
+	Form        Form               `gorm:"foreignKey:FormID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
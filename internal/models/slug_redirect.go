@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SlugRedirect keeps a form's previous slug pointing at the form for a grace period
+// after the slug has been changed
+type SlugRedirect struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	Slug      string    `gorm:"type:varchar(63);uniqueIndex;column:slug" json:"slug"`
+	FormID    string    `gorm:"type:uuid;index;column:formId" json:"formId"`
+	ExpiresAt time.Time `gorm:"type:timestamptz(6);column:expiresAt" json:"expiresAt"`
+	CreatedAt time.Time `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	Form      Form      `gorm:"foreignKey:FormID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (SlugRedirect) TableName() string {
+	return "slug_redirects"
+}
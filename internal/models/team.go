@@ -10,6 +10,7 @@ type Team struct {
 	Valid        bool              `gorm:"default:true;column:valid" json:"valid"`
 	CreatedAt    time.Time         `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
 	UpdatedAt    *time.Time        `gorm:"type:timestamp(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	DeletedAt    *time.Time        `gorm:"type:timestamptz(6);index;column:deletedAt" json:"deletedAt"`
 	Forms        []Form            `gorm:"foreignKey:TeamID" json:"forms"`
 	Projects     []Project         `gorm:"foreignKey:TeamID" json:"projects"`
 	Plan         *Plan             `gorm:"foreignKey:PlanID" json:"plan"`
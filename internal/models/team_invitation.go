@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// TeamInvitation invites an email address to join a team with a role. Only the SHA-256
+// of the single-use token is stored; the token itself is only ever in the email.
+type TeamInvitation struct {
+	ID         string           `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	TeamID     string           `gorm:"type:uuid;index;uniqueIndex:team_invitations_pending_key,where:status = 'PENDING';column:teamId" json:"teamId"`
+	Email      string           `gorm:"type:varchar;uniqueIndex:team_invitations_pending_key,where:status = 'PENDING';column:email" json:"email"`
+	Role       TeamRole         `gorm:"type:varchar(16);column:role" json:"role"`
+	InvitedBy  string           `gorm:"type:varchar;column:invitedBy" json:"invitedBy"`
+	TokenHash  string           `gorm:"type:varchar(64);uniqueIndex;column:tokenHash" json:"-"`
+	Status     InvitationStatus `gorm:"type:varchar(16);default:'PENDING';column:status" json:"status"`
+	ExpiresAt  time.Time        `gorm:"type:timestamptz(6);column:expiresAt" json:"expiresAt"`
+	AcceptedBy *string          `gorm:"type:varchar;column:acceptedBy" json:"acceptedBy"`
+	AcceptedAt *time.Time       `gorm:"type:timestamptz(6);column:acceptedAt" json:"acceptedAt"`
+	CreatedAt  time.Time        `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	UpdatedAt  *time.Time       `gorm:"type:timestamptz(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	Team       Team             `gorm:"foreignKey:TeamID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (TeamInvitation) TableName() string {
+	return "team_invitations"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TeamMember grants a user a role in a team. The team's OwnerID always holds the
+// OWNER row's user.
+type TeamMember struct {
+	ID        string     `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	TeamID    string     `gorm:"type:uuid;uniqueIndex:team_members_team_user_key;column:teamId" json:"teamId"`
+	UserID    string     `gorm:"type:varchar;uniqueIndex:team_members_team_user_key;index;column:userId" json:"userId"`
+	Role      TeamRole   `gorm:"type:varchar(16);column:role" json:"role"`
+	CreatedAt time.Time  `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	UpdatedAt *time.Time `gorm:"type:timestamptz(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	Team      Team       `gorm:"foreignKey:TeamID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (TeamMember) TableName() string {
+	return "team_members"
+}
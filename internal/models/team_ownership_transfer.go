@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TeamOwnershipTransfer is the owner's offer to hand a team over to one of its members.
+// Ownership only moves once the member accepts; a team has at most one pending offer.
+type TeamOwnershipTransfer struct {
+	ID          string                  `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
+	TeamID      string                  `gorm:"type:uuid;index;uniqueIndex:team_ownership_transfers_pending_key,where:status = 'PENDING';column:teamId" json:"teamId"`
+	FromUserID  string                  `gorm:"type:varchar;column:fromUserId" json:"fromUserId"`
+	ToUserID    string                  `gorm:"type:varchar;index;column:toUserId" json:"toUserId"`
+	Status      OwnershipTransferStatus `gorm:"type:varchar(16);default:'PENDING';column:status" json:"status"`
+	ExpiresAt   time.Time               `gorm:"type:timestamptz(6);column:expiresAt" json:"expiresAt"`
+	RespondedAt *time.Time              `gorm:"type:timestamptz(6);column:respondedAt" json:"respondedAt"`
+	CreatedAt   time.Time               `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
+	UpdatedAt   *time.Time              `gorm:"type:timestamptz(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
+	Team        Team                    `gorm:"foreignKey:TeamID;references:ID;onDelete:CASCADE" json:"-"`
+}
+
+func (TeamOwnershipTransfer) TableName() string {
+	return "team_ownership_transfers"
+}
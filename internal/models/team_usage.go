@@ -14,6 +14,11 @@ type TeamSubscription struct {
 	CycleStart      time.Time                `gorm:"type:timestamptz(6);column:cycleStart" json:"cycleStart"`
 	CycleEnd        time.Time                `gorm:"type:timestamptz(6);column:cycleEnd" json:"cycleEnd"`
 	CancelCycleAt   *time.Time               `gorm:"type:timestamptz(6);column:cancelCycleAt" json:"cancelCycleAt"`
+	// NextPlanID is a downgrade that takes effect when the current cycle ends
+	NextPlanID      *string                  `gorm:"type:uuid;column:nextPlanId" json:"nextPlanId"`
+	PaymentFailedAt *time.Time               `gorm:"type:timestamptz(6);column:paymentFailedAt" json:"paymentFailedAt"`
+	// GraceEndsAt is when a team in GRACE is blocked unless it is back within its limits and paid
+	GraceEndsAt     *time.Time               `gorm:"type:timestamptz(6);column:graceEndsAt" json:"graceEndsAt"`
 	CreatedAt       time.Time                `gorm:"type:timestamptz(6);default:now();column:createdAt" json:"createdAt"`
 	UpdatedAt       time.Time                `gorm:"type:timestamptz(6);autoUpdateTime;column:updatedAt" json:"updatedAt"`
 	Team            []Team                   `gorm:"foreignKey:TeamUsageID" json:"team"`
@@ -4,8 +4,8 @@ import "time"
 
 type UsageRecord struct {
 	ID            string          `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();column:id" json:"id"`
-	TeamID        string          `gorm:"type:uuid;index;column:teamId" json:"teamId"`
-	CycleStart    time.Time       `gorm:"type:timestamptz(6);column:cycleStart" json:"cycleStart"`
+	TeamID        string          `gorm:"type:uuid;index;uniqueIndex:idx_usage_records_team_cycle;column:teamId" json:"teamId"`
+	CycleStart    time.Time       `gorm:"type:timestamptz(6);uniqueIndex:idx_usage_records_team_cycle;column:cycleStart" json:"cycleStart"`
 	FormsUsed     int             `gorm:"default:0;column:formsUsed" json:"formsUsed"`
 	ProjectsUsed  int             `gorm:"default:0;column:projectsUsed" json:"projectsUsed"`
 	ResponsesUsed int             `gorm:"default:0;column:responsesUsed" json:"responsesUsed"`
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IAPIKeyRepo interface {
+	GetById(ctx context.Context, teamId string, keyId string) (*models.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	GetByTeam(ctx context.Context, teamId string) ([]*models.APIKey, error)
+	Create(ctx context.Context, key *models.APIKey) error
+	Revoke(ctx context.Context, keyId string, at time.Time) (bool, error)
+	Touch(ctx context.Context, keyId string, at time.Time, after time.Time) error
+}
+
+type APIKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) *APIKeyRepo {
+	return &APIKeyRepo{
+		db: db,
+	}
+}
+
+func (r *APIKeyRepo) GetById(ctx context.Context, teamId string, keyId string) (*models.APIKey, error) {
+
+	var key *models.APIKey
+	err := r.db.WithContext(ctx).
+		Where(`id = ? AND "teamId" = ?`, keyId, teamId).
+		First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return key, nil
+}
+
+// GetByHash returns the key with its team
+func (r *APIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+
+	var key *models.APIKey
+	err := r.db.WithContext(ctx).
+		Preload("Team").
+		Where(`"keyHash" = ?`, keyHash).
+		First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepo) GetByTeam(ctx context.Context, teamId string) ([]*models.APIKey, error) {
+
+	var keys []*models.APIKey
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ?`, teamId).
+		Order(`"createdAt" DESC`).
+		Find(&keys).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, key *models.APIKey) error {
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(key).Error; err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// Revoke revokes a key unless it already is, reporting whether it did
+func (r *APIKeyRepo) Revoke(ctx context.Context, keyId string, at time.Time) (bool, error) {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where(`id = ? AND "revokedAt" IS NULL`, keyId).
+		Update("revokedAt", at)
+	if result.Error != nil {
+		return false, errors.Internal(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Touch records a use of the key, skipping the write when it was already recorded
+// after the given time so that busy keys are not updated on every request
+func (r *APIKeyRepo) Touch(ctx context.Context, keyId string, at time.Time, after time.Time) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where(`id = ? AND ("lastUsedAt" IS NULL OR "lastUsedAt" < ?)`, keyId, after).
+		UpdateColumn("lastUsedAt", at).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
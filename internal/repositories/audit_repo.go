@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter is a parsed types.AuditLogQuery
+type AuditLogFilter struct {
+	ActorID      string
+	ResourceType string
+	ResourceID   string
+	Action       string
+	From         *time.Time
+	To           *time.Time
+}
+
+type IAuditRepo interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+	Get(ctx context.Context, teamId string, filter *AuditLogFilter, pagination *types.PaginationQuery) ([]*models.AuditLog, int64, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type AuditRepo struct {
+	db *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) *AuditRepo {
+	return &AuditRepo{
+		db: db,
+	}
+}
+
+func (r *AuditRepo) Create(ctx context.Context, entry *models.AuditLog) error {
+
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// Get lists the team's audit entries matching the filter, newest first
+func (r *AuditRepo) Get(ctx context.Context, teamId string, filter *AuditLogFilter, pagination *types.PaginationQuery) ([]*models.AuditLog, int64, error) {
+
+	query := r.db.WithContext(ctx).
+		Model(&models.AuditLog{}).
+		Where(`"teamId" = ?`, teamId)
+
+	if filter.ActorID != "" {
+		query = query.Where(`"actorId" = ?`, filter.ActorID)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where(`"resourceType" = ?`, filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where(`"resourceId" = ?`, filter.ResourceID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where(`"createdAt" >= ?`, *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(`"createdAt" < ?`, *filter.To)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, errors.Internal(err)
+	}
+
+	var entries []*models.AuditLog
+	err := query.
+		Order(`"createdAt" DESC, id DESC`).
+		Limit(pagination.Limit).
+		Offset((pagination.Page - 1) * pagination.Limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, errors.Internal(err)
+	}
+	return entries, totalCount, nil
+}
+
+// Purge deletes entries older than the cutoff
+func (r *AuditRepo) Purge(ctx context.Context, before time.Time) (int64, error) {
+
+	result := r.db.WithContext(ctx).
+		Where(`"createdAt" < ?`, before).
+		Delete(&models.AuditLog{})
+	if result.Error != nil {
+		return 0, errors.Internal(result.Error)
+	}
+	return result.RowsAffected, nil
+}
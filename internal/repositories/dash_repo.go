@@ -17,6 +17,10 @@ type IDashRepo interface {
 	GetAnalytics(c context.Context, formId string) (*types.FormAnalytics, error)
 	GetQuestions(ctx context.Context, formId string) ([]*models.Question, error)
 	GetResponses(ctx context.Context, formId string) ([]*models.Response, error)
+	EachResponse(ctx context.Context, formId string, fn func(response *models.Response) error) error
+	GetResponse(ctx context.Context, formId string, responseId string) (*models.Response, error)
+	UpdateResponse(ctx context.Context, responseId string, updates map[string]interface{}) error
+	DeleteResponse(ctx context.Context, responseId string) error
 
 	// PASSWORD CONFIG
 	GetPasswords(ctx context.Context, formId string) ([]*models.ActivePassword, error)
@@ -145,6 +149,11 @@ func (r *DashRepo) GetAnalytics(ctx context.Context, formId string) (*types.Form
 		})
 	}
 
+	variableSummaries, err := r.summarizeVariables(ctx, formId)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
 	analytics := &types.FormAnalytics{
 		ResponseCount:      responseCount,
 		AvgCompletionTime:  avgCompletionTime,
@@ -155,11 +164,39 @@ func (r *DashRepo) GetAnalytics(ctx context.Context, formId string) (*types.Form
 		CompletionRate:     completionRate,
 		TodayResponseCount: todayResponseCount,
 		SubmitDataPoints:   submitDataPoints,
+		Variables:          variableSummaries,
 	}
 
 	return analytics, nil
 }
 
+// summarizeVariables aggregates the computed variables of completed responses in SQL,
+// so that a form with many responses is not loaded into memory for it
+func (r *DashRepo) summarizeVariables(ctx context.Context, formId string) ([]types.VariableSummary, error) {
+
+	var summaries []types.VariableSummary
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT v.key AS name, COUNT(*) AS count, AVG(v.value::float8) AS avg, MIN(v.value::float8) AS min, MAX(v.value::float8) AS max
+		FROM responses
+		CROSS JOIN LATERAL jsonb_each(CASE WHEN jsonb_typeof(responses.variables) = 'object' THEN responses.variables ELSE '{}'::jsonb END) AS v
+		WHERE responses."formId" = ? AND responses.valid = true AND responses."submittedAt" IS NOT NULL
+			AND jsonb_typeof(v.value) = 'number'
+		GROUP BY v.key
+		ORDER BY v.key`, formId).
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if summaries == nil {
+		summaries = []types.VariableSummary{}
+	}
+	for i := range summaries {
+		summaries[i].Avg = math.Round(summaries[i].Avg*100) / 100
+	}
+	return summaries, nil
+}
+
 func (r *DashRepo) GetQuestions(ctx context.Context, formId string) ([]*models.Question, error) {
 	var questions []*models.Question
 	err := r.db.WithContext(ctx).Preload("Options").Model(&models.Question{}).
@@ -173,8 +210,87 @@ func (r *DashRepo) GetQuestions(ctx context.Context, formId string) ([]*models.Q
 }
 
 func (r *DashRepo) GetResponses(ctx context.Context, formId string) ([]*models.Response, error) {
-	// TODO: Implement GetResponses logic
-	return nil, nil
+	var responses []*models.Response
+	err := r.db.WithContext(ctx).Model(&models.Response{}).
+		Where(`"formId" = ? AND valid = ?`, formId, true).
+		Order(`"submittedAt" DESC NULLS LAST`).
+		Find(&responses).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return responses, nil
+}
+
+// EachResponse streams the form's responses to fn in the order of GetResponses, one row
+// at a time, for reports over every response. An error from fn stops it.
+func (r *DashRepo) EachResponse(ctx context.Context, formId string, fn func(response *models.Response) error) error {
+
+	rows, err := r.db.WithContext(ctx).Model(&models.Response{}).
+		Where(`"formId" = ? AND valid = ?`, formId, true).
+		Order(`"submittedAt" DESC NULLS LAST`).
+		Rows()
+	if err != nil {
+		return errors.Internal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var response models.Response
+		if err := r.db.ScanRows(rows, &response); err != nil {
+			return errors.Internal(err)
+		}
+		if err := fn(&response); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *DashRepo) GetResponse(ctx context.Context, formId string, responseId string) (*models.Response, error) {
+
+	var response *models.Response
+	err := r.db.WithContext(ctx).
+		Where(`id = ? AND "formId" = ? AND valid = ?`, responseId, formId, true).
+		First(&response).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return response, nil
+}
+
+func (r *DashRepo) UpdateResponse(ctx context.Context, responseId string, updates map[string]interface{}) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Response{}).
+		Where("id = ? AND valid = ?", responseId, true).
+		Updates(updates).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// DeleteResponse removes the response from reports, analytics and exports. It stays
+// counted towards the team's submission usage, which is metered when it arrived.
+func (r *DashRepo) DeleteResponse(ctx context.Context, responseId string) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Response{}).
+		Where("id = ? AND valid = ?", responseId, true).
+		Updates(map[string]interface{}{
+			"valid":     false,
+			"updatedAt": time.Now(),
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
 }
 
 func (r *DashRepo) GetPasswords(ctx context.Context, formId string) ([]*models.ActivePassword, error) {
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IDomainRepo interface {
+	GetByForm(ctx context.Context, formId string) (*models.CustomDomain, error)
+	GetVerified(ctx context.Context, domain string) (*models.CustomDomain, error)
+	Claim(ctx context.Context, customDomain *models.CustomDomain) (*models.CustomDomain, error)
+	Update(ctx context.Context, id string, updates map[string]interface{}) error
+	MarkVerified(ctx context.Context, customDomain *models.CustomDomain, verifiedAt time.Time) error
+	DeleteByForm(ctx context.Context, formId string) error
+}
+
+type DomainRepo struct {
+	db *gorm.DB
+}
+
+func NewDomainRepo(db *gorm.DB) *DomainRepo {
+	return &DomainRepo{
+		db: db,
+	}
+}
+
+func (r *DomainRepo) GetByForm(ctx context.Context, formId string) (*models.CustomDomain, error) {
+
+	var customDomain *models.CustomDomain
+	err := r.db.WithContext(ctx).
+		Where(`"formId" = ?`, formId).
+		First(&customDomain).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return customDomain, nil
+}
+
+func (r *DomainRepo) GetVerified(ctx context.Context, domain string) (*models.CustomDomain, error) {
+
+	var customDomain *models.CustomDomain
+	err := r.db.WithContext(ctx).
+		Where(`domain = ? AND status = ?`, domain, models.DomainStatusVerified).
+		First(&customDomain).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return customDomain, nil
+}
+
+// Claim replaces the form's domain claim with a new pending one. The form stops
+// answering on its previous custom domain until the new one is verified.
+func (r *DomainRepo) Claim(ctx context.Context, customDomain *models.CustomDomain) (*models.CustomDomain, error) {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(`"formId" = ?`, customDomain.FormID).
+			Delete(&models.CustomDomain{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Form{}).
+			Where("id = ?", customDomain.FormID).
+			Update("customDomain", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(customDomain).Error
+	})
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return customDomain, nil
+}
+
+func (r *DomainRepo) Update(ctx context.Context, id string, updates map[string]interface{}) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.CustomDomain{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// MarkVerified records a successful challenge and points the form at the domain. A
+// domain verified for another form in the meantime is a conflict.
+func (r *DomainRepo) MarkVerified(ctx context.Context, customDomain *models.CustomDomain, verifiedAt time.Time) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.CustomDomain{}).
+			Where("id = ?", customDomain.ID).
+			Updates(map[string]interface{}{
+				"status":        models.DomainStatusVerified,
+				"lastError":     nil,
+				"lastCheckedAt": verifiedAt,
+				"verifiedAt":    verifiedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Form{}).
+			Where("id = ?", customDomain.FormID).
+			Update("customDomain", customDomain.Domain).Error
+	})
+	if err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("Domain is already in use by another form")
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *DomainRepo) DeleteByForm(ctx context.Context, formId string) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(`"formId" = ?`, formId).
+			Delete(&models.CustomDomain{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Form{}).
+			Where("id = ?", formId).
+			Update("customDomain", nil).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
@@ -20,10 +22,23 @@ type IFormRepo interface {
 	Create(ctx context.Context, form *models.Form) (*models.Form, error)
 	Update(ctx context.Context, formId string, updates map[string]interface{}) error
 	UpdateStatus(ctx context.Context, formId string, status models.FormStatus) error
+	SetMetadataKey(ctx context.Context, formId string, key string, value interface{}) error
 	Delete(ctx context.Context, formId string) error
+	GetDeleted(ctx context.Context, formId string) (*models.Form, error)
+	Restore(ctx context.Context, formId string) error
+
+	CountByTeam(ctx context.Context, teamId string) (int64, error)
+	Move(ctx context.Context, formId string, projectId string, teamId string) error
 
 	GetByDomain(ctx context.Context, domain string) (*models.Form, error)
 	UpdateQuestionSequence(ctx context.Context, formId string, sequence []*types.SequenceItem) error
+
+	TransitionStatus(ctx context.Context, transition *models.FormStatusTransition, updates map[string]interface{}) error
+	GetStatusTransitions(ctx context.Context, formId string) ([]*models.FormStatusTransition, error)
+
+	IsSlugTaken(ctx context.Context, slug string, formId string) (bool, error)
+	GetSlugRedirect(ctx context.Context, slug string) (*models.SlugRedirect, error)
+	UpdateSlug(ctx context.Context, form *models.Form, slug string, redirectUntil time.Time) error
 }
 
 type FormRepo struct {
@@ -104,6 +119,9 @@ func (r *FormRepo) Create(ctx context.Context, form *models.Form) (*models.Form,
 		Model(&models.Form{}).
 		Create(form).Error
 	if err != nil {
+		if errors.IsUniqueViolation(err) {
+			return nil, errors.Conflict("Slug is already taken")
+		}
 		return nil, errors.Internal(err)
 	}
 	return r.GetById(ctx, form.ID)
@@ -135,6 +153,33 @@ func (r *FormRepo) Update(ctx context.Context, formId string, updates map[string
 	return nil
 }
 
+// SetMetadataKey replaces a single top-level key of the form's metadata in place, so
+// that concurrent changes to other keys are not lost
+func (r *FormRepo) SetMetadataKey(ctx context.Context, formId string, key string, value interface{}) error {
+
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return errors.Internal(err)
+	}
+	err = r.db.WithContext(ctx).
+		Model(&models.Form{}).
+		Where(&models.Form{
+			ID:    formId,
+			Valid: true,
+		}).
+		Updates(map[string]interface{}{
+			"metadata": gorm.Expr(
+				`jsonb_set(CASE WHEN jsonb_typeof(metadata) = 'object' THEN metadata ELSE '{}'::jsonb END, ARRAY[?::text], ?::jsonb)`,
+				key, string(jsonBytes),
+			),
+			"updatedAt": utils.GetCurrentTime(),
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
 func (r *FormRepo) UpdateStatus(ctx context.Context, formId string, status models.FormStatus) error {
 
 	err := r.db.WithContext(ctx).
@@ -173,16 +218,109 @@ func (r *FormRepo) GetWithTeam(ctx context.Context, formId string) (*models.Form
 	return form, nil
 }
 
+// Delete moves the form to the trash
 func (r *FormRepo) Delete(ctx context.Context, formId string) error {
 
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return softDeleteForms(tx, "id", formId, trashTime())
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// GetDeleted returns a form from the trash with its project and team
+func (r *FormRepo) GetDeleted(ctx context.Context, formId string) (*models.Form, error) {
+
+	var form *models.Form
+	err := r.db.WithContext(ctx).
+		Preload("Project").
+		Preload("Team").
+		Where(`id = ? AND valid = false AND "deletedAt" IS NOT NULL`, formId).
+		First(&form).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return form, nil
+}
+
+func (r *FormRepo) Restore(ctx context.Context, formId string) error {
+
 	err := r.db.WithContext(ctx).
 		Model(&models.Form{}).
-		Where("id = ?", formId).
-		Updates(map[string]interface{}{
-			"valid":     false,
-			"updatedAt": utils.GetCurrentTime(),
-		}).Error
+		Where("id = ? AND valid = false", formId).
+		Updates(restoreUpdates()).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// CountByTeam counts the team's forms, leaving out those in the trash
+func (r *FormRepo) CountByTeam(ctx context.Context, teamId string) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Form{}).
+		Where(&models.Form{
+			TeamID: teamId,
+			Valid:  true,
+		}).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
+
+// Move re-parents a form. Everything else hangs off the form ID and moves along with it,
+// except the storage metered for the form's uploads, which is moved to the new team with
+// a negative usage event for each team it was counted against and a positive one for
+// the new team, so that aggregation picks up both.
+func (r *FormRepo) Move(ctx context.Context, formId string, projectId string, teamId string) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Form{}).
+			Where(&models.Form{
+				ID:    formId,
+				Valid: true,
+			}).
+			Updates(map[string]interface{}{
+				"projectId": projectId,
+				"teamId":    teamId,
+				"updatedAt": utils.GetCurrentTime(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.NotFound("Form")
+		}
+
+		return tx.Exec(`
+			INSERT INTO usage_events (id, "teamId", type, quantity, "refId", "createdAt")
+			SELECT uuid_generate_v4(), "teamId", type, -SUM(quantity), "refId", now()
+			FROM usage_events
+			WHERE type = @type AND "refId" = @formId AND "teamId" <> @teamId
+			GROUP BY "teamId", type, "refId"
+			HAVING SUM(quantity) <> 0
+			UNION ALL
+			SELECT uuid_generate_v4(), @teamId, type, SUM(quantity), "refId", now()
+			FROM usage_events
+			WHERE type = @type AND "refId" = @formId AND "teamId" <> @teamId
+			GROUP BY type, "refId"
+			HAVING SUM(quantity) <> 0`,
+			map[string]interface{}{"type": models.UsageEventStorageBytes, "formId": formId, "teamId": teamId},
+		).Error
+	})
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
 		return errors.Internal(err)
 	}
 	return nil
@@ -214,11 +352,16 @@ func (r *FormRepo) UpdateQuestionSequence(ctx context.Context, formId string, se
 	return nil
 }
 
+// GetByDomain resolves a form by its subdomain or by a custom domain that has been verified for it
 func (r *FormRepo) GetByDomain(ctx context.Context, domain string) (*models.Form, error) {
 	var form *models.Form
+	verified := r.db.
+		Model(&models.CustomDomain{}).
+		Select("1").
+		Where(`custom_domains."formId" = forms.id AND custom_domains.domain = ? AND custom_domains.status = ?`, domain, models.DomainStatusVerified)
 	err := r.db.WithContext(ctx).
 		Model(&models.Form{}).
-		Where(`("uniqueSubdomain" = ? OR "customDomain" = ?) AND valid = true`, domain, domain).
+		Where(`(lower("uniqueSubdomain") = lower(?) OR ("customDomain" = ? AND EXISTS (?))) AND valid = true`, domain, domain, verified).
 		First(&form).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -228,3 +371,157 @@ func (r *FormRepo) GetByDomain(ctx context.Context, domain string) (*models.Form
 	}
 	return form, nil
 }
+
+// IsSlugTaken reports whether another form uses the slug, or still redirects from it
+func (r *FormRepo) IsSlugTaken(ctx context.Context, slug string, formId string) (bool, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Form{}).
+		Where(`lower("uniqueSubdomain") = lower(?) AND id <> ?`, slug, formId).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Internal(err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err = r.db.WithContext(ctx).
+		Model(&models.SlugRedirect{}).
+		Where(`slug = lower(?) AND "formId" <> ? AND "expiresAt" > ?`, slug, formId, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Internal(err)
+	}
+	return count > 0, nil
+}
+
+// GetSlugRedirect returns the unexpired redirect of a previous slug
+func (r *FormRepo) GetSlugRedirect(ctx context.Context, slug string) (*models.SlugRedirect, error) {
+
+	var redirect *models.SlugRedirect
+	err := r.db.WithContext(ctx).
+		Preload("Form").
+		Where(`slug = lower(?) AND "expiresAt" > ?`, slug, time.Now()).
+		First(&redirect).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return redirect, nil
+}
+
+// UpdateSlug changes the form's slug and keeps the previous one redirecting until redirectUntil
+func (r *FormRepo) UpdateSlug(ctx context.Context, form *models.Form, slug string, redirectUntil time.Time) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Expired redirects are cleaned up here, and a form can take back one of its old slugs
+		err := tx.Where(`slug = ? OR "expiresAt" <= ?`, slug, time.Now()).
+			Delete(&models.SlugRedirect{}).Error
+		if err != nil {
+			return err
+		}
+
+		if form.UniqueSubdomain != nil && strings.ToLower(*form.UniqueSubdomain) != slug {
+			redirect := &models.SlugRedirect{
+				ID:        utils.GenerateUUID(),
+				Slug:      strings.ToLower(*form.UniqueSubdomain),
+				FormID:    form.ID,
+				ExpiresAt: redirectUntil,
+				CreatedAt: time.Now(),
+			}
+			err = tx.Omit(clause.Associations).
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "slug"}},
+					DoUpdates: clause.AssignmentColumns([]string{"formId", "expiresAt"}),
+				}).
+				Create(redirect).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Form{}).
+			Where("id = ?", form.ID).
+			Updates(map[string]interface{}{
+				"uniqueSubdomain": slug,
+				"updatedAt":       utils.GetCurrentTime(),
+			}).Error
+	})
+	if err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("Slug is already taken")
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// TransitionStatus moves the form from transition.FromStatus to transition.ToStatus along
+// with any other column updates, and records the transition. Archiving also revokes the
+// form's passwords in the same transaction. It fails with a conflict when the status was
+// changed concurrently.
+func (r *FormRepo) TransitionStatus(ctx context.Context, transition *models.FormStatusTransition, updates map[string]interface{}) error {
+
+	changes := map[string]interface{}{
+		"status":    transition.ToStatus,
+		"updatedAt": utils.GetCurrentTime(),
+	}
+	for k, v := range updates {
+		changes[k] = v
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Form{}).Where("id = ? AND valid = true", transition.FormID)
+		if transition.FromStatus == models.FormStatusDraft {
+			query = query.Where("(status = ? OR status IS NULL)", transition.FromStatus)
+		} else {
+			query = query.Where("status = ?", transition.FromStatus)
+		}
+		result := query.Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.Conflict("Form status has changed, reload and try again")
+		}
+		if err := tx.Omit(clause.Associations).Create(transition).Error; err != nil {
+			return err
+		}
+		if transition.ToStatus == models.FormStatusArchived {
+			// Archived forms are read-only, nobody should keep a way in
+			return revokePasswords(tx, transition.FormID)
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *FormRepo) GetStatusTransitions(ctx context.Context, formId string) ([]*models.FormStatusTransition, error) {
+
+	var transitions []*models.FormStatusTransition
+	err := r.db.WithContext(ctx).
+		Where(`"formId" = ?`, formId).
+		Order(`"createdAt" DESC`).
+		Find(&transitions).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return transitions, nil
+}
+
+// revokePasswords invalidates every access password of the form
+func revokePasswords(tx *gorm.DB, formId string) error {
+	return tx.Model(&models.ActivePassword{}).
+		Where(`"formId" = ? AND "isValid" = true`, formId).
+		Update("isValid", false).Error
+}
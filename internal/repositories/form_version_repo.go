@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
@@ -13,6 +14,7 @@ type IFormVersionRepo interface {
 	GetByFormID(ctx context.Context, formId string) ([]*models.FormVersion, error)
 	GetByID(ctx context.Context, versionId string) (*models.FormVersion, error)
 	GetLatestVersion(ctx context.Context, formId string) (*models.FormVersion, error)
+	MarkPublished(ctx context.Context, versionId string, publishedAt time.Time) error
 }
 
 type FormVersionRepo struct {
@@ -75,3 +77,14 @@ func (r *FormVersionRepo) GetLatestVersion(ctx context.Context, formId string) (
 	}
 	return &version, nil
 }
+
+func (r *FormVersionRepo) MarkPublished(ctx context.Context, versionId string, publishedAt time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.FormVersion{}).
+		Where("id = ?", versionId).
+		Update("publishedAt", publishedAt).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IInvitationRepo interface {
+	GetById(ctx context.Context, teamId string, invitationId string) (*models.TeamInvitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.TeamInvitation, error)
+	GetPending(ctx context.Context, teamId string) ([]*models.TeamInvitation, error)
+	CountPending(ctx context.Context, teamId string, now time.Time) (int64, error)
+	Create(ctx context.Context, invitation *models.TeamInvitation) error
+	UpdatePending(ctx context.Context, invitationId string, updates map[string]interface{}) (bool, error)
+	Accept(ctx context.Context, invitation *models.TeamInvitation, member *models.TeamMember) error
+}
+
+type InvitationRepo struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepo(db *gorm.DB) *InvitationRepo {
+	return &InvitationRepo{
+		db: db,
+	}
+}
+
+func (r *InvitationRepo) GetById(ctx context.Context, teamId string, invitationId string) (*models.TeamInvitation, error) {
+
+	var invitation *models.TeamInvitation
+	err := r.db.WithContext(ctx).
+		Where(`id = ? AND "teamId" = ?`, invitationId, teamId).
+		First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return invitation, nil
+}
+
+// GetByTokenHash returns the invitation with its team
+func (r *InvitationRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.TeamInvitation, error) {
+
+	var invitation *models.TeamInvitation
+	err := r.db.WithContext(ctx).
+		Preload("Team").
+		Where(`"tokenHash" = ?`, tokenHash).
+		First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return invitation, nil
+}
+
+func (r *InvitationRepo) GetPending(ctx context.Context, teamId string) ([]*models.TeamInvitation, error) {
+
+	var invitations []*models.TeamInvitation
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ? AND status = ?`, teamId, models.InvitationStatusPending).
+		Order(`"createdAt" DESC`).
+		Find(&invitations).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return invitations, nil
+}
+
+// CountPending counts the invitations that can still be accepted and so hold a seat
+func (r *InvitationRepo) CountPending(ctx context.Context, teamId string, now time.Time) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TeamInvitation{}).
+		Where(`"teamId" = ? AND status = ? AND "expiresAt" > ?`, teamId, models.InvitationStatusPending, now).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
+
+// Create stores a new invitation. A lapsed pending invitation for the same email is
+// marked expired first; a live one makes this fail with Conflict.
+func (r *InvitationRepo) Create(ctx context.Context, invitation *models.TeamInvitation) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.TeamInvitation{}).
+			Where(`"teamId" = ? AND email = ? AND status = ? AND "expiresAt" <= ?`,
+				invitation.TeamID, invitation.Email, models.InvitationStatusPending, time.Now()).
+			Update("status", models.InvitationStatusExpired).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(invitation).Error
+	})
+	if err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("This email already has a pending invitation, resend it instead")
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// UpdatePending updates an invitation that is still pending and reports whether it was
+func (r *InvitationRepo) UpdatePending(ctx context.Context, invitationId string, updates map[string]interface{}) (bool, error) {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.TeamInvitation{}).
+		Where("id = ? AND status = ?", invitationId, models.InvitationStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		return false, errors.Internal(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Accept marks the invitation used and adds the member. The status check makes the
+// token single-use even when it is redeemed twice at once.
+func (r *InvitationRepo) Accept(ctx context.Context, invitation *models.TeamInvitation, member *models.TeamMember) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TeamInvitation{}).
+			Where(`id = ? AND status = ? AND "expiresAt" > ?`, invitation.ID, models.InvitationStatusPending, time.Now()).
+			Updates(map[string]interface{}{
+				"status":     models.InvitationStatusAccepted,
+				"acceptedBy": member.UserID,
+				"acceptedAt": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.Conflict("This invitation is no longer valid")
+		}
+
+		err := tx.Omit(clause.Associations).Create(member).Error
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("You are already a member of this team")
+		}
+		return err
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
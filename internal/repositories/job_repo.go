@@ -0,0 +1,230 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IJobRepo interface {
+	GetByForm(ctx context.Context, formId string) ([]*models.ScheduledJob, error)
+	GetById(ctx context.Context, jobId string) (*models.ScheduledJob, error)
+	Schedule(ctx context.Context, job *models.ScheduledJob) (*models.ScheduledJob, error)
+	Enqueue(ctx context.Context, job *models.ScheduledJob) error
+	GetFormsMissingJob(ctx context.Context, jobType models.ScheduledJobType, now time.Time, limit int) ([]*models.Form, error)
+	Cancel(ctx context.Context, formId string, jobType models.ScheduledJobType) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.ScheduledJob, error)
+	Finish(ctx context.Context, jobId string, status models.ScheduledJobStatus, lastError *string) error
+	Retry(ctx context.Context, jobId string, runAt time.Time, lastError string) error
+}
+
+type JobRepo struct {
+	db *gorm.DB
+}
+
+func NewJobRepo(db *gorm.DB) *JobRepo {
+	return &JobRepo{
+		db: db,
+	}
+}
+
+func (r *JobRepo) GetByForm(ctx context.Context, formId string) ([]*models.ScheduledJob, error) {
+
+	var jobs []*models.ScheduledJob
+	err := r.db.WithContext(ctx).
+		Where(`"formId" = ?`, formId).
+		Order(`"runAt" DESC`).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return jobs, nil
+}
+
+func (r *JobRepo) GetById(ctx context.Context, jobId string) (*models.ScheduledJob, error) {
+
+	var job *models.ScheduledJob
+	err := r.db.WithContext(ctx).
+		Where("id = ?", jobId).
+		First(&job).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return job, nil
+}
+
+// Schedule replaces the pending job of the same type for the form
+func (r *JobRepo) Schedule(ctx context.Context, job *models.ScheduledJob) (*models.ScheduledJob, error) {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ScheduledJob{}).
+			Where(`"formId" = ? AND type = ? AND status = ?`, job.FormID, job.Type, models.ScheduledJobStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.ScheduledJobStatusCancelled,
+				"finishedAt": time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(job).Error
+	})
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return job, nil
+}
+
+// Enqueue adds the job, or when the form already has a pending job of the same type
+// brings that job forward to the new job's runAt if it is earlier, so that a form that
+// fills up closes now rather than at its closeAt
+func (r *JobRepo) Enqueue(ctx context.Context, job *models.ScheduledJob) error {
+
+	err := r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "formId"}, {Name: "type"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Name: "status"}, Value: models.ScheduledJobStatusPending}}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"runAt": gorm.Expr(`LEAST(scheduled_jobs."runAt", excluded."runAt")`),
+			}),
+		}).
+		Create(job).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// GetFormsMissingJob lists active forms whose openAt or closeAt calls for a job of the
+// given type that does not exist: a future openAt without a pending OPEN job, or a
+// closeAt without a pending CLOSE job or a CLOSE job that already ran for it
+func (r *JobRepo) GetFormsMissingJob(ctx context.Context, jobType models.ScheduledJobType, now time.Time, limit int) ([]*models.Form, error) {
+
+	pending := r.db.Model(&models.ScheduledJob{}).
+		Select("1").
+		Where(`scheduled_jobs."formId" = forms.id AND scheduled_jobs.type = ?`, jobType).
+		Where(`scheduled_jobs.status IN ?`, []models.ScheduledJobStatus{models.ScheduledJobStatusPending, models.ScheduledJobStatusRunning})
+
+	query := r.db.WithContext(ctx).
+		Model(&models.Form{}).
+		Where("forms.valid = true")
+	switch jobType {
+	case models.ScheduledJobOpen:
+		query = query.Where(`forms."openAt" > ? AND NOT EXISTS (?)`, now, pending)
+	case models.ScheduledJobClose:
+		ran := r.db.Model(&models.ScheduledJob{}).
+			Select("1").
+			Where(`scheduled_jobs."formId" = forms.id AND scheduled_jobs.type = ?`, jobType).
+			Where(`scheduled_jobs.status = ? AND scheduled_jobs."runAt" >= forms."closeAt"`, models.ScheduledJobStatusDone)
+		query = query.Where(`forms."closeAt" IS NOT NULL AND NOT EXISTS (?) AND NOT EXISTS (?)`, pending, ran)
+	default:
+		return nil, nil
+	}
+
+	var forms []*models.Form
+	if err := query.Limit(limit).Find(&forms).Error; err != nil {
+		return nil, errors.Internal(err)
+	}
+	return forms, nil
+}
+
+func (r *JobRepo) Cancel(ctx context.Context, formId string, jobType models.ScheduledJobType) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.ScheduledJob{}).
+		Where(`"formId" = ? AND type = ? AND status = ?`, formId, jobType, models.ScheduledJobStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.ScheduledJobStatusCancelled,
+			"finishedAt": time.Now(),
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// ClaimDue leases due jobs to the caller. Rows locked by another instance are skipped,
+// and running jobs whose lease expired, because their instance died, are picked up again.
+func (r *JobRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.ScheduledJob, error) {
+
+	var jobs []*models.ScheduledJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(`(status = ? AND "runAt" <= ?) OR (status = ? AND "lockedUntil" < ?)`,
+				models.ScheduledJobStatusPending, now, models.ScheduledJobStatusRunning, now).
+			Order(`"runAt" ASC`).
+			Limit(limit).
+			Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		lockedUntil := now.Add(lease)
+		err = tx.Model(&models.ScheduledJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":      models.ScheduledJobStatusRunning,
+				"lockedUntil": lockedUntil,
+				"attempts":    gorm.Expr("attempts + 1"),
+			}).Error
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			job.Status = models.ScheduledJobStatusRunning
+			job.LockedUntil = &lockedUntil
+			job.Attempts++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return jobs, nil
+}
+
+func (r *JobRepo) Finish(ctx context.Context, jobId string, status models.ScheduledJobStatus, lastError *string) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.ScheduledJob{}).
+		Where("id = ? AND status = ?", jobId, models.ScheduledJobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"lastError":   lastError,
+			"lockedUntil": nil,
+			"finishedAt":  time.Now(),
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// Retry puts a failed running job back in the queue
+func (r *JobRepo) Retry(ctx context.Context, jobId string, runAt time.Time, lastError string) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.ScheduledJob{}).
+		Where("id = ? AND status = ?", jobId, models.ScheduledJobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      models.ScheduledJobStatusPending,
+			"runAt":       runAt,
+			"lastError":   lastError,
+			"lockedUntil": nil,
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type IMemberRepo interface {
+	Get(ctx context.Context, teamId string, userId string) (*models.TeamMember, error)
+	GetByTeam(ctx context.Context, teamId string) ([]*models.TeamMember, error)
+	CountSeats(ctx context.Context, team *models.Team) (int64, error)
+	UpdateRole(ctx context.Context, teamId string, userId string, role models.TeamRole) error
+	Delete(ctx context.Context, teamId string, userId string) error
+}
+
+type MemberRepo struct {
+	db *gorm.DB
+}
+
+func NewMemberRepo(db *gorm.DB) *MemberRepo {
+	return &MemberRepo{
+		db: db,
+	}
+}
+
+func (r *MemberRepo) Get(ctx context.Context, teamId string, userId string) (*models.TeamMember, error) {
+
+	var member *models.TeamMember
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ? AND "userId" = ?`, teamId, userId).
+		First(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return member, nil
+}
+
+func (r *MemberRepo) GetByTeam(ctx context.Context, teamId string) ([]*models.TeamMember, error) {
+
+	var members []*models.TeamMember
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ?`, teamId).
+		Order(`"createdAt"`).
+		Find(&members).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return members, nil
+}
+
+// CountSeats counts the team's members, including an owner who predates memberships
+// and so has no row
+func (r *MemberRepo) CountSeats(ctx context.Context, team *models.Team) (int64, error) {
+
+	query := r.db.WithContext(ctx).
+		Model(&models.TeamMember{}).
+		Where(`"teamId" = ?`, team.ID)
+	if team.OwnerID != nil {
+		query = query.Where(`"userId" <> ?`, *team.OwnerID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, errors.Internal(err)
+	}
+	if team.OwnerID != nil {
+		count++
+	}
+	return count, nil
+}
+
+func (r *MemberRepo) UpdateRole(ctx context.Context, teamId string, userId string, role models.TeamRole) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.TeamMember{}).
+		Where(`"teamId" = ? AND "userId" = ?`, teamId, userId).
+		Updates(map[string]interface{}{
+			"role":      role,
+			"updatedAt": time.Now(),
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *MemberRepo) Delete(ctx context.Context, teamId string, userId string) error {
+
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ? AND "userId" = ?`, teamId, userId).
+		Delete(&models.TeamMember{}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
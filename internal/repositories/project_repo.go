@@ -18,6 +18,9 @@ type IProjectRepo interface {
 	Update(ctx context.Context, project *models.Project) (bool, error)
 	Delete(ctx context.Context, projectId string) error
 	GetWithTeam(ctx context.Context, projectId string) (*models.Project, error)
+	GetDeleted(ctx context.Context, projectId string) (*models.Project, error)
+	Restore(ctx context.Context, project *models.Project) error
+	CountByTeam(ctx context.Context, teamId string) (int64, error)
 }
 
 type ProjectRepo struct {
@@ -150,18 +153,81 @@ func (r *ProjectRepo) Update(ctx context.Context, project *models.Project) (bool
 	return true, nil
 }
 
+// Delete moves the project to the trash together with its active forms
 func (r *ProjectRepo) Delete(ctx context.Context, projectId string) error {
 
-	if err := r.db.WithContext(ctx).Model(&models.Project{}).
-		Where(&models.Project{
-			ID:    projectId,
-			Valid: true,
-		}).
-		Updates(map[string]interface{}{
-			"valid":     false,
-			"updatedAt": utils.GetCurrentTime(),
-		}).Error; err != nil {
+	deletedAt := trashTime()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Project{}).
+			Where(&models.Project{
+				ID:    projectId,
+				Valid: true,
+			}).
+			Updates(softDeleteUpdates(deletedAt))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return softDeleteForms(tx, "projectId", projectId, deletedAt)
+	})
+	if err != nil {
 		return errors.Internal(err)
 	}
 	return nil
 }
+
+// GetDeleted returns a project from the trash with its team
+func (r *ProjectRepo) GetDeleted(ctx context.Context, projectId string) (*models.Project, error) {
+
+	var project *models.Project
+	err := r.db.WithContext(ctx).
+		Preload("Team").
+		Where(`id = ? AND valid = false AND "deletedAt" IS NOT NULL`, projectId).
+		First(&project).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return project, nil
+}
+
+// Restore brings the project back with the forms that were deleted with it
+func (r *ProjectRepo) Restore(ctx context.Context, project *models.Project) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Project{}).
+			Where("id = ? AND valid = false", project.ID).
+			Updates(restoreUpdates()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Form{}).
+			Where(`"projectId" = ? AND valid = false AND "deletedAt" = ?`, project.ID, project.DeletedAt).
+			Updates(restoreUpdates()).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// CountByTeam counts the team's projects, leaving out those in the trash
+func (r *ProjectRepo) CountByTeam(ctx context.Context, teamId string) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Project{}).
+		Where(&models.Project{
+			TeamID: teamId,
+			Valid:  true,
+		}).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
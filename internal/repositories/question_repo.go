@@ -2,14 +2,19 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IQuestionRepo interface {
 	GetQuestions(ctx context.Context, formId string) ([]*models.Question, error)
+	GetQuestionById(ctx context.Context, formId string, questionId string) (*models.Question, error)
 	CreateQuestion(ctx context.Context, question *models.Question) (*models.Question, error)
 	UpdateQuestion(ctx context.Context, questionId string, question *map[string]interface{}) error
 	DeleteQuestion(ctx context.Context, questionId string) error
@@ -18,6 +23,13 @@ type IQuestionRepo interface {
 	CreateOption(ctx context.Context, option *models.QuestionOption) (*models.QuestionOption, error)
 	UpdateOption(ctx context.Context, optionId string, option *map[string]interface{}) error
 	DeleteOption(ctx context.Context, optionId string) error
+
+	CreateOptions(ctx context.Context, options []*models.QuestionOption) ([]*models.QuestionOption, error)
+	ReplaceOptions(ctx context.Context, questionId string, options []*models.QuestionOption) ([]*models.QuestionOption, error)
+	UpdateOptionSequence(ctx context.Context, questionId string, sequence []*types.SequenceItem) error
+
+	CopyQuestions(ctx context.Context, questions []*models.Question, options []*models.QuestionOption, edges []*models.Edge) error
+	MoveQuestions(ctx context.Context, targetFormId string, questions []*models.Question, dropEdgeIds []string) error
 }
 
 type QuestionRepo struct {
@@ -45,6 +57,22 @@ func (r *QuestionRepo) GetQuestions(ctx context.Context, formId string) ([]*mode
 	return questions, err
 }
 
+func (r *QuestionRepo) GetQuestionById(ctx context.Context, formId string, questionId string) (*models.Question, error) {
+
+	var question models.Question
+	err := r.db.WithContext(ctx).
+		Model(&models.Question{}).
+		Where(`id = ? AND "formId" = ?`, questionId, formId).
+		First(&question).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return &question, nil
+}
+
 func (r *QuestionRepo) CreateQuestion(ctx context.Context, question *models.Question) (*models.Question, error) {
 
 	err := r.db.WithContext(ctx).
@@ -86,6 +114,7 @@ func (r *QuestionRepo) GetOptions(ctx context.Context, questionId string) ([]*mo
 	err := r.db.WithContext(ctx).
 		Model(&models.QuestionOption{}).
 		Where(`"questionId" = ?`, questionId).
+		Order(`"sortOrder" ASC`).
 		Find(&options).Error
 	if err != nil {
 		return nil, errors.Internal(err)
@@ -128,3 +157,197 @@ func (r *QuestionRepo) DeleteOption(ctx context.Context, optionId string) error
 	}
 	return nil
 }
+
+func (r *QuestionRepo) CreateOptions(ctx context.Context, options []*models.QuestionOption) ([]*models.QuestionOption, error) {
+	if len(options) == 0 {
+		return options, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&models.QuestionOption{}).
+		CreateInBatches(options, 200).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return options, nil
+}
+
+// ReplaceOptions makes options the question's full option list in one transaction.
+// Options keeping an existing ID are updated in place, the rest are created, and the
+// existing options left out are deleted with their translations. Deleting an option
+// that a logic condition of the form still refers to is refused, so that no edge is
+// left pointing at nothing.
+func (r *QuestionRepo) ReplaceOptions(ctx context.Context, questionId string, options []*models.QuestionOption) ([]*models.QuestionOption, error) {
+
+	keep := make([]string, len(options))
+	for i, option := range options {
+		keep[i] = option.ID
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		removedQuery := tx.Model(&models.QuestionOption{}).Where(`"questionId" = ?`, questionId)
+		if len(keep) > 0 {
+			removedQuery = removedQuery.Where("id NOT IN ?", keep)
+		}
+		var removed []string
+		if err := removedQuery.Pluck("id", &removed).Error; err != nil {
+			return err
+		}
+
+		if len(removed) > 0 {
+			if err := checkOptionReferences(tx, questionId, removed); err != nil {
+				return err
+			}
+			err := tx.Where(`"resourceType" = ? AND "resourceId" IN ?`, models.TranslationResourceOption, removed).
+				Delete(&models.FormTranslation{}).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", removed).Delete(&models.QuestionOption{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"label", "value", "sortOrder", "score"}),
+		}).CreateInBatches(options, 200).Error
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return nil, appErr
+		}
+		return nil, errors.Internal(err)
+	}
+	return options, nil
+}
+
+// checkOptionReferences fails validation when an edge condition of the question's
+// form mentions one of the option IDs
+func checkOptionReferences(tx *gorm.DB, questionId string, optionIds []string) error {
+
+	formId := tx.Model(&models.Question{}).Select(`"formId"`).Where("id = ?", questionId)
+	var edges []*models.Edge
+	err := tx.Where(`"formId" = (?)`, formId).
+		Where(`condition IS NOT NULL AND condition::text <> '{}'`).
+		Find(&edges).Error
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool)
+	for _, edge := range edges {
+		raw, err := json.Marshal(edge.Condition)
+		if err != nil {
+			return err
+		}
+		for _, optionId := range optionIds {
+			if strings.Contains(string(raw), `"`+optionId+`"`) {
+				referenced[optionId] = true
+			}
+		}
+	}
+	if len(referenced) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(referenced))
+	for _, optionId := range optionIds {
+		if referenced[optionId] {
+			ids = append(ids, optionId)
+		}
+	}
+	return errors.ValidationFailed("Options used in logic conditions cannot be removed", map[string]interface{}{"optionIds": ids})
+}
+
+func (r *QuestionRepo) UpdateOptionSequence(ctx context.Context, questionId string, sequence []*types.SequenceItem) error {
+	if len(sequence) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(sequence))
+	caseStr := "CASE id "
+	args := make([]interface{}, 0, len(sequence)*2)
+	for i, item := range sequence {
+		ids[i] = item.ID
+		caseStr += "WHEN ? THEN ? "
+		args = append(args, item.ID, item.NewOrder)
+	}
+	caseStr += `ELSE "sortOrder" END`
+
+	err := r.db.WithContext(ctx).
+		Model(&models.QuestionOption{}).
+		Where(`id IN ? AND "questionId" = ?`, ids, questionId).
+		Update(`"sortOrder"`, gorm.Expr(caseStr, args...)).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// CopyQuestions inserts already re-keyed questions, options and edges in a single transaction
+func (r *QuestionRepo) CopyQuestions(ctx context.Context, questions []*models.Question, options []*models.QuestionOption, edges []*models.Edge) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).CreateInBatches(questions, 200).Error; err != nil {
+			return err
+		}
+		if len(options) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(options, 200).Error; err != nil {
+				return err
+			}
+		}
+		if len(edges) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(edges, 200).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// MoveQuestions re-parents questions and the edges between them to the target form.
+// Edges listed in dropEdgeIds would dangle after the move and are deleted.
+func (r *QuestionRepo) MoveQuestions(ctx context.Context, targetFormId string, questions []*models.Question, dropEdgeIds []string) error {
+
+	ids := make([]string, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(dropEdgeIds) > 0 {
+			if err := tx.Where("id IN ?", dropEdgeIds).Delete(&models.Edge{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, question := range questions {
+			err := tx.Model(&models.Question{}).
+				Where("id = ?", question.ID).
+				Updates(map[string]interface{}{
+					"formId":    targetFormId,
+					"sortOrder": question.SortOrder,
+					"posX":      question.PosX,
+					"posY":      question.PosY,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Edge{}).
+			Where(`"sourceNodeId" IN ? AND "targetNodeId" IN ?`, ids, ids).
+			Update("formId", targetFormId).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
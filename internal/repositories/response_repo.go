@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IResponseRepo interface {
+	Create(ctx context.Context, response *models.Response) (*models.Response, error)
+	GetById(ctx context.Context, responseId string) (*models.Response, error)
+	Submit(ctx context.Context, response *models.Response, started bool, maxResponses *int) error
+	CountByForm(ctx context.Context, formId string) (int64, error)
+	CountCompleted(ctx context.Context, formId string) (int64, error)
+	CountSubmittedByTeam(ctx context.Context, teamId string, since time.Time) (int64, error)
+}
+
+type ResponseRepo struct {
+	db *gorm.DB
+}
+
+func NewResponseRepo(db *gorm.DB) *ResponseRepo {
+	return &ResponseRepo{
+		db: db,
+	}
+}
+
+func (r *ResponseRepo) Create(ctx context.Context, response *models.Response) (*models.Response, error) {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Response{}).
+		Create(response).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return response, nil
+}
+
+func (r *ResponseRepo) GetById(ctx context.Context, responseId string) (*models.Response, error) {
+
+	var response models.Response
+	err := r.db.WithContext(ctx).
+		Where(`id = ? AND valid = ?`, responseId, true).
+		First(&response).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return &response, nil
+}
+
+// Submit stores a completed response, completing the started response with the same ID
+// when started is set and creating it otherwise. With maxResponses the form is locked
+// while its completed responses are counted, so that concurrent submissions cannot go
+// over the limit. A started response that was already submitted is a conflict.
+func (r *ResponseRepo) Submit(ctx context.Context, response *models.Response, started bool, maxResponses *int) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if maxResponses != nil {
+			var formIds []string
+			err := tx.Model(&models.Form{}).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", response.FormID).
+				Pluck("id", &formIds).Error
+			if err != nil {
+				return err
+			}
+
+			var count int64
+			err = tx.Model(&models.Response{}).
+				Where(`"formId" = ? AND valid = ? AND status = ?`, response.FormID, true, models.ResponseStatusCompleted).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count >= int64(*maxResponses) {
+				return errors.BadRequest("Form has reached its response limit")
+			}
+		}
+
+		if !started {
+			return tx.Omit(clause.Associations).Create(response).Error
+		}
+
+		result := tx.Model(&models.Response{}).
+			Where(`id = ? AND "formId" = ? AND status = ?`, response.ID, response.FormID, models.ResponseStatusStarted).
+			Updates(map[string]interface{}{
+				"data":        response.Data,
+				"metaData":    response.MetaData,
+				"variables":   response.Variables,
+				"status":      response.Status,
+				"submittedAt": response.SubmittedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.Conflict("Response has already been submitted")
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *ResponseRepo) CountByForm(ctx context.Context, formId string) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Response{}).
+		Where(`"formId" = ? AND valid = ?`, formId, true).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
+
+func (r *ResponseRepo) CountCompleted(ctx context.Context, formId string) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Response{}).
+		Where(`"formId" = ? AND valid = ? AND status = ?`, formId, true, models.ResponseStatusCompleted).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
+
+// CountSubmittedByTeam counts responses submitted to any of the team's forms since the
+// given time, including forms in the trash
+func (r *ResponseRepo) CountSubmittedByTeam(ctx context.Context, teamId string, since time.Time) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Response{}).
+		Joins(`JOIN forms ON forms.id = responses."formId"`).
+		Where(`forms."teamId" = ? AND responses.status = ? AND responses."submittedAt" >= ?`,
+			teamId, models.ResponseStatusCompleted, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type ISubscriptionRepo interface {
+	GetStatus(ctx context.Context, subscriptionId string) (models.TeamSubscriptionStatus, error)
+	GetSubscribedTeams(ctx context.Context) ([]string, error)
+	Update(ctx context.Context, subscriptionId string, updates map[string]interface{}) error
+	Roll(ctx context.Context, teamId string, subscription *models.TeamSubscription, start time.Time, end time.Time) (bool, error)
+	Cancel(ctx context.Context, teamId string, subscription *models.TeamSubscription, planId *string) (bool, error)
+}
+
+type SubscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepo(db *gorm.DB) *SubscriptionRepo {
+	return &SubscriptionRepo{
+		db: db,
+	}
+}
+
+// GetStatus returns the subscription's status, or an empty status when it does not exist
+func (r *SubscriptionRepo) GetStatus(ctx context.Context, subscriptionId string) (models.TeamSubscriptionStatus, error) {
+
+	var statuses []models.TeamSubscriptionStatus
+	err := r.db.WithContext(ctx).
+		Model(&models.TeamSubscription{}).
+		Where("id = ?", subscriptionId).
+		Limit(1).
+		Pluck("status", &statuses).Error
+	if err != nil {
+		return "", errors.Internal(err)
+	}
+	if len(statuses) == 0 {
+		return "", nil
+	}
+	return statuses[0], nil
+}
+
+// GetSubscribedTeams lists the active teams that have a subscription
+func (r *SubscriptionRepo) GetSubscribedTeams(ctx context.Context) ([]string, error) {
+
+	var teamIds []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Team{}).
+		Where(`valid = true AND "teamUsageId" IS NOT NULL`).
+		Pluck("id", &teamIds).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return teamIds, nil
+}
+
+func (r *SubscriptionRepo) Update(ctx context.Context, subscriptionId string, updates map[string]interface{}) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.TeamSubscription{}).
+		Where("id = ?", subscriptionId).
+		Updates(updates).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// Roll moves the subscription to its next cycle, resetting its counters and switching
+// the subscription and team to a scheduled downgrade. It reports false when the cycle
+// was already rolled, so that only one instance rolls it.
+func (r *SubscriptionRepo) Roll(ctx context.Context, teamId string, subscription *models.TeamSubscription, start time.Time, end time.Time) (bool, error) {
+
+	rolled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"cycleStart": start,
+			"cycleEnd":   end,
+			"responses":  0,
+			"overUsage":  0,
+		}
+		if subscription.NextPlanID != nil {
+			updates["planId"] = *subscription.NextPlanID
+			updates["nextPlanId"] = nil
+		}
+
+		result := tx.Model(&models.TeamSubscription{}).
+			Where(`id = ? AND "cycleEnd" = ?`, subscription.ID, subscription.CycleEnd).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		rolled = true
+
+		if subscription.NextPlanID == nil {
+			return nil
+		}
+		return tx.Model(&models.Team{}).
+			Where("id = ?", teamId).
+			Update("planId", *subscription.NextPlanID).Error
+	})
+	if err != nil {
+		return false, errors.Internal(err)
+	}
+	return rolled, nil
+}
+
+// Cancel detaches the subscription from the team and moves the team to the given plan.
+// It reports false when the subscription was already cancelled.
+func (r *SubscriptionRepo) Cancel(ctx context.Context, teamId string, subscription *models.TeamSubscription, planId *string) (bool, error) {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Team{}).
+		Where(`id = ? AND "teamUsageId" = ?`, teamId, subscription.ID).
+		Updates(map[string]interface{}{
+			"teamUsageId": nil,
+			"planId":      planId,
+		})
+	if result.Error != nil {
+		return false, errors.Internal(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
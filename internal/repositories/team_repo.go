@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
@@ -14,9 +15,12 @@ import (
 type ITeamRepo interface {
 	Get(ctx context.Context, userId string, pagination *types.PaginationQuery) ([]*types.TeamResponse, int64, error)
 	GetById(ctx context.Context, teamId string) (*models.Team, error)
+	GetWithPlan(ctx context.Context, teamId string) (*models.Team, error)
 	Create(ctx context.Context, team *models.Team) error
 	Update(ctx context.Context, team *models.Team) error
 	Delete(ctx context.Context, teamId string) error
+	GetDeleted(ctx context.Context, teamId string) (*models.Team, error)
+	Restore(ctx context.Context, team *models.Team) error
 }
 
 type TeamRepo struct {
@@ -37,10 +41,7 @@ func (r *TeamRepo) Get(ctx context.Context, userId string, pagination *types.Pag
 	baseQuery := r.db.
 		WithContext(ctx).
 		Model(&models.Team{}).
-		Where(&models.Team{
-			OwnerID: &userId,
-			Valid:   true,
-		})
+		Where(`teams.valid = true AND (teams."ownerId" = ? OR EXISTS (?))`, userId, r.membership(userId))
 
 	if pagination.Search != "" {
 		baseQuery = baseQuery.Where(
@@ -70,8 +71,14 @@ func (r *TeamRepo) Get(ctx context.Context, userId string, pagination *types.Pag
             FROM projects
             WHERE projects."teamId" = teams.id
             AND projects.valid = true
-        ) AS "projectCount"
-    `).
+        ) AS "projectCount",
+        CASE WHEN teams."ownerId" = @user THEN 'OWNER' ELSE (
+            SELECT team_members.role
+            FROM team_members
+            WHERE team_members."teamId" = teams.id
+            AND team_members."userId" = @user
+        ) END AS role
+    `, sql.Named("user", userId)).
 		Order(`teams."createdAt" DESC`).
 		Limit(pagination.Limit).
 		Offset((pagination.Page - 1) * pagination.Limit).
@@ -99,9 +106,49 @@ func (r *TeamRepo) GetById(ctx context.Context, teamId string) (*models.Team, er
 	return &team, nil
 }
 
+// GetWithPlan loads an active team with its plan, subscription and add-ons
+func (r *TeamRepo) GetWithPlan(ctx context.Context, teamId string) (*models.Team, error) {
+
+	var team *models.Team
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Preload("TeamUsage").
+		Preload("TeamAddons.AddOn").
+		Where(&models.Team{
+			ID:    teamId,
+			Valid: true,
+		}).
+		First(&team).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return team, nil
+}
+
+// membership matches the teams the user is a member of
+func (r *TeamRepo) membership(userId string) *gorm.DB {
+	return r.db.Model(&models.TeamMember{}).
+		Select("1").
+		Where(`team_members."teamId" = teams.id AND team_members."userId" = ?`, userId)
+}
+
+// Create inserts the team along with its owner's membership
 func (r *TeamRepo) Create(ctx context.Context, team *models.Team) error {
 
-	err := r.db.WithContext(ctx).Create(team).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(&models.TeamMember{
+			ID:     utils.GenerateUUID(),
+			TeamID: team.ID,
+			UserID: *team.OwnerID,
+			Role:   models.TeamRoleOwner,
+		}).Error
+	})
 	if err != nil {
 		return err
 	}
@@ -123,21 +170,81 @@ func (r *TeamRepo) Update(ctx context.Context, team *models.Team) error {
 	return nil
 }
 
+// Delete moves the team to the trash together with its active projects and forms.
+// They share one deletedAt so that restoring the team brings back only those.
 func (r *TeamRepo) Delete(ctx context.Context, teamId string) error {
 
-	err := r.db.WithContext(ctx).Model(&models.Team{}).
-		Where(&models.Team{
-			ID:    teamId,
-			Valid: true,
-		}).
-		Updates(map[string]interface{}{
-			"valid":     false,
-			"updatedAt": utils.GetCurrentTime(),
-		}).Error
+	deletedAt := trashTime()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Team{}).
+			Where(&models.Team{
+				ID:    teamId,
+				Valid: true,
+			}).
+			Updates(softDeleteUpdates(deletedAt))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		err := tx.Model(&models.Project{}).
+			Where(`"teamId" = ? AND valid = true`, teamId).
+			Updates(softDeleteUpdates(deletedAt)).Error
+		if err != nil {
+			return err
+		}
+		return softDeleteForms(tx, "teamId", teamId, deletedAt)
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
 
+// GetDeleted returns a team from the trash with its plan, subscription and add-ons, so
+// that its limits can be checked before it is restored
+func (r *TeamRepo) GetDeleted(ctx context.Context, teamId string) (*models.Team, error) {
+
+	var team *models.Team
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Preload("TeamUsage").
+		Preload("TeamAddons.AddOn").
+		Where(`id = ? AND valid = false AND "deletedAt" IS NOT NULL`, teamId).
+		First(&team).Error
 	if err != nil {
-		return err
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
 	}
+	return team, nil
+}
 
+// Restore brings the team back with the projects and forms that were deleted with it
+func (r *TeamRepo) Restore(ctx context.Context, team *models.Team) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Team{}).
+			Where("id = ? AND valid = false", team.ID).
+			Updates(restoreUpdates()).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Project{}).
+			Where(`"teamId" = ? AND valid = false AND "deletedAt" = ?`, team.ID, team.DeletedAt).
+			Updates(restoreUpdates()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Form{}).
+			Where(`"teamId" = ? AND valid = false AND "deletedAt" = ?`, team.ID, team.DeletedAt).
+			Updates(restoreUpdates()).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
 	return nil
 }
@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITransferRepo interface {
+	GetPending(ctx context.Context, teamId string) (*models.TeamOwnershipTransfer, error)
+	Create(ctx context.Context, transfer *models.TeamOwnershipTransfer) error
+	UpdatePending(ctx context.Context, transferId string, updates map[string]interface{}) (bool, error)
+	Accept(ctx context.Context, transfer *models.TeamOwnershipTransfer) error
+	SetOwner(ctx context.Context, team *models.Team, userId string) error
+}
+
+type TransferRepo struct {
+	db *gorm.DB
+}
+
+func NewTransferRepo(db *gorm.DB) *TransferRepo {
+	return &TransferRepo{
+		db: db,
+	}
+}
+
+// GetPending returns the team's pending transfer, which may have expired
+func (r *TransferRepo) GetPending(ctx context.Context, teamId string) (*models.TeamOwnershipTransfer, error) {
+
+	var transfer *models.TeamOwnershipTransfer
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ? AND status = ?`, teamId, models.OwnershipTransferStatusPending).
+		First(&transfer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Internal(err)
+	}
+	return transfer, nil
+}
+
+// Create inserts a pending transfer, first expiring a lapsed one so that it no longer
+// blocks the team
+func (r *TransferRepo) Create(ctx context.Context, transfer *models.TeamOwnershipTransfer) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.TeamOwnershipTransfer{}).
+			Where(`"teamId" = ? AND status = ? AND "expiresAt" <= ?`,
+				transfer.TeamID, models.OwnershipTransferStatusPending, time.Now()).
+			Update("status", models.OwnershipTransferStatusExpired).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(transfer).Error
+	})
+	if err != nil {
+		if errors.IsUniqueViolation(err) {
+			return errors.Conflict("This team already has a pending ownership transfer, cancel it first")
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *TransferRepo) UpdatePending(ctx context.Context, transferId string, updates map[string]interface{}) (bool, error) {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.TeamOwnershipTransfer{}).
+		Where("id = ? AND status = ?", transferId, models.OwnershipTransferStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		return false, errors.Internal(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Accept completes a pending transfer, provided the team still has the owner who
+// offered it
+func (r *TransferRepo) Accept(ctx context.Context, transfer *models.TeamOwnershipTransfer) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.TeamOwnershipTransfer{}).
+			Where(`id = ? AND status = ? AND "expiresAt" > ?`, transfer.ID, models.OwnershipTransferStatusPending, now).
+			Updates(map[string]interface{}{
+				"status":      models.OwnershipTransferStatusAccepted,
+				"respondedAt": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.Conflict("This ownership transfer is no longer valid")
+		}
+		return setOwner(tx, transfer.TeamID, &transfer.FromUserID, transfer.ToUserID)
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// SetOwner hands the team to the user straight away, cancelling any pending transfer
+func (r *TransferRepo) SetOwner(ctx context.Context, team *models.Team, userId string) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.TeamOwnershipTransfer{}).
+			Where(`"teamId" = ? AND status = ?`, team.ID, models.OwnershipTransferStatusPending).
+			Updates(map[string]interface{}{
+				"status":      models.OwnershipTransferStatusCancelled,
+				"respondedAt": time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return setOwner(tx, team.ID, team.OwnerID, userId)
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// setOwner moves OwnerID from the expected owner to the user and keeps the memberships
+// in step: the new owner's row becomes OWNER and the previous owner stays on as ADMIN.
+// Either row is created when missing, as owners predating memberships have none.
+func setOwner(tx *gorm.DB, teamId string, from *string, to string) error {
+
+	query := tx.Model(&models.Team{}).Where("id = ? AND valid = true", teamId)
+	if from != nil {
+		query = query.Where(`"ownerId" = ?`, *from)
+	} else {
+		query = query.Where(`"ownerId" IS NULL`)
+	}
+	result := query.Updates(map[string]interface{}{
+		"ownerId":   to,
+		"updatedAt": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("The team's owner has changed")
+	}
+
+	if err := upsertMember(tx, teamId, to, models.TeamRoleOwner); err != nil {
+		return err
+	}
+	if from != nil && *from != to {
+		return upsertMember(tx, teamId, *from, models.TeamRoleAdmin)
+	}
+	return nil
+}
+
+func upsertMember(tx *gorm.DB, teamId string, userId string, role models.TeamRole) error {
+	return tx.Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "teamId"}, {Name: "userId"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"role": role, "updatedAt": time.Now()}),
+		}).
+		Create(&models.TeamMember{
+			ID:     utils.GenerateUUID(),
+			TeamID: teamId,
+			UserID: userId,
+			Role:   role,
+		}).Error
+}
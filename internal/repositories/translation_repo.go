@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITranslationRepo interface {
+	GetLocales(ctx context.Context, formId string) ([]*models.FormLocale, error)
+	CreateLocale(ctx context.Context, locale *models.FormLocale) (*models.FormLocale, error)
+	DeleteLocale(ctx context.Context, formId string, locale string) error
+	SetDefaultLocale(ctx context.Context, formId string, locale string) error
+
+	GetTranslations(ctx context.Context, formId string, locale string) ([]*models.FormTranslation, error)
+	UpsertTranslations(ctx context.Context, translations []*models.FormTranslation) error
+	DeleteTranslation(ctx context.Context, translation *models.FormTranslation) error
+}
+
+type TranslationRepo struct {
+	db *gorm.DB
+}
+
+func NewTranslationRepo(db *gorm.DB) *TranslationRepo {
+	return &TranslationRepo{
+		db: db,
+	}
+}
+
+func (r *TranslationRepo) GetLocales(ctx context.Context, formId string) ([]*models.FormLocale, error) {
+
+	var locales []*models.FormLocale
+	err := r.db.WithContext(ctx).
+		Where(`"formId" = ?`, formId).
+		Order(`"isDefault" DESC, locale ASC`).
+		Find(&locales).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return locales, nil
+}
+
+func (r *TranslationRepo) CreateLocale(ctx context.Context, locale *models.FormLocale) (*models.FormLocale, error) {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if locale.IsDefault {
+			err := tx.Model(&models.FormLocale{}).
+				Where(`"formId" = ?`, locale.FormID).
+				Update("isDefault", false).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Omit(clause.Associations).Create(locale).Error
+	})
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return locale, nil
+}
+
+// DeleteLocale removes the locale and every translation stored for it
+func (r *TranslationRepo) DeleteLocale(ctx context.Context, formId string, locale string) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(`"formId" = ? AND locale = ?`, formId, locale).
+			Delete(&models.FormTranslation{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where(`"formId" = ? AND locale = ?`, formId, locale).
+			Delete(&models.FormLocale{}).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *TranslationRepo) SetDefaultLocale(ctx context.Context, formId string, locale string) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.FormLocale{}).
+		Where(`"formId" = ?`, formId).
+		Update("isDefault", gorm.Expr("locale = ?", locale)).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// GetTranslations returns the translations of a form, limited to one locale when locale is not empty
+func (r *TranslationRepo) GetTranslations(ctx context.Context, formId string, locale string) ([]*models.FormTranslation, error) {
+
+	query := r.db.WithContext(ctx).
+		Where(`"formId" = ?`, formId)
+	if locale != "" {
+		query = query.Where("locale = ?", locale)
+	}
+
+	var translations []*models.FormTranslation
+	if err := query.Find(&translations).Error; err != nil {
+		return nil, errors.Internal(err)
+	}
+	return translations, nil
+}
+
+func (r *TranslationRepo) UpsertTranslations(ctx context.Context, translations []*models.FormTranslation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "formId"}, {Name: "locale"}, {Name: "resourceType"}, {Name: "resourceId"}, {Name: "field"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updatedAt"}),
+		}).
+		CreateInBatches(translations, 200).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *TranslationRepo) DeleteTranslation(ctx context.Context, translation *models.FormTranslation) error {
+
+	err := r.db.WithContext(ctx).
+		Where(`"formId" = ? AND locale = ? AND "resourceType" = ? AND "resourceId" = ? AND field = ?`,
+			translation.FormID, translation.Locale, translation.ResourceType, translation.ResourceID, translation.Field).
+		Delete(&models.FormTranslation{}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type ITrashRepo interface {
+	GetDeletedTeams(ctx context.Context, userId string) ([]*models.Team, error)
+	GetDeletedProjects(ctx context.Context, teamId string) ([]*models.Project, error)
+	GetDeletedForms(ctx context.Context, teamId string) ([]*models.Form, error)
+	CountDeletedProjects(ctx context.Context, teamId string, deletedAt time.Time) (int64, error)
+	CountDeletedForms(ctx context.Context, column string, value string, deletedAt time.Time) (int64, error)
+	GetFormsDeletedWith(ctx context.Context, column string, value string, deletedAt time.Time) ([]*models.Form, error)
+
+	GetExpiredForms(ctx context.Context, before time.Time, limit int) ([]string, error)
+	PurgeForm(ctx context.Context, formId string) error
+	PurgeProjects(ctx context.Context, before time.Time) (int64, error)
+	PurgeTeams(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TrashRepo struct {
+	db *gorm.DB
+}
+
+func NewTrashRepo(db *gorm.DB) *TrashRepo {
+	return &TrashRepo{
+		db: db,
+	}
+}
+
+// trashTime is the deletedAt stamped on an item and everything deleted with it. It is
+// truncated to the column precision so that restore can match it exactly.
+func trashTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func softDeleteUpdates(deletedAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"valid":     false,
+		"deletedAt": deletedAt,
+		"updatedAt": deletedAt,
+	}
+}
+
+func restoreUpdates() map[string]interface{} {
+	return map[string]interface{}{
+		"valid":     true,
+		"deletedAt": nil,
+		"updatedAt": time.Now().UTC(),
+	}
+}
+
+// softDeleteForms trashes the active forms matching column = value and cancels their
+// pending scheduled jobs, so nothing publishes or closes a form while it is in the trash
+func softDeleteForms(tx *gorm.DB, column string, value string, deletedAt time.Time) error {
+
+	err := tx.Model(&models.Form{}).
+		Where(map[string]interface{}{column: value, "valid": true}).
+		Updates(softDeleteUpdates(deletedAt)).Error
+	if err != nil {
+		return err
+	}
+
+	deleted := tx.Model(&models.Form{}).
+		Select("id").
+		Where(map[string]interface{}{column: value, "deletedAt": deletedAt})
+	return tx.Model(&models.ScheduledJob{}).
+		Where(`status = ? AND "formId" IN (?)`, models.ScheduledJobStatusPending, deleted).
+		Updates(map[string]interface{}{
+			"status":     models.ScheduledJobStatusCancelled,
+			"finishedAt": deletedAt,
+		}).Error
+}
+
+// CountDeletedProjects counts the team's projects deleted along with it, which restoring
+// the team brings back
+func (r *TrashRepo) CountDeletedProjects(ctx context.Context, teamId string, deletedAt time.Time) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Project{}).
+		Where(`"teamId" = ? AND valid = false AND "deletedAt" = ?`, teamId, deletedAt).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
+
+// CountDeletedForms counts the forms matching column = value that were deleted along
+// with their team or project, which restoring it brings back
+func (r *TrashRepo) CountDeletedForms(ctx context.Context, column string, value string, deletedAt time.Time) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Form{}).
+		Where(map[string]interface{}{column: value, "valid": false, "deletedAt": deletedAt}).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return count, nil
+}
+
+// GetFormsDeletedWith lists the forms matching column = value that were deleted along
+// with their team or project
+func (r *TrashRepo) GetFormsDeletedWith(ctx context.Context, column string, value string, deletedAt time.Time) ([]*models.Form, error) {
+
+	var forms []*models.Form
+	err := r.db.WithContext(ctx).
+		Where(map[string]interface{}{column: value, "valid": false, "deletedAt": deletedAt}).
+		Find(&forms).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return forms, nil
+}
+
+func (r *TrashRepo) GetDeletedTeams(ctx context.Context, userId string) ([]*models.Team, error) {
+
+	var teams []*models.Team
+	err := r.db.WithContext(ctx).
+		Where(`"ownerId" = ? AND valid = false AND "deletedAt" IS NOT NULL`, userId).
+		Order(`"deletedAt" DESC`).
+		Find(&teams).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return teams, nil
+}
+
+func (r *TrashRepo) GetDeletedProjects(ctx context.Context, teamId string) ([]*models.Project, error) {
+
+	var projects []*models.Project
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ? AND valid = false AND "deletedAt" IS NOT NULL`, teamId).
+		Order(`"deletedAt" DESC`).
+		Find(&projects).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return projects, nil
+}
+
+// GetDeletedForms lists the team's trashed forms, leaving out those deleted with their
+// project since they come back when the project is restored
+func (r *TrashRepo) GetDeletedForms(ctx context.Context, teamId string) ([]*models.Form, error) {
+
+	withProject := r.db.Model(&models.Project{}).
+		Select("1").
+		Where(`projects.id = forms."projectId" AND projects.valid = false AND projects."deletedAt" = forms."deletedAt"`)
+
+	var forms []*models.Form
+	err := r.db.WithContext(ctx).
+		Where(`"teamId" = ? AND valid = false AND "deletedAt" IS NOT NULL AND NOT EXISTS (?)`, teamId, withProject).
+		Order(`"deletedAt" DESC`).
+		Find(&forms).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return forms, nil
+}
+
+// GetExpiredForms returns the IDs of forms due for purging: trashed before the cutoff
+// themselves, or belonging to a project or team that was
+func (r *TrashRepo) GetExpiredForms(ctx context.Context, before time.Time, limit int) ([]string, error) {
+
+	expiredProjects := r.db.Model(&models.Project{}).
+		Select("id").
+		Where(`valid = false AND "deletedAt" < ?`, before)
+	expiredTeams := r.db.Model(&models.Team{}).
+		Select("id").
+		Where(`valid = false AND "deletedAt" < ?`, before)
+
+	var formIds []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Form{}).
+		Where(`(valid = false AND "deletedAt" < ?) OR "projectId" IN (?) OR "teamId" IN (?)`, before, expiredProjects, expiredTeams).
+		Order(`"deletedAt"`).
+		Limit(limit).
+		Pluck("id", &formIds).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return formIds, nil
+}
+
+// PurgeForm permanently deletes a form and every row that belongs to it, children
+// first, in one transaction. Nothing is left to ON DELETE CASCADE, which the older
+// tables do not declare.
+func (r *TrashRepo) PurgeForm(ctx context.Context, formId string) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		questions := tx.Model(&models.Question{}).
+			Select("id").
+			Where(`"formId" = ?`, formId)
+		if err := tx.Where(`"questionId" IN (?)`, questions).Delete(&models.QuestionOption{}).Error; err != nil {
+			return err
+		}
+
+		// Jobs and transitions refer to versions, and versions to the form, so they go
+		// before the versions, which go after the form stops pointing at one of them
+		children := []interface{}{
+			&models.ScheduledJob{},
+			&models.FormStatusTransition{},
+			&models.Response{},
+			&models.Edge{},
+			&models.Question{},
+			&models.FormTranslation{},
+			&models.FormLocale{},
+			&models.SlugRedirect{},
+			&models.CustomDomain{},
+			&models.ActivePassword{},
+		}
+		for _, child := range children {
+			if err := tx.Where(`"formId" = ?`, formId).Delete(child).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&models.Form{}).
+			Where("id = ?", formId).
+			Update("publishedVersionId", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where(`"formId" = ?`, formId).Delete(&models.FormVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", formId).Delete(&models.Form{}).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// PurgeProjects permanently deletes expired projects whose forms are already purged
+func (r *TrashRepo) PurgeProjects(ctx context.Context, before time.Time) (int64, error) {
+
+	forms := r.db.Model(&models.Form{}).
+		Select("1").
+		Where(`forms."projectId" = projects.id`)
+
+	result := r.db.WithContext(ctx).
+		Where(`valid = false AND "deletedAt" < ? AND NOT EXISTS (?)`, before, forms).
+		Delete(&models.Project{})
+	if result.Error != nil {
+		return 0, errors.Internal(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeTeams permanently deletes expired teams whose projects and forms are already purged
+func (r *TrashRepo) PurgeTeams(ctx context.Context, before time.Time) (int64, error) {
+
+	forms := r.db.Model(&models.Form{}).
+		Select("1").
+		Where(`forms."teamId" = teams.id`)
+	projects := r.db.Model(&models.Project{}).
+		Select("1").
+		Where(`projects."teamId" = teams.id`)
+
+	result := r.db.WithContext(ctx).
+		Where(`valid = false AND "deletedAt" < ? AND NOT EXISTS (?) AND NOT EXISTS (?)`, before, forms, projects).
+		Delete(&models.Team{})
+	if result.Error != nil {
+		return 0, errors.Internal(result.Error)
+	}
+	return result.RowsAffected, nil
+}
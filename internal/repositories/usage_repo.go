@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IUsageRepo interface {
+	CreateEvent(ctx context.Context, event *models.UsageEvents) error
+	CreateAIUsage(ctx context.Context, aiEvent *models.AiUsageEvents, event *models.UsageEvents) error
+	GetTeamsToAggregate(ctx context.Context, since time.Time) ([]string, error)
+	SumEvents(ctx context.Context, teamId string, from time.Time, to time.Time) (map[models.UsageEventType]int64, error)
+	SumStorage(ctx context.Context, teamId string) (int64, error)
+	SumFormStorage(ctx context.Context, formId string) (int64, error)
+	ReleaseFormStorage(ctx context.Context, formId string) error
+	SaveRecord(ctx context.Context, record *models.UsageRecord) error
+	UpdateSubscription(ctx context.Context, subscriptionId string, responses int, overUsage int) error
+}
+
+type UsageRepo struct {
+	db *gorm.DB
+}
+
+func NewUsageRepo(db *gorm.DB) *UsageRepo {
+	return &UsageRepo{
+		db: db,
+	}
+}
+
+func (r *UsageRepo) CreateEvent(ctx context.Context, event *models.UsageEvents) error {
+
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// CreateAIUsage stores an AI call together with the usage event counting its tokens
+func (r *UsageRepo) CreateAIUsage(ctx context.Context, aiEvent *models.AiUsageEvents, event *models.UsageEvents) error {
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(aiEvent).Error; err != nil {
+			return err
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// GetTeamsToAggregate lists the teams with usage events since the given time that are
+// newer than the team's latest usage record. The state lives in the records rather than
+// in memory, so every instance sees the same teams.
+func (r *UsageRepo) GetTeamsToAggregate(ctx context.Context, since time.Time) ([]string, error) {
+
+	recorded := r.db.Model(&models.UsageRecord{}).
+		Select(`MAX("recordedAt")`).
+		Where(`usage_records."teamId" = usage_events."teamId"`)
+
+	var teamIds []string
+	err := r.db.WithContext(ctx).
+		Model(&models.UsageEvents{}).
+		Distinct(`"teamId"`).
+		Where(`"createdAt" >= ? AND "createdAt" >= COALESCE((?), '-infinity')`, since, recorded).
+		Pluck(`"teamId"`, &teamIds).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	return teamIds, nil
+}
+
+// SumEvents totals the quantities of the team's usage events in [from, to) by type
+func (r *UsageRepo) SumEvents(ctx context.Context, teamId string, from time.Time, to time.Time) (map[models.UsageEventType]int64, error) {
+
+	var rows []struct {
+		Type  models.UsageEventType
+		Total int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.UsageEvents{}).
+		Select(`type, COALESCE(SUM(quantity), 0) AS total`).
+		Where(`"teamId" = ? AND "createdAt" >= ? AND "createdAt" < ?`, teamId, from, to).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	totals := make(map[models.UsageEventType]int64, len(rows))
+	for _, row := range rows {
+		totals[row.Type] = row.Total
+	}
+	return totals, nil
+}
+
+// SumStorage is the team's metered storage: the bytes of its uploads less those of
+// deleted files and purged forms
+func (r *UsageRepo) SumStorage(ctx context.Context, teamId string) (int64, error) {
+
+	var total int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UsageEvents{}).
+		Select(`COALESCE(SUM(quantity), 0)`).
+		Where(`"teamId" = ? AND type = ?`, teamId, models.UsageEventStorageBytes).
+		Scan(&total).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return total, nil
+}
+
+// SumFormStorage is the storage metered for the uploads of a form
+func (r *UsageRepo) SumFormStorage(ctx context.Context, formId string) (int64, error) {
+
+	var total int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UsageEvents{}).
+		Select(`COALESCE(SUM(quantity), 0)`).
+		Where(`"refId" = ? AND type = ?`, formId, models.UsageEventStorageBytes).
+		Scan(&total).Error
+	if err != nil {
+		return 0, errors.Internal(err)
+	}
+	return total, nil
+}
+
+// ReleaseFormStorage records the negative of the storage still counted for a form, once
+// per team it is counted against. Nothing is recorded for a form already released, so
+// calling it again after a failed purge is harmless.
+func (r *UsageRepo) ReleaseFormStorage(ctx context.Context, formId string) error {
+
+	err := r.db.WithContext(ctx).Exec(`
+		INSERT INTO usage_events (id, "teamId", type, quantity, "refId", "createdAt")
+		SELECT uuid_generate_v4(), "teamId", type, -SUM(quantity), "refId", now()
+		FROM usage_events
+		WHERE type = ? AND "refId" = ?
+		GROUP BY "teamId", type, "refId"
+		HAVING SUM(quantity) <> 0`,
+		models.UsageEventStorageBytes, formId,
+	).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+// SaveRecord creates or replaces the team's usage record for the record's cycle
+func (r *UsageRepo) SaveRecord(ctx context.Context, record *models.UsageRecord) error {
+
+	err := r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "teamId"}, {Name: "cycleStart"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"formsUsed", "projectsUsed", "responsesUsed", "storageUsed", "tokensUsed", "recordedAt",
+			}),
+		}).
+		Create(record).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func (r *UsageRepo) UpdateSubscription(ctx context.Context, subscriptionId string, responses int, overUsage int) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.TeamSubscription{}).
+		Where("id = ?", subscriptionId).
+		Updates(map[string]interface{}{
+			"responses": responses,
+			"overUsage": overUsage,
+		}).Error
+	if err != nil {
+		return errors.Internal(err)
+	}
+	return nil
+}
@@ -1,17 +1,21 @@
 package routes
 
 import (
+	"github.com/HarshKanjiya/escape-form-api/internal/auth"
 	"github.com/HarshKanjiya/escape-form-api/internal/config"
 	"github.com/HarshKanjiya/escape-form-api/internal/controllers"
 	"github.com/HarshKanjiya/escape-form-api/internal/database"
+	"github.com/HarshKanjiya/escape-form-api/internal/dns"
+	"github.com/HarshKanjiya/escape-form-api/internal/mailer"
 	"github.com/HarshKanjiya/escape-form-api/internal/middlewares"
 	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
 	"github.com/HarshKanjiya/escape-form-api/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
 
-// SetupRoutes configures all application routes
-func SetupRoutes(app *fiber.App, cfg *config.Config) {
+// SetupRoutes configures all application routes and returns the job scheduler, which
+// shares their services
+func SetupRoutes(app *fiber.App, cfg *config.Config, authenticator auth.Authenticator, mail mailer.Mailer) *services.Scheduler {
 
 	// Initialize repositories
 	teamRepo := repositories.NewTeamRepo(database.DB)
@@ -21,16 +25,39 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	questionRepo := repositories.NewQuestionRepo(database.DB)
 	edgeRepo := repositories.NewEdgeRepo(database.DB)
 	dashRepo := repositories.NewDashRepo(database.DB)
+	responseRepo := repositories.NewResponseRepo(database.DB)
+	translationRepo := repositories.NewTranslationRepo(database.DB)
+	domainRepo := repositories.NewDomainRepo(database.DB)
+	jobRepo := repositories.NewJobRepo(database.DB)
+	trashRepo := repositories.NewTrashRepo(database.DB)
+	memberRepo := repositories.NewMemberRepo(database.DB)
+	invitationRepo := repositories.NewInvitationRepo(database.DB)
+	apiKeyRepo := repositories.NewAPIKeyRepo(database.DB)
+	auditRepo := repositories.NewAuditRepo(database.DB)
+	transferRepo := repositories.NewTransferRepo(database.DB)
+	usageRepo := repositories.NewUsageRepo(database.DB)
+	subscriptionRepo := repositories.NewSubscriptionRepo(database.DB)
 
 	// Initialize services
-	teamService := services.NewTeamService(teamRepo)
-	projectService := services.NewProjectService(projectRepo, teamRepo)
-	formService := services.NewFormService(formRepo, projectRepo, formVersionRepo, questionRepo, edgeRepo)
-	questionService := services.NewQuestionService(questionRepo, formRepo)
-	edgeService := services.NewEdgeService(edgeRepo, formRepo)
-	dashService := services.NewDashService(dashRepo, formRepo)
-	submissionService := services.NewSubmissionService(formRepo, formVersionRepo)
-	uploadService := services.NewUploadService(cfg)
+	permissionService := services.NewPermissionService(memberRepo, teamRepo, subscriptionRepo, cfg)
+	auditService := services.NewAuditService(auditRepo, permissionService, cfg)
+	entitlementService := services.NewEntitlementService(teamRepo, projectRepo, formRepo, memberRepo, invitationRepo, responseRepo, usageRepo, cfg)
+	usageService := services.NewUsageService(usageRepo, teamRepo, permissionService, entitlementService, cfg)
+	billingService := services.NewBillingService(subscriptionRepo, teamRepo, permissionService, auditService, entitlementService, cfg)
+	teamService := services.NewTeamService(teamRepo, memberRepo, transferRepo, permissionService, auditService, cfg)
+	projectService := services.NewProjectService(projectRepo, teamRepo, permissionService, auditService, entitlementService, usageService)
+	formService := services.NewFormService(formRepo, projectRepo, teamRepo, formVersionRepo, questionRepo, edgeRepo, translationRepo, jobRepo, permissionService, auditService, entitlementService, usageService)
+	questionService := services.NewQuestionService(questionRepo, formRepo, edgeRepo, permissionService, auditService)
+	edgeService := services.NewEdgeService(edgeRepo, formRepo, permissionService, auditService)
+	dashService := services.NewDashService(dashRepo, formRepo, jobRepo, permissionService, auditService)
+	submissionService := services.NewSubmissionService(formRepo, formVersionRepo, responseRepo, dashRepo, jobRepo, entitlementService, usageService, cfg)
+	uploadService := services.NewUploadService(formRepo, permissionService, auditService, entitlementService, usageService, cfg)
+	translationService := services.NewTranslationService(translationRepo, formRepo, questionRepo, permissionService, auditService)
+	prefillService := services.NewPrefillService(formRepo, formVersionRepo, permissionService, cfg)
+	domainService := services.NewDomainService(domainRepo, formRepo, dns.NewResolver(cfg), permissionService, auditService, cfg)
+	trashService := services.NewTrashService(trashRepo, teamRepo, projectRepo, formRepo, jobRepo, permissionService, auditService, entitlementService, usageService, cfg)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, permissionService, auditService)
+	invitationService := services.NewInvitationService(invitationRepo, permissionService, auditService, entitlementService, mail, cfg)
 
 	// Initialize controllers
 	teamController := controllers.NewTeamController(teamService)
@@ -41,20 +68,71 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	dashController := controllers.NewDashController(dashService)
 	uploadController := controllers.NewUploadController(uploadService)
 	submissionController := controllers.NewSubmissionController(submissionService)
+	translationController := controllers.NewTranslationController(translationService)
+	prefillController := controllers.NewPrefillController(prefillService)
+	domainController := controllers.NewDomainController(domainService)
+	trashController := controllers.NewTrashController(trashService)
+	invitationController := controllers.NewInvitationController(invitationService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	auditController := controllers.NewAuditController(auditService)
+	usageController := controllers.NewUsageController(usageService)
+	billingController := controllers.NewBillingController(billingService)
 
 	// API v1 routes
 	api := app.Group("/api/v1")
 
 	api.Get("/health", controllers.HealthCheck)
 
-	protectedRoutes := api.Group("/", middlewares.ClerkAuth())
+	// Protected routes take a session from the configured provider or an API key; routes
+	// that are not scoped to a single team are for users only
+	protectedRoutes := api.Group("/", middlewares.Authenticate(authenticator, auth.NewAPIKeyAuthenticator(apiKeyService)))
+	userOnly := middlewares.UserOnly()
 
 	teams := protectedRoutes.Group("/teams")
 	{
-		teams.Get("/", teamController.Get)
-		teams.Post("/", teamController.Create)
+		teams.Get("/", userOnly, teamController.Get)
+		teams.Post("/", userOnly, teamController.Create)
+		teams.Get("/trash", userOnly, trashController.GetDeletedTeams)
 		teams.Patch("/:id", teamController.Update)
 		teams.Delete("/:id", teamController.Delete)
+		teams.Get("/:id/trash", trashController.GetTeamTrash)
+		teams.Post("/:id/restore", trashController.RestoreTeam)
+
+		teams.Get("/:id/members", teamController.GetMembers)
+		teams.Patch("/:id/members/:userId", teamController.UpdateMemberRole)
+		teams.Delete("/:id/members/:userId", teamController.RemoveMember)
+		teams.Post("/:id/leave", userOnly, teamController.Leave)
+
+		teams.Get("/:id/ownership-transfer", teamController.GetOwnershipTransfer)
+		teams.Post("/:id/ownership-transfer", userOnly, teamController.TransferOwnership)
+		teams.Delete("/:id/ownership-transfer", userOnly, teamController.CancelOwnershipTransfer)
+		teams.Post("/:id/ownership-transfer/accept", userOnly, teamController.AcceptOwnershipTransfer)
+		teams.Post("/:id/ownership-transfer/decline", userOnly, teamController.DeclineOwnershipTransfer)
+
+		teams.Get("/:id/invitations", invitationController.List)
+		teams.Post("/:id/invitations", invitationController.Create)
+		teams.Delete("/:id/invitations/:invitationId", invitationController.Revoke)
+		teams.Post("/:id/invitations/:invitationId/resend", invitationController.Resend)
+
+		teams.Get("/:id/api-keys", userOnly, apiKeyController.List)
+		teams.Post("/:id/api-keys", userOnly, apiKeyController.Create)
+		teams.Delete("/:id/api-keys/:keyId", userOnly, apiKeyController.Revoke)
+
+		teams.Get("/:id/audit", auditController.List)
+		teams.Get("/:id/usage", usageController.Get)
+	}
+
+	// Support staff, listed in SUPPORT_ADMIN_IDS, acting outside the teams' own roles
+	admin := protectedRoutes.Group("/admin", userOnly)
+	{
+		admin.Put("/teams/:id/owner", teamController.OverrideOwner)
+		admin.Post("/teams/:id/payment-failed", billingController.PaymentFailed)
+		admin.Post("/teams/:id/payment-recovered", billingController.PaymentRecovered)
+	}
+
+	invitations := protectedRoutes.Group("/invitations")
+	{
+		invitations.Post("/accept", userOnly, invitationController.Accept)
 	}
 
 	projects := protectedRoutes.Group("/projects")
@@ -64,6 +142,7 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 		projects.Post("/", projectController.Create)
 		projects.Patch("/:id", projectController.Update)
 		projects.Delete("/:id", projectController.Delete)
+		projects.Post("/:id/restore", trashController.RestoreProject)
 	}
 
 	forms := protectedRoutes.Group("/forms")
@@ -73,22 +152,43 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 		forms.Get("/:id", formController.GetById)
 		forms.Post("/:id/status", formController.UpdateStatus)
 		forms.Delete("/:id", formController.Delete)
+		forms.Post("/:id/restore", trashController.RestoreForm)
+		forms.Post("/:formId/move", formController.Move)
 
 		forms.Post("/:formId/publish", formController.Publish)
 		forms.Post("/:formId/unpublish", formController.Unpublish)
+		forms.Get("/:formId/status/history", formController.GetStatusHistory)
+		forms.Get("/:formId/schedule", formController.GetScheduledJobs)
+		forms.Post("/:formId/schedule/publish", formController.SchedulePublish)
+		forms.Delete("/:formId/schedule/:jobId", formController.CancelScheduledJob)
 
 		forms.Post("/:formId/sequence", formController.UpdateSequence)
+		forms.Put("/:formId/variables", formController.UpdateVariables)
+		forms.Put("/:formId/quiz", formController.UpdateQuiz)
+		forms.Put("/:formId/hidden-fields", formController.UpdateHiddenFields)
+		forms.Post("/:formId/prefill", prefillController.CreateToken)
+		forms.Get("/:formId/slug/availability", formController.CheckSlug)
+		forms.Put("/:formId/slug", formController.UpdateSlug)
+
+		forms.Get("/:formId/domain", domainController.Get)
+		forms.Put("/:formId/domain", domainController.Set)
+		forms.Post("/:formId/domain/verify", domainController.Verify)
+		forms.Delete("/:formId/domain", domainController.Delete)
 	}
 
 	questions := forms.Group("/:formId/questions")
 	{
 		questions.Get("/", questionController.GetQuestions)
 		questions.Post("/", questionController.CreateQuestion)
+		questions.Post("/transfer", questionController.TransferQuestions)
 		questions.Patch("/:questionId", questionController.UpdateQuestion)
 		questions.Delete("/:questionId", questionController.DeleteQuestion)
 
 		questions.Get("/:questionId/options", questionController.GetOptions)
 		questions.Post("/:questionId/options", questionController.CreateOption)
+		questions.Put("/:questionId/options", questionController.ReplaceOptions)
+		questions.Post("/:questionId/options/bulk", questionController.BulkCreateOptions)
+		questions.Post("/:questionId/options/sequence", questionController.UpdateOptionSequence)
 		questions.Patch("/:questionId/options/:optionId", questionController.UpdateOption)
 		questions.Delete("/:questionId/options/:optionId", questionController.DeleteOption)
 	}
@@ -101,13 +201,32 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 		edges.Delete("/:id", edgeController.Delete)
 	}
 
+	locales := forms.Group("/:formId/locales")
+	{
+		locales.Get("/", translationController.GetLocales)
+		locales.Post("/", translationController.CreateLocale)
+		locales.Delete("/:locale", translationController.DeleteLocale)
+		locales.Post("/:locale/default", translationController.SetDefaultLocale)
+	}
+
+	translations := forms.Group("/:formId/translations")
+	{
+		translations.Get("/", translationController.GetTranslations)
+		translations.Put("/:locale", translationController.UpsertTranslations)
+	}
+
 	dash := protectedRoutes.Group("/dashboard")
 	{
 		dash.Get("/:formId/analytics", dashController.GetAnalytics)
 		dash.Get("/:formId/questions", dashController.GetQuestions)
 		dash.Get("/:formId/responses", dashController.GetResponses)
+		dash.Get("/:formId/responses/export", dashController.ExportResponses)
+		dash.Patch("/:formId/responses/:responseId", dashController.UpdateResponse)
+		dash.Delete("/:formId/responses/:responseId", dashController.DeleteResponse)
+		dash.Get("/:formId/quiz", dashController.GetQuizReport)
 		dash.Put("/:formId/security", dashController.UpdateSecurity)
 		dash.Put("/:formId/settings", dashController.UpdateSettings)
+		dash.Get("/:formId/translations", translationController.GetCompleteness)
 
 		dash.Get("/:formId/passwords", dashController.GetPasswords)
 		dash.Post("/:formId/passwords", dashController.CreatePasswords)
@@ -125,6 +244,21 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	submissions := api.Group("/submissions")
 	{
 		submissions.Get("/:domain", submissionController.GetForm)
+		submissions.Post("/:domain", submissionController.Submit)
+		submissions.Post("/:domain/start", submissionController.Start)
+		submissions.Post("/:domain/validate", submissionController.ValidateAnswer)
+		submissions.Post("/:domain/next", submissionController.NextQuestion)
+	}
+
+	// Same endpoints for forms served on their own host, resolved from the Host header
+	hosted := api.Group("/hosted", middlewares.FormHost(cfg.Domains.FormHost))
+	{
+		hosted.Get("/", submissionController.GetForm)
+		hosted.Post("/", submissionController.Submit)
+		hosted.Post("/start", submissionController.Start)
+		hosted.Post("/validate", submissionController.ValidateAnswer)
+		hosted.Post("/next", submissionController.NextQuestion)
 	}
 
+	return services.NewScheduler(jobRepo, formService, trashService, auditService, usageService, billingService, cfg)
 }
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+const defaultRatingStars = 5
+
+var (
+	e164Pattern        = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	phoneStripPattern  = regexp.MustCompile(`[\s\-().]`)
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// ValidateAnswer runs the rules derived from a published question's type and metadata
+// against a single answer to a question of the given form. It returns the normalized
+// answer and any field-level errors.
+func ValidateAnswer(formId string, question *types.PublishedQuestion, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	field := "answers." + question.ID
+	questionType := models.QuestionType(question.Type)
+
+	if isEmptyAnswer(value) {
+		if question.Required {
+			return nil, []utils.ValidationErrorResponse{fieldError(field, "This question is required")}
+		}
+		return nil, nil
+	}
+
+	metadata, err := decodeQuestionMetadata(questionType, question.Metadata)
+	if err != nil {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Question configuration is invalid")}
+	}
+
+	switch questionType {
+	case models.QuestionTypeTextShort, models.QuestionTypeTextLong:
+		return validateTextAnswer(field, metadata.(*TextMetadata), value)
+	case models.QuestionTypeNumber:
+		return validateNumberAnswer(field, metadata.(*NumberMetadata), value)
+	case models.QuestionTypeDate:
+		return validateDateAnswer(field, metadata.(*DateMetadata), value)
+	case models.QuestionTypeChoiceSingle, models.QuestionTypeChoiceDropdown, models.QuestionTypeChoicePicture:
+		return validateSingleChoiceAnswer(field, question.Options, value)
+	case models.QuestionTypeChoiceMultiple, models.QuestionTypeChoiceCheckbox:
+		return validateMultipleChoiceAnswer(field, question.Options, metadata.(*SelectionMetadata), value)
+	case models.QuestionTypeRatingRank:
+		return validateRankAnswer(field, question.Options, value)
+	case models.QuestionTypeChoiceBool:
+		return validateBoolAnswer(field, value, false)
+	case models.QuestionTypeLegal:
+		return validateBoolAnswer(field, value, question.Required)
+	case models.QuestionTypeInfoEmail:
+		return validateEmailAnswer(field, value)
+	case models.QuestionTypeInfoPhone:
+		return validatePhoneAnswer(field, metadata.(*PhoneMetadata), value)
+	case models.QuestionTypeInfoUrl:
+		return validateURLAnswer(field, value)
+	case models.QuestionTypeUserAddress:
+		return validateAddressAnswer(field, metadata.(*AddressMetadata), value)
+	case models.QuestionTypeRatingZeroToTen:
+		return validateRatingAnswer(field, value, 0, 10)
+	case models.QuestionTypeRatingStar:
+		stars := defaultRatingStars
+		if starMeta := metadata.(*RatingStarMetadata); starMeta.Stars != nil {
+			stars = *starMeta.Stars
+		}
+		return validateRatingAnswer(field, value, 1, stars)
+	case models.QuestionTypeFileAny, models.QuestionTypeFileImageOrVideo:
+		return validateFileAnswer(field, formId, metadata.(*FileMetadata), value)
+	case models.QuestionTypeScreenWelcome, models.QuestionTypeScreenEnd,
+		models.QuestionTypeScreenStatement, models.QuestionTypeRedirectToUrl:
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "This question does not accept answers")}
+	}
+
+	return value, nil
+}
+
+func isEmptyAnswer(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func validateTextAnswer(field string, meta *TextMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be text")}
+	}
+	text = strings.TrimSpace(text)
+	length := utf8.RuneCountInString(text)
+
+	var errs []utils.ValidationErrorResponse
+	if meta.MinLength != nil && length < *meta.MinLength {
+		errs = append(errs, fieldError(field, fmt.Sprintf("Answer must be at least %d characters", *meta.MinLength)))
+	}
+	if meta.MaxLength != nil && length > *meta.MaxLength {
+		errs = append(errs, fieldError(field, fmt.Sprintf("Answer must not exceed %d characters", *meta.MaxLength)))
+	}
+	if meta.Pattern != nil && *meta.Pattern != "" {
+		pattern, err := regexp.Compile(*meta.Pattern)
+		if err == nil && !pattern.MatchString(text) {
+			message := "Answer does not match the expected format"
+			if meta.PatternMessage != nil && *meta.PatternMessage != "" {
+				message = *meta.PatternMessage
+			}
+			errs = append(errs, fieldError(field, message))
+		}
+	}
+	return text, errs
+}
+
+func validateNumberAnswer(field string, meta *NumberMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	number, ok := toNumber(value)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a number")}
+	}
+
+	var errs []utils.ValidationErrorResponse
+	if meta.Min != nil && number < *meta.Min {
+		errs = append(errs, fieldError(field, fmt.Sprintf("Answer must be greater than or equal to %v", *meta.Min)))
+	}
+	if meta.Max != nil && number > *meta.Max {
+		errs = append(errs, fieldError(field, fmt.Sprintf("Answer must be less than or equal to %v", *meta.Max)))
+	}
+	if meta.Step != nil && *meta.Step > 0 {
+		base := 0.0
+		if meta.Min != nil {
+			base = *meta.Min
+		}
+		steps := (number - base) / *meta.Step
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			errs = append(errs, fieldError(field, fmt.Sprintf("Answer must be in increments of %v", *meta.Step)))
+		}
+	}
+	return number, errs
+}
+
+func validateDateAnswer(field string, meta *DateMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a date")}
+	}
+	date, err := time.Parse(metadataDate, text)
+	if err != nil {
+		parsed, rfcErr := time.Parse(time.RFC3339, text)
+		if rfcErr != nil {
+			return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a date formatted as YYYY-MM-DD")}
+		}
+		date = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	var errs []utils.ValidationErrorResponse
+	if minDate, _ := parseMetadataDate(meta.MinDate); minDate != nil && date.Before(*minDate) {
+		errs = append(errs, fieldError(field, "Answer must be on or after "+*meta.MinDate))
+	}
+	if maxDate, _ := parseMetadataDate(meta.MaxDate); maxDate != nil && date.After(*maxDate) {
+		errs = append(errs, fieldError(field, "Answer must be on or before "+*meta.MaxDate))
+	}
+	return date.Format(metadataDate), errs
+}
+
+func validateSingleChoiceAnswer(field string, options []types.PublishedQuestionOption, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a single option")}
+	}
+	option := findOption(options, text)
+	if option == nil {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer is not one of the available options")}
+	}
+	return option.Value, nil
+}
+
+func validateMultipleChoiceAnswer(field string, options []types.PublishedQuestionOption, meta *SelectionMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	selected, errs := selectedOptionValues(field, options, value)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if meta.MinSelections != nil && len(selected) < *meta.MinSelections {
+		errs = append(errs, fieldError(field, fmt.Sprintf("Select at least %d options", *meta.MinSelections)))
+	}
+	if meta.MaxSelections != nil && len(selected) > *meta.MaxSelections {
+		errs = append(errs, fieldError(field, fmt.Sprintf("Select at most %d options", *meta.MaxSelections)))
+	}
+	return selected, errs
+}
+
+func validateRankAnswer(field string, options []types.PublishedQuestionOption, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	ranked, errs := selectedOptionValues(field, options, value)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(ranked) != len(options) {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Every option must be ranked exactly once")}
+	}
+	return ranked, nil
+}
+
+// selectedOptionValues resolves a list of option values or IDs, dropping duplicates
+func selectedOptionValues(field string, options []types.PublishedQuestionOption, value interface{}) ([]string, []utils.ValidationErrorResponse) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a list of options")}
+	}
+
+	seen := make(map[string]bool)
+	selected := make([]string, 0, len(items))
+	for i, item := range items {
+		text, ok := item.(string)
+		option := findOption(options, text)
+		if !ok || option == nil {
+			return nil, []utils.ValidationErrorResponse{
+				fieldError(fmt.Sprintf("%s[%d]", field, i), "Answer is not one of the available options"),
+			}
+		}
+		if seen[option.Value] {
+			continue
+		}
+		seen[option.Value] = true
+		selected = append(selected, option.Value)
+	}
+	return selected, nil
+}
+
+func findOption(options []types.PublishedQuestionOption, value string) *types.PublishedQuestionOption {
+	for i := range options {
+		if options[i].Value == value || options[i].ID == value {
+			return &options[i]
+		}
+	}
+	return nil
+}
+
+func validateBoolAnswer(field string, value interface{}, mustAccept bool) (interface{}, []utils.ValidationErrorResponse) {
+	accepted, ok := value.(bool)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be true or false")}
+	}
+	if mustAccept && !accepted {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "This must be accepted to continue")}
+	}
+	return accepted, nil
+}
+
+func validateEmailAnswer(field string, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be an email address")}
+	}
+	text = strings.TrimSpace(text)
+	address, err := mail.ParseAddress(text)
+	if err != nil || address.Address != text {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a valid email address")}
+	}
+	at := strings.LastIndex(address.Address, "@")
+	domain := strings.ToLower(address.Address[at+1:])
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a valid email address")}
+	}
+	return address.Address[:at+1] + domain, nil
+}
+
+// validatePhoneAnswer normalizes a phone number to E.164, using the question's default
+// calling code when the respondent omits the international prefix
+func validatePhoneAnswer(field string, meta *PhoneMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a phone number")}
+	}
+	phone := phoneStripPattern.ReplaceAllString(strings.TrimSpace(text), "")
+	if strings.HasPrefix(phone, "00") {
+		phone = "+" + strings.TrimPrefix(phone, "00")
+	}
+	if !strings.HasPrefix(phone, "+") && meta.DefaultCallingCode != nil && *meta.DefaultCallingCode != "" {
+		phone = "+" + strings.TrimPrefix(*meta.DefaultCallingCode, "+") + strings.TrimPrefix(phone, "0")
+	}
+	if !e164Pattern.MatchString(phone) {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a valid phone number with country code")}
+	}
+	return phone, nil
+}
+
+func validateURLAnswer(field string, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a URL")}
+	}
+	text = strings.TrimSpace(text)
+	if !strings.Contains(text, "://") {
+		text = "https://" + text
+	}
+	parsed, err := url.Parse(text)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") ||
+		parsed.Hostname() == "" || !strings.Contains(parsed.Hostname(), ".") {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a valid URL")}
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	return parsed.String(), nil
+}
+
+func validateAddressAnswer(field string, meta *AddressMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	address, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be an address")}
+	}
+
+	var errs []utils.ValidationErrorResponse
+	normalized := make(map[string]interface{})
+	for _, component := range addressComponents {
+		raw, present := address[component]
+		if !present || raw == nil {
+			continue
+		}
+		text, ok := raw.(string)
+		if !ok {
+			errs = append(errs, fieldError(field+"."+component, component+" must be text"))
+			continue
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if component == "country" {
+			text = strings.ToUpper(text)
+			if !countryCodePattern.MatchString(text) {
+				errs = append(errs, fieldError(field+".country", "country must be a two-letter ISO country code"))
+				continue
+			}
+		}
+		normalized[component] = text
+	}
+	for key := range address {
+		if !containsString(addressComponents, key) {
+			errs = append(errs, fieldError(field+"."+key, key+" is not an address component"))
+		}
+	}
+	for _, component := range meta.RequiredComponents {
+		if _, present := normalized[component]; !present {
+			errs = append(errs, fieldError(field+"."+component, component+" is required"))
+		}
+	}
+	return normalized, errs
+}
+
+func validateRatingAnswer(field string, value interface{}, min int, max int) (interface{}, []utils.ValidationErrorResponse) {
+	number, ok := toNumber(value)
+	if !ok || number != math.Trunc(number) {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a whole number")}
+	}
+	if int(number) < min || int(number) > max {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, fmt.Sprintf("Answer must be between %d and %d", min, max))}
+	}
+	return int(number), nil
+}
+
+// validateFileAnswer accepts keys of files uploaded to this form only, so that an answer
+// cannot reference another form's uploads
+func validateFileAnswer(field string, formId string, meta *FileMetadata, value interface{}) (interface{}, []utils.ValidationErrorResponse) {
+	var keys []string
+	switch v := value.(type) {
+	case string:
+		keys = []string{v}
+	case []interface{}:
+		for i, item := range v {
+			key, ok := item.(string)
+			if !ok {
+				return nil, []utils.ValidationErrorResponse{fieldError(fmt.Sprintf("%s[%d]", field, i), "File reference must be text")}
+			}
+			keys = append(keys, key)
+		}
+	default:
+		return nil, []utils.ValidationErrorResponse{fieldError(field, "Answer must be a file reference")}
+	}
+
+	prefix := "uploads/form_" + formId + "/"
+	for i, key := range keys {
+		if !strings.HasPrefix(key, prefix) || len(key) == len(prefix) || strings.Contains(key, "..") {
+			return nil, []utils.ValidationErrorResponse{fieldError(fmt.Sprintf("%s[%d]", field, i), "File reference is invalid")}
+		}
+	}
+	maxFiles := 1
+	if meta.MaxFiles != nil {
+		maxFiles = *meta.MaxFiles
+	}
+	if len(keys) > maxFiles {
+		return nil, []utils.ValidationErrorResponse{fieldError(field, fmt.Sprintf("At most %d files can be uploaded", maxFiles))}
+	}
+	return keys, nil
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return 0, false
+		}
+		return number, true
+	}
+	return 0, false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"gorm.io/datatypes"
+)
+
+const testFormId = "6f1c2a3b-0000-4000-8000-000000000001"
+
+func TestValidateAnswer(t *testing.T) {
+	choices := []types.PublishedQuestionOption{
+		{ID: "opt-a", Label: "A", Value: "a"},
+		{ID: "opt-b", Label: "B", Value: "b"},
+		{ID: "opt-c", Label: "C", Value: "c"},
+	}
+	question := func(questionType models.QuestionType, required bool, metadata string, options ...types.PublishedQuestionOption) *types.PublishedQuestion {
+		q := &types.PublishedQuestion{ID: "q1", Type: string(questionType), Required: required, Options: options}
+		if metadata != "" {
+			q.Metadata = datatypes.JSON(metadata)
+		}
+		return q
+	}
+
+	tests := []struct {
+		name     string
+		question *types.PublishedQuestion
+		value    interface{}
+		want     interface{}
+		wantErrs int
+	}{
+		{"required and missing", question(models.QuestionTypeTextShort, true, ""), nil, nil, 1},
+		{"required and blank", question(models.QuestionTypeTextShort, true, ""), "   ", nil, 1},
+		{"optional and missing", question(models.QuestionTypeTextShort, false, ""), nil, nil, 0},
+		{"text is trimmed", question(models.QuestionTypeTextShort, false, ""), "  hello ", "hello", 0},
+		{"text too short", question(models.QuestionTypeTextShort, false, `{"minLength": 5}`), "abc", "abc", 1},
+		{"text too long", question(models.QuestionTypeTextLong, false, `{"maxLength": 2}`), "abc", "abc", 1},
+		{"text pattern", question(models.QuestionTypeTextShort, false, `{"pattern": "^[0-9]+$"}`), "12a", "12a", 1},
+		{"text not a string", question(models.QuestionTypeTextShort, false, ""), 12.0, nil, 1},
+
+		{"number from text", question(models.QuestionTypeNumber, false, ""), "42", 42.0, 0},
+		{"number below min", question(models.QuestionTypeNumber, false, `{"min": 10}`), 5.0, 5.0, 1},
+		{"number above max", question(models.QuestionTypeNumber, false, `{"max": 10}`), 11.0, 11.0, 1},
+		{"number off step", question(models.QuestionTypeNumber, false, `{"min": 1, "step": 2}`), 4.0, 4.0, 1},
+		{"number on step", question(models.QuestionTypeNumber, false, `{"min": 1, "step": 2}`), 5.0, 5.0, 0},
+		{"number not numeric", question(models.QuestionTypeNumber, false, ""), "many", nil, 1},
+
+		{"date", question(models.QuestionTypeDate, false, ""), "2024-02-29", "2024-02-29", 0},
+		{"date from RFC 3339", question(models.QuestionTypeDate, false, ""), "2024-02-29T23:00:00Z", "2024-02-29", 0},
+		{"date invalid", question(models.QuestionTypeDate, false, ""), "2023-02-29", nil, 1},
+		{"date before min", question(models.QuestionTypeDate, false, `{"minDate": "2024-01-01"}`), "2023-12-31", "2023-12-31", 1},
+
+		{"single choice by value", question(models.QuestionTypeChoiceSingle, false, "", choices...), "b", "b", 0},
+		{"single choice by id", question(models.QuestionTypeChoiceSingle, false, "", choices...), "opt-c", "c", 0},
+		{"single choice unknown", question(models.QuestionTypeChoiceSingle, false, "", choices...), "z", nil, 1},
+		{"multiple choice drops duplicates", question(models.QuestionTypeChoiceMultiple, false, "", choices...), []interface{}{"a", "opt-a", "b"}, []string{"a", "b"}, 0},
+		{"multiple choice too few", question(models.QuestionTypeChoiceMultiple, false, `{"minSelections": 2}`, choices...), []interface{}{"a"}, []string{"a"}, 1},
+		{"multiple choice not a list", question(models.QuestionTypeChoiceMultiple, false, "", choices...), "a", nil, 1},
+		{"rank must cover every option", question(models.QuestionTypeRatingRank, false, "", choices...), []interface{}{"a", "b"}, nil, 1},
+		{"rank", question(models.QuestionTypeRatingRank, false, "", choices...), []interface{}{"c", "a", "b"}, []string{"c", "a", "b"}, 0},
+
+		{"legal must be accepted", question(models.QuestionTypeLegal, true, ""), false, nil, 1},
+		{"bool may be false", question(models.QuestionTypeChoiceBool, true, ""), false, false, 0},
+
+		{"email domain lowercased", question(models.QuestionTypeInfoEmail, false, ""), "Jane@Example.COM", "Jane@example.com", 0},
+		{"email with display name", question(models.QuestionTypeInfoEmail, false, ""), "Jane <jane@example.com>", nil, 1},
+		{"email without dot", question(models.QuestionTypeInfoEmail, false, ""), "jane@localhost", nil, 1},
+
+		{"phone E.164", question(models.QuestionTypeInfoPhone, false, ""), "+1 (415) 555-0100", "+14155550100", 0},
+		{"phone with default code", question(models.QuestionTypeInfoPhone, false, `{"defaultCallingCode": "+44"}`), "07700 900123", "+447700900123", 0},
+		{"phone without code", question(models.QuestionTypeInfoPhone, false, ""), "4155550100", nil, 1},
+
+		{"url without scheme", question(models.QuestionTypeInfoUrl, false, ""), "Example.com/path", "https://example.com/path", 0},
+		{"url with other scheme", question(models.QuestionTypeInfoUrl, false, ""), "ftp://example.com", nil, 1},
+
+		{"address", question(models.QuestionTypeUserAddress, false, `{"requiredComponents": ["city"]}`), map[string]interface{}{"city": " Paris ", "country": "fr"}, map[string]interface{}{"city": "Paris", "country": "FR"}, 0},
+		{"address missing component", question(models.QuestionTypeUserAddress, false, `{"requiredComponents": ["city"]}`), map[string]interface{}{"country": "FR"}, map[string]interface{}{"country": "FR"}, 1},
+		{"address unknown component", question(models.QuestionTypeUserAddress, false, ""), map[string]interface{}{"planet": "Mars"}, map[string]interface{}{}, 1},
+
+		{"rating 0-10", question(models.QuestionTypeRatingZeroToTen, false, ""), 10.0, 10, 0},
+		{"rating 0-10 out of range", question(models.QuestionTypeRatingZeroToTen, false, ""), 11.0, nil, 1},
+		{"rating not whole", question(models.QuestionTypeRatingZeroToTen, false, ""), 2.5, nil, 1},
+		{"star rating above stars", question(models.QuestionTypeRatingStar, false, `{"stars": 3}`), 4.0, nil, 1},
+
+		{"file of this form", question(models.QuestionTypeFileAny, false, ""), "uploads/form_" + testFormId + "/answer/cv_1.pdf", []string{"uploads/form_" + testFormId + "/answer/cv_1.pdf"}, 0},
+		{"file of another form", question(models.QuestionTypeFileAny, false, ""), "uploads/form_6f1c2a3b-0000-4000-8000-000000000002/answer/cv_1.pdf", nil, 1},
+		{"file sharing the id as a prefix", question(models.QuestionTypeFileAny, false, ""), "uploads/form_" + testFormId + "0/answer/cv_1.pdf", nil, 1},
+		{"file prefix only", question(models.QuestionTypeFileAny, false, ""), "uploads/form_" + testFormId + "/", nil, 1},
+		{"file with traversal", question(models.QuestionTypeFileAny, false, ""), "uploads/form_" + testFormId + "/../other/cv.pdf", nil, 1},
+		{"too many files", question(models.QuestionTypeFileAny, false, ""), []interface{}{"uploads/form_" + testFormId + "/a/1", "uploads/form_" + testFormId + "/a/2"}, nil, 1},
+
+		{"screens take no answer", question(models.QuestionTypeScreenWelcome, false, ""), "hi", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := ValidateAnswer(testFormId, tt.question, tt.value)
+			if len(errs) != tt.wantErrs {
+				t.Fatalf("got %d errors %v, want %d", len(errs), errs, tt.wantErrs)
+			}
+			if tt.wantErrs == 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
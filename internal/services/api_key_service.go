@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/mapper"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// apiKeyBytes is the entropy of an API key
+	apiKeyBytes = 32
+	// apiKeyDisplayLength is how much of the key is kept to identify it in listings
+	apiKeyDisplayLength = len(models.APIKeyPrefix) + 8
+	// apiKeyTouchInterval limits how often lastUsedAt is written for a busy key
+	apiKeyTouchInterval = time.Minute
+)
+
+type IAPIKeyService interface {
+	Create(ctx context.Context, userId string, teamId string, body *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error)
+	List(ctx context.Context, userId string, teamId string) ([]*types.APIKeyResponse, error)
+	Revoke(ctx context.Context, userId string, teamId string, keyId string) error
+	Verify(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+type APIKeyService struct {
+	apiKeyRepo  repositories.IAPIKeyRepo
+	permissions IPermissionService
+	audit       IAuditService
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.IAPIKeyRepo, permissions IPermissionService, audit IAuditService) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		permissions: permissions,
+		audit:       audit,
+	}
+}
+
+// Create issues a key acting for the user in one team. Its scopes must be within the
+// user's role, and it keeps following that role: a key loses access along with its
+// creator.
+func (s *APIKeyService) Create(ctx context.Context, userId string, teamId string, body *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error) {
+
+	team, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.permissions.Role(ctx, userId, team)
+	if err != nil {
+		return nil, err
+	}
+	scopes := make([]string, 0, len(body.Scopes))
+	seen := make(map[string]bool, len(body.Scopes))
+	for _, scope := range body.Scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		for _, permission := range scopePermissions[models.APIKeyScope(scope)] {
+			if !roleAllows(role, permission) {
+				return nil, errors.BadRequest("Your role cannot grant the " + scope + " scope")
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+
+	var expiresAt *time.Time
+	if body.ExpiresAt != "" {
+		at, err := time.Parse(time.RFC3339, body.ExpiresAt)
+		if err != nil {
+			return nil, errors.BadRequest("expiresAt must be an RFC 3339 timestamp")
+		}
+		if !at.After(time.Now()) {
+			return nil, errors.BadRequest("expiresAt must be in the future")
+		}
+		at = at.UTC()
+		expiresAt = &at
+	}
+
+	secret, err := utils.GenerateSecureToken(apiKeyBytes)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	key := models.APIKeyPrefix + secret
+
+	apiKey := &models.APIKey{
+		ID:        utils.GenerateUUID(),
+		TeamID:    team.ID,
+		Name:      strings.TrimSpace(body.Name),
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    scopes,
+		CreatedBy: userId,
+		ExpiresAt: expiresAt,
+		CreatedAt: *utils.GetCurrentTime(),
+	}
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceAPIKey,
+		ResourceID:   apiKey.ID,
+		Action:       models.AuditActionCreate,
+		After:        apiKey,
+	})
+
+	return &types.CreateAPIKeyResponse{
+		APIKeyResponse: mapper.ToAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userId string, teamId string) ([]*types.APIKeyResponse, error) {
+
+	if _, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionViewSettings); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.apiKeyRepo.GetByTeam(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*types.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		resp[i] = mapper.ToAPIKeyResponse(key)
+	}
+	return resp, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userId string, teamId string, keyId string) error {
+
+	if _, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionRevokeAccess); err != nil {
+		return err
+	}
+
+	key, err := s.apiKeyRepo.GetById(ctx, teamId, keyId)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return errors.NotFound("API key")
+	}
+
+	now := time.Now().UTC()
+	revoked, err := s.apiKeyRepo.Revoke(ctx, key.ID, now)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.Conflict("This API key is already revoked")
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamId,
+		ResourceType: models.AuditResourceAPIKey,
+		ResourceID:   key.ID,
+		Action:       models.AuditActionRevoke,
+		Before:       key,
+		After:        map[string]interface{}{"revokedAt": now},
+	})
+	return nil
+}
+
+// Verify returns the active key matching a presented key and records its use
+func (s *APIKeyService) Verify(ctx context.Context, key string) (*models.APIKey, error) {
+
+	if !strings.HasPrefix(key, models.APIKeyPrefix) {
+		return nil, errors.Unauthorized("Invalid API key")
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, utils.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || !apiKey.Team.Valid {
+		return nil, errors.Unauthorized("Invalid API key")
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, errors.Unauthorized("This API key has been revoked")
+	}
+
+	now := time.Now().UTC()
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return nil, errors.Unauthorized("This API key has expired")
+	}
+
+	if err := s.apiKeyRepo.Touch(ctx, apiKey.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		log.Error().Err(err).Str("apiKeyId", apiKey.ID).Msg("Failed to record API key use")
+	}
+	return apiKey, nil
+}
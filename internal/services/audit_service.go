@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/mapper"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// auditIgnoredFields change on every write and say nothing about what was changed
+var auditIgnoredFields = map[string]bool{
+	"updatedAt": true,
+}
+
+// auditRedactedFields are recorded as changed without their values
+var auditRedactedFields = map[string]bool{
+	"password": true,
+}
+
+const auditRedacted = "[redacted]"
+
+// AuditEvent is a change to record. Before and After are models, request bodies or
+// update maps; only the fields that differ between them are stored. When both are
+// set, only the fields in After are compared, so a partial update map can be diffed
+// against the full model it was applied to.
+type AuditEvent struct {
+	TeamID       string
+	ResourceType models.AuditResourceType
+	ResourceID   string
+	Action       models.AuditAction
+	Before       interface{}
+	After        interface{}
+}
+
+// AuditChange is a changed field's value before and after
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type IAuditService interface {
+	Record(ctx context.Context, event *AuditEvent)
+	List(ctx context.Context, userId string, teamId string, query *types.AuditLogQuery, pagination *types.PaginationQuery) ([]*types.AuditLogResponse, int64, error)
+}
+
+type AuditService struct {
+	auditRepo   repositories.IAuditRepo
+	permissions IPermissionService
+	cfg         *config.Config
+}
+
+func NewAuditService(auditRepo repositories.IAuditRepo, permissions IPermissionService, cfg *config.Config) *AuditService {
+	return &AuditService{
+		auditRepo:   auditRepo,
+		permissions: permissions,
+		cfg:         cfg,
+	}
+}
+
+// Record stores an audit entry for a change that has already been made. The actor, API
+// key, client IP and request ID come from the request locals carried by ctx. Failing
+// to record is logged rather than returned, since the change itself went through.
+func (s *AuditService) Record(ctx context.Context, event *AuditEvent) {
+
+	changes, changed := auditDiff(event.Before, event.After)
+	if event.Before != nil && event.After != nil && !changed {
+		return
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		log.Error().Err(err).Str("resourceId", event.ResourceID).Msg("Failed to encode audit changes")
+		data = []byte("{}")
+	}
+
+	entry := &models.AuditLog{
+		ID:           utils.GenerateUUID(),
+		TeamID:       event.TeamID,
+		ActorID:      contextString(ctx, "user_id"),
+		IP:           contextString(ctx, "client_ip"),
+		RequestID:    contextString(ctx, "request_id"),
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Action:       event.Action,
+		Changes:      data,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if key := apiKeyFromContext(ctx); key != nil {
+		entry.APIKeyID = &key.ID
+	}
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		log.Error().Err(err).
+			Str("teamId", event.TeamID).
+			Str("resourceType", string(event.ResourceType)).
+			Str("resourceId", event.ResourceID).
+			Str("action", string(event.Action)).
+			Msg("Failed to record audit entry")
+	}
+}
+
+func (s *AuditService) List(ctx context.Context, userId string, teamId string, query *types.AuditLogQuery, pagination *types.PaginationQuery) ([]*types.AuditLogResponse, int64, error) {
+
+	if _, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionViewAudit); err != nil {
+		return nil, 0, err
+	}
+
+	filter := &repositories.AuditLogFilter{
+		ActorID:      query.ActorID,
+		ResourceType: strings.ToUpper(query.ResourceType),
+		ResourceID:   query.ResourceID,
+		Action:       strings.ToUpper(query.Action),
+	}
+	if query.From != "" {
+		from, err := time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return nil, 0, errors.BadRequest("from must be an RFC 3339 timestamp")
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, err := time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return nil, 0, errors.BadRequest("to must be an RFC 3339 timestamp")
+		}
+		filter.To = &to
+	}
+	pagination.Normalize()
+
+	entries, total, err := s.auditRepo.Get(ctx, teamId, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]*types.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		resp[i] = mapper.ToAuditLogResponse(entry)
+	}
+	return resp, total, nil
+}
+
+// Purge deletes audit entries older than the retention period
+func (s *AuditService) Purge(ctx context.Context) error {
+
+	purged, err := s.auditRepo.Purge(ctx, time.Now().Add(-s.cfg.Audit.Retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Info().Int64("entries", purged).Msg("Purged audit log")
+	}
+	return nil
+}
+
+// contextString reads a string request local, returning nil when it is not set
+func contextString(ctx context.Context, key string) *string {
+	value, _ := ctx.Value(key).(string)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// auditDiff returns the fields that differ between before and after, and whether any do
+func auditDiff(before interface{}, after interface{}) (map[string]*AuditChange, bool) {
+
+	from := auditFields(before)
+	to := auditFields(after)
+
+	changes := make(map[string]*AuditChange)
+	switch {
+	case from == nil:
+		for field, value := range to {
+			changes[field] = &AuditChange{To: value}
+		}
+	case to == nil:
+		for field, value := range from {
+			changes[field] = &AuditChange{From: value}
+		}
+	default:
+		for field, value := range to {
+			if !reflect.DeepEqual(from[field], value) {
+				changes[field] = &AuditChange{From: from[field], To: value}
+			}
+		}
+	}
+
+	for field, change := range changes {
+		if auditIgnoredFields[field] {
+			delete(changes, field)
+			continue
+		}
+		if auditRedactedFields[field] {
+			if change.From != nil {
+				change.From = auditRedacted
+			}
+			if change.To != nil {
+				change.To = auditRedacted
+			}
+		}
+	}
+	return changes, len(changes) > 0
+}
+
+// auditFields flattens a value to its JSON fields, dropping a model's associations so
+// that only its own columns are compared
+func auditFields(value interface{}) map[string]interface{} {
+
+	if value == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	if rv.Kind() == reflect.Struct {
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			gormTag := field.Tag.Get("gorm")
+			if !strings.Contains(gormTag, "foreignKey:") && !strings.Contains(gormTag, "many2many:") {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = field.Name
+			}
+			delete(fields, name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type IBillingService interface {
+	PaymentFailed(ctx context.Context, userId string, teamId string) error
+	PaymentRecovered(ctx context.Context, userId string, teamId string) error
+}
+
+// BillingService moves subscriptions through their billing cycles. At the end of a
+// cycle a scheduled cancellation or downgrade is applied and the counters are reset.
+// A team over its limits or with a failed payment goes into GRACE, and is BLOCKED
+// when the grace period ends without it being resolved. A blocked team is read-only.
+type BillingService struct {
+	subscriptionRepo repositories.ISubscriptionRepo
+	teamRepo         repositories.ITeamRepo
+	permissions      IPermissionService
+	audit            IAuditService
+	entitlements     IEntitlementService
+	cfg              *config.Config
+}
+
+func NewBillingService(subscriptionRepo repositories.ISubscriptionRepo, teamRepo repositories.ITeamRepo, permissions IPermissionService, audit IAuditService, entitlements IEntitlementService, cfg *config.Config) *BillingService {
+	return &BillingService{
+		subscriptionRepo: subscriptionRepo,
+		teamRepo:         teamRepo,
+		permissions:      permissions,
+		audit:            audit,
+		entitlements:     entitlements,
+		cfg:              cfg,
+	}
+}
+
+// teamBlockedError is returned for changes to a blocked team
+func teamBlockedError() error {
+	return errors.PaymentRequired("The team is blocked until its billing is resolved and is read-only", nil)
+}
+
+// PaymentFailed marks the team's subscription as unpaid, which puts the team into GRACE
+func (s *BillingService) PaymentFailed(ctx context.Context, userId string, teamId string) error {
+
+	team, err := s.getSubscribedTeam(ctx, userId, teamId)
+	if err != nil {
+		return err
+	}
+	if team.TeamUsage.PaymentFailedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.subscriptionRepo.Update(ctx, team.TeamUsage.ID, map[string]interface{}{"paymentFailedAt": now}); err != nil {
+		return err
+	}
+	team.TeamUsage.PaymentFailedAt = &now
+	return s.updateStatus(ctx, team, now)
+}
+
+// PaymentRecovered clears a failed payment, bringing the team back to ACTIVE when it
+// is also within its limits
+func (s *BillingService) PaymentRecovered(ctx context.Context, userId string, teamId string) error {
+
+	team, err := s.getSubscribedTeam(ctx, userId, teamId)
+	if err != nil {
+		return err
+	}
+	if team.TeamUsage.PaymentFailedAt == nil {
+		return nil
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, team.TeamUsage.ID, map[string]interface{}{"paymentFailedAt": nil}); err != nil {
+		return err
+	}
+	team.TeamUsage.PaymentFailedAt = nil
+	return s.updateStatus(ctx, team, time.Now())
+}
+
+// getSubscribedTeam loads a team with its subscription for support staff
+func (s *BillingService) getSubscribedTeam(ctx context.Context, userId string, teamId string) (*models.Team, error) {
+
+	if !s.permissions.IsSupportAdmin(ctx, userId) {
+		return nil, errors.Unauthorized("")
+	}
+
+	team, err := s.teamRepo.GetWithPlan(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+	if team == nil {
+		return nil, errors.NotFound("Team")
+	}
+	if team.TeamUsage == nil {
+		return nil, errors.BadRequest("Team has no subscription")
+	}
+	return team, nil
+}
+
+// Run advances every subscribed team. A team that fails is logged and retried on the
+// next run.
+func (s *BillingService) Run(ctx context.Context) error {
+
+	teamIds, err := s.subscriptionRepo.GetSubscribedTeams(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, teamId := range teamIds {
+		if err := s.advance(ctx, teamId, time.Now()); err != nil {
+			log.Error().Err(err).Str("teamId", teamId).Msg("Failed to advance subscription")
+		}
+	}
+	return nil
+}
+
+func (s *BillingService) advance(ctx context.Context, teamId string, now time.Time) error {
+
+	team, err := s.teamRepo.GetWithPlan(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if team == nil || team.TeamUsage == nil {
+		return nil
+	}
+
+	subscription := team.TeamUsage
+	if !subscription.CycleEnd.IsZero() && !now.Before(subscription.CycleEnd) {
+		if subscription.CancelCycleAt != nil && !subscription.CancelCycleAt.After(subscription.CycleEnd) {
+			return s.cancel(ctx, team)
+		}
+
+		start, end := nextCycle(subscription.CycleStart, subscription.CycleEnd, now)
+		rolled, err := s.subscriptionRepo.Roll(ctx, team.ID, subscription, start, end)
+		if err != nil {
+			return err
+		}
+		if !rolled {
+			return nil
+		}
+
+		s.audit.Record(ctx, &AuditEvent{
+			TeamID:       team.ID,
+			ResourceType: models.AuditResourceSubscription,
+			ResourceID:   subscription.ID,
+			Action:       models.AuditActionUpdate,
+			Before:       map[string]interface{}{"cycleStart": subscription.CycleStart, "cycleEnd": subscription.CycleEnd, "planId": subscription.PlanID},
+			After:        map[string]interface{}{"cycleStart": start, "cycleEnd": end, "planId": rolledPlan(subscription)},
+		})
+
+		// Reload so that a downgrade is checked against the new plan's limits
+		team, err = s.teamRepo.GetWithPlan(ctx, teamId)
+		if err != nil {
+			return err
+		}
+		if team == nil || team.TeamUsage == nil {
+			return nil
+		}
+	}
+
+	return s.updateStatus(ctx, team, now)
+}
+
+// cancel moves the team to the free plan at the end of its last cycle
+func (s *BillingService) cancel(ctx context.Context, team *models.Team) error {
+
+	var planId *string
+	if s.cfg.Billing.FreePlanID != "" {
+		planId = &s.cfg.Billing.FreePlanID
+	}
+
+	cancelled, err := s.subscriptionRepo.Cancel(ctx, team.ID, team.TeamUsage, planId)
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		s.audit.Record(ctx, &AuditEvent{
+			TeamID:       team.ID,
+			ResourceType: models.AuditResourceSubscription,
+			ResourceID:   team.TeamUsage.ID,
+			Action:       models.AuditActionCancel,
+			Before:       map[string]interface{}{"planId": team.PlanID},
+			After:        map[string]interface{}{"planId": planId},
+		})
+	}
+	return nil
+}
+
+// updateStatus moves the subscription between ACTIVE, GRACE and BLOCKED
+func (s *BillingService) updateStatus(ctx context.Context, team *models.Team, now time.Time) error {
+
+	subscription := team.TeamUsage
+
+	overLimit, err := s.entitlements.OverLimit(ctx, team)
+	if err != nil {
+		return err
+	}
+
+	status := subscription.Status
+	graceEndsAt := subscription.GraceEndsAt
+	switch {
+	case !overLimit && subscription.PaymentFailedAt == nil:
+		status = models.TeamSubscriptionStatusActive
+		graceEndsAt = nil
+	case status == models.TeamSubscriptionStatusActive || status == "":
+		status = models.TeamSubscriptionStatusGrace
+		ends := now.Add(s.cfg.Billing.GracePeriod)
+		graceEndsAt = &ends
+	case status == models.TeamSubscriptionStatusGrace && graceEndsAt != nil && !now.Before(*graceEndsAt):
+		status = models.TeamSubscriptionStatusBlocked
+	}
+
+	if status == subscription.Status {
+		return nil
+	}
+
+	err = s.subscriptionRepo.Update(ctx, subscription.ID, map[string]interface{}{
+		"status":      status,
+		"graceEndsAt": graceEndsAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceSubscription,
+		ResourceID:   subscription.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       map[string]interface{}{"status": subscription.Status},
+		After:        map[string]interface{}{"status": status, "overLimit": overLimit, "paymentFailed": subscription.PaymentFailedAt != nil},
+	})
+	log.Info().Str("teamId", team.ID).Str("from", string(subscription.Status)).Str("to", string(status)).Msg("Subscription status changed")
+
+	subscription.Status = status
+	subscription.GraceEndsAt = graceEndsAt
+	return nil
+}
+
+// nextCycle is the cycle containing now, keeping the length of the current cycle in
+// months so that monthly and yearly subscriptions keep their anchor day
+func nextCycle(start time.Time, end time.Time, now time.Time) (time.Time, time.Time) {
+
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	if months < 1 {
+		months = 1
+	}
+	// A cycle anchored on the 31st ends on the 28th in February; the later of the two
+	// days recovers the anchor
+	day := start.Day()
+	if end.Day() > day {
+		day = end.Day()
+	}
+	for !end.After(now) {
+		start = end
+		end = addMonths(start, months, day)
+	}
+	return start, end
+}
+
+// addMonths moves t forward by months onto the given day, or the last day of the
+// month when it is shorter
+func addMonths(t time.Time, months int, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// rolledPlan is the subscription's plan once its scheduled downgrade is applied
+func rolledPlan(subscription *models.TeamSubscription) string {
+	if subscription.NextPlanID != nil {
+		return *subscription.NextPlanID
+	}
+	return subscription.PlanID
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		name   string
+		t      time.Time
+		months int
+		day    int
+		want   time.Time
+	}{
+		{"same day next month", date(2025, time.January, 15), 1, 15, date(2025, time.February, 15)},
+		{"31st into February", date(2025, time.January, 31), 1, 31, date(2025, time.February, 28)},
+		{"31st into February of a leap year", date(2024, time.January, 31), 1, 31, date(2024, time.February, 29)},
+		{"31st into a 30-day month", date(2025, time.March, 31), 1, 31, date(2025, time.April, 30)},
+		{"anchor recovered after a short month", date(2025, time.February, 28), 1, 31, date(2025, time.March, 31)},
+		{"across the year end", date(2024, time.December, 31), 1, 31, date(2025, time.January, 31)},
+		{"leap day a year later", date(2024, time.February, 29), 12, 29, date(2025, time.February, 28)},
+		{"leap day four years later", date(2024, time.February, 29), 48, 29, date(2028, time.February, 29)},
+		{"quarterly", date(2025, time.November, 30), 3, 30, date(2026, time.February, 28)},
+		{"time of day kept", time.Date(2025, time.May, 10, 13, 45, 0, 0, time.UTC), 1, 10, time.Date(2025, time.June, 10, 13, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addMonths(tt.t, tt.months, tt.day); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextCycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:  "current cycle not over",
+			start: date(2025, time.January, 1), end: date(2025, time.February, 1), now: date(2025, time.January, 20),
+			wantStart: date(2025, time.January, 1), wantEnd: date(2025, time.February, 1),
+		},
+		{
+			name:  "rolls when the end is reached",
+			start: date(2025, time.January, 1), end: date(2025, time.February, 1), now: date(2025, time.February, 1),
+			wantStart: date(2025, time.February, 1), wantEnd: date(2025, time.March, 1),
+		},
+		{
+			name:  "anchor on the 31st after February",
+			start: date(2025, time.January, 31), end: date(2025, time.February, 28), now: date(2025, time.March, 1),
+			wantStart: date(2025, time.February, 28), wantEnd: date(2025, time.March, 31),
+		},
+		{
+			name:  "anchor on the 31st in a leap year",
+			start: date(2023, time.December, 31), end: date(2024, time.January, 31), now: date(2024, time.February, 1),
+			wantStart: date(2024, time.January, 31), wantEnd: date(2024, time.February, 29),
+		},
+		{
+			name:  "anchor kept after a 30-day month",
+			start: date(2025, time.March, 31), end: date(2025, time.April, 30), now: date(2025, time.May, 2),
+			wantStart: date(2025, time.April, 30), wantEnd: date(2025, time.May, 31),
+		},
+		{
+			name:  "several missed cycles",
+			start: date(2025, time.January, 15), end: date(2025, time.February, 15), now: date(2025, time.May, 20),
+			wantStart: date(2025, time.May, 15), wantEnd: date(2025, time.June, 15),
+		},
+		{
+			name:  "yearly from a leap day",
+			start: date(2024, time.February, 29), end: date(2025, time.February, 28), now: date(2025, time.March, 1),
+			wantStart: date(2025, time.February, 28), wantEnd: date(2026, time.February, 28),
+		},
+		{
+			name:  "cycle shorter than a month becomes monthly",
+			start: date(2025, time.June, 1), end: date(2025, time.June, 15), now: date(2025, time.June, 20),
+			wantStart: date(2025, time.June, 15), wantEnd: date(2025, time.July, 15),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStart, gotEnd := nextCycle(tt.start, tt.end, tt.now)
+			if !gotStart.Equal(tt.wantStart) || !gotEnd.Equal(tt.wantEnd) {
+				t.Errorf("got %v to %v, want %v to %v", gotStart, gotEnd, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -16,7 +16,11 @@ import (
 type IDashService interface {
 	GetAnalytics(ctx context.Context, userId string, formId string) (*types.FormAnalytics, error)
 	GetResponses(ctx context.Context, userId string, formId string) ([]*models.Response, error)
-	GetQuestions(ctx context.Context, formId string) ([]*types.QuestionResponse, error)
+	GetQuestions(ctx context.Context, userId string, formId string) ([]*types.QuestionResponse, error)
+	ExportResponses(ctx context.Context, userId string, formId string) ([]byte, error)
+	GetQuizReport(ctx context.Context, userId string, formId string) (*types.QuizReport, error)
+	UpdateResponse(ctx context.Context, userId string, formId string, responseId string, body *types.UpdateResponseRequest) (*models.Response, error)
+	DeleteResponse(ctx context.Context, userId string, formId string, responseId string) error
 
 	GetPasswords(ctx context.Context, userId string, formId string) ([]*types.ActivePasswordResponse, error)
 	CreatePassword(ctx context.Context, userId string, formId string, password types.PasswordRequest) (*types.ActivePasswordResponse, error)
@@ -28,17 +32,26 @@ type IDashService interface {
 }
 
 type DashService struct {
-	dashRepo repositories.IDashRepo
-	formRepo repositories.IFormRepo
+	dashRepo    repositories.IDashRepo
+	formRepo    repositories.IFormRepo
+	jobRepo     repositories.IJobRepo
+	permissions IPermissionService
+	audit       IAuditService
 }
 
 func NewDashService(
 	dashRepo repositories.IDashRepo,
 	formRepo repositories.IFormRepo,
+	jobRepo repositories.IJobRepo,
+	permissions IPermissionService,
+	audit IAuditService,
 ) *DashService {
 	return &DashService{
-		dashRepo: dashRepo,
-		formRepo: formRepo,
+		dashRepo:    dashRepo,
+		formRepo:    formRepo,
+		jobRepo:     jobRepo,
+		permissions: permissions,
+		audit:       audit,
 	}
 }
 
@@ -53,8 +66,8 @@ func (s *DashService) GetAnalytics(ctx context.Context, userId string, formId st
 		return nil, errors.NotFound("Form")
 	}
 
-	if *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("Overview Analytics")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionViewResponses); err != nil {
+		return nil, err
 	}
 
 	analytics, err := s.dashRepo.GetAnalytics(ctx, formId)
@@ -76,8 +89,8 @@ func (s *DashService) GetResponses(ctx context.Context, userId string, formId st
 		return nil, errors.NotFound("Form")
 	}
 
-	if *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("Overview Analytics")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionViewResponses); err != nil {
+		return nil, err
 	}
 
 	responses, err := s.dashRepo.GetResponses(ctx, formId)
@@ -91,7 +104,101 @@ func (s *DashService) GetResponses(ctx context.Context, userId string, formId st
 	return responses, nil
 }
 
-func (s *DashService) GetQuestions(ctx context.Context, formId string) ([]*types.QuestionResponse, error) {
+// UpdateResponse changes the tags of a response. Answers are left as they were
+// submitted.
+func (s *DashService) UpdateResponse(ctx context.Context, userId string, formId string, responseId string, body *types.UpdateResponseRequest) (*models.Response, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditResponses); err != nil {
+		return nil, err
+	}
+
+	response, err := s.dashRepo.GetResponse(ctx, formId, responseId)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, errors.NotFound("Response")
+	}
+
+	tags := body.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	if err := s.dashRepo.UpdateResponse(ctx, responseId, map[string]interface{}{"tags": tags}); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceResponse,
+		ResourceID:   responseId,
+		Action:       models.AuditActionUpdate,
+		Before:       map[string]interface{}{"tags": response.Tags},
+		After:        map[string]interface{}{"tags": tags},
+	})
+	response.Tags = tags
+	return response, nil
+}
+
+func (s *DashService) DeleteResponse(ctx context.Context, userId string, formId string, responseId string) error {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditResponses); err != nil {
+		return err
+	}
+
+	response, err := s.dashRepo.GetResponse(ctx, formId, responseId)
+	if err != nil {
+		return err
+	}
+	if response == nil {
+		return errors.NotFound("Response")
+	}
+
+	if err := s.dashRepo.DeleteResponse(ctx, responseId); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceResponse,
+		ResourceID:   responseId,
+		Action:       models.AuditActionDelete,
+	})
+	return nil
+}
+
+func (s *DashService) GetQuestions(ctx context.Context, userId string, formId string) ([]*types.QuestionResponse, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionViewResponses); err != nil {
+		return nil, err
+	}
 
 	questions, err := s.dashRepo.GetQuestions(ctx, formId)
 	if err != nil {
@@ -120,8 +227,8 @@ func (s *DashService) GetPasswords(ctx context.Context, userId string, formId st
 		return nil, errors.NotFound("Form")
 	}
 
-	if *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("Overview Analytics")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionViewSettings); err != nil {
+		return nil, err
 	}
 
 	passwords, err := s.dashRepo.GetPasswords(ctx, formId)
@@ -148,8 +255,8 @@ func (s *DashService) CreatePassword(ctx context.Context, userId string, formId
 		return nil, errors.NotFound("Form")
 	}
 
-	if *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("Overview Analytics")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionManage); err != nil {
+		return nil, err
 	}
 
 	var expireAt *time.Time
@@ -176,6 +283,14 @@ func (s *DashService) CreatePassword(ctx context.Context, userId string, formId
 		return nil, err
 	}
 
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourcePassword,
+		ResourceID:   createdPassword.ID,
+		Action:       models.AuditActionCreate,
+		After:        createdPassword,
+	})
+
 	return mapper.ToActivePasswordResponse(createdPassword), nil
 }
 
@@ -190,8 +305,8 @@ func (s *DashService) UpdatePassword(ctx context.Context, userId string, formId
 		return errors.NotFound("Form")
 	}
 
-	if *form.Team.OwnerID != userId {
-		return errors.Unauthorized("Overview Analytics")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionManage); err != nil {
+		return err
 	}
 
 	var expireAt *time.Time
@@ -218,6 +333,13 @@ func (s *DashService) UpdatePassword(ctx context.Context, userId string, formId
 		return err
 	}
 
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourcePassword,
+		ResourceID:   passwordId,
+		Action:       models.AuditActionUpdate,
+		After:        pass,
+	})
 	return nil
 }
 
@@ -232,14 +354,21 @@ func (s *DashService) DeletePassword(ctx context.Context, userId string, formId
 		return errors.NotFound("Form")
 	}
 
-	if *form.Team.OwnerID != userId {
-		return errors.Unauthorized("Overview Analytics")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionRevokeAccess); err != nil {
+		return err
 	}
 
 	err = s.dashRepo.DeletePassword(ctx, passwordId)
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourcePassword,
+		ResourceID:   passwordId,
+		Action:       models.AuditActionDelete,
+	})
 	return nil
 }
 
@@ -254,8 +383,20 @@ func (s *DashService) UpdateSecurity(ctx context.Context, userId string, formId
 		return errors.NotFound("Form")
 	}
 
-	if form.CreatedBy != userId {
-		return errors.Unauthorized("")
+	if _, err := s.permissions.CheckTeam(ctx, userId, form.TeamID, PermissionManage); err != nil {
+		return err
+	}
+
+	openAt, err := parseScheduleTime("openAt", body.OpenAt)
+	if err != nil {
+		return err
+	}
+	closeAt, err := parseScheduleTime("closeAt", body.CloseAt)
+	if err != nil {
+		return err
+	}
+	if openAt != nil && closeAt != nil && !closeAt.After(*openAt) {
+		return errors.BadRequest("closeAt must be after openAt")
 	}
 
 	updates := make(map[string]interface{})
@@ -267,7 +408,27 @@ func (s *DashService) UpdateSecurity(ctx context.Context, userId string, formId
 		return err
 	}
 
-	return nil
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       form,
+		After:        updates,
+	})
+
+	return syncFormSchedule(ctx, s.jobRepo, formId, userId, openAt, closeAt)
+}
+
+func parseScheduleTime(field string, value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, errors.BadRequest(field + " must be an RFC 3339 date-time")
+	}
+	return &parsed, nil
 }
 
 func (s *DashService) UpdateSettings(ctx context.Context, userId string, formId string, body *types.UpdateSettingsRequest) error {
@@ -281,8 +442,8 @@ func (s *DashService) UpdateSettings(ctx context.Context, userId string, formId
 		return errors.NotFound("Form")
 	}
 
-	if form.CreatedBy != userId {
-		return errors.Unauthorized("")
+	if _, err := s.permissions.CheckTeam(ctx, userId, form.TeamID, PermissionEditForms); err != nil {
+		return err
 	}
 
 	updates := make(map[string]interface{})
@@ -294,5 +455,13 @@ func (s *DashService) UpdateSettings(ctx context.Context, userId string, formId
 		return err
 	}
 
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       form,
+		After:        updates,
+	})
 	return nil
 }
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/dns"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+const (
+	// domainChallengePrefix is the label under which the verification TXT record is created
+	domainChallengePrefix = "_escapeform-challenge"
+	domainChallengeValue  = "escapeform-verification="
+	domainLookupTimeout   = 5 * time.Second
+)
+
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+type IDomainService interface {
+	Get(ctx context.Context, userId string, formId string) (*types.CustomDomainResponse, error)
+	Set(ctx context.Context, userId string, formId string, body *types.SetCustomDomainRequest) (*types.CustomDomainResponse, error)
+	Verify(ctx context.Context, userId string, formId string) (*types.CustomDomainResponse, error)
+	Delete(ctx context.Context, userId string, formId string) error
+}
+
+type DomainService struct {
+	domainRepo  repositories.IDomainRepo
+	formRepo    repositories.IFormRepo
+	resolver    dns.Resolver
+	permissions IPermissionService
+	audit       IAuditService
+	cfg         *config.Config
+}
+
+func NewDomainService(domainRepo repositories.IDomainRepo, formRepo repositories.IFormRepo, resolver dns.Resolver, permissions IPermissionService, audit IAuditService, cfg *config.Config) *DomainService {
+	return &DomainService{
+		domainRepo:  domainRepo,
+		formRepo:    formRepo,
+		resolver:    resolver,
+		permissions: permissions,
+		audit:       audit,
+		cfg:         cfg,
+	}
+}
+
+func (s *DomainService) Get(ctx context.Context, userId string, formId string) (*types.CustomDomainResponse, error) {
+
+	if _, err := s.checkAccess(ctx, userId, formId, PermissionViewSettings); err != nil {
+		return nil, err
+	}
+
+	customDomain, err := s.domainRepo.GetByForm(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	if customDomain == nil {
+		return nil, errors.NotFound("Custom domain")
+	}
+
+	return s.toResponse(customDomain, s.cnameConfigured(ctx, customDomain.Domain)), nil
+}
+
+// Set claims a domain for the form and issues a new challenge token
+func (s *DomainService) Set(ctx context.Context, userId string, formId string, body *types.SetCustomDomainRequest) (*types.CustomDomainResponse, error) {
+
+	form, err := s.checkAccess(ctx, userId, formId, PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := normalizeDomain(body.Domain)
+	if !hostnamePattern.MatchString(domain) {
+		return nil, errors.BadRequest("Invalid domain")
+	}
+	formHost := normalizeDomain(s.cfg.Domains.FormHost)
+	if domain == formHost || strings.HasSuffix(domain, "."+formHost) {
+		return nil, errors.BadRequest("Subdomains of " + formHost + " cannot be claimed")
+	}
+
+	verified, err := s.domainRepo.GetVerified(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if verified != nil && verified.FormID != formId {
+		return nil, errors.Conflict("Domain is already in use by another form")
+	}
+
+	customDomain, err := s.domainRepo.Claim(ctx, &models.CustomDomain{
+		ID:        utils.GenerateUUID(),
+		FormID:    formId,
+		Domain:    domain,
+		Token:     strings.ReplaceAll(utils.GenerateUUID(), "-", ""),
+		Status:    models.DomainStatusPending,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceDomain,
+		ResourceID:   customDomain.ID,
+		Action:       models.AuditActionCreate,
+		After:        customDomain,
+	})
+	return s.toResponse(customDomain, false), nil
+}
+
+// Verify checks the challenge TXT record of the claimed domain
+func (s *DomainService) Verify(ctx context.Context, userId string, formId string) (*types.CustomDomainResponse, error) {
+
+	form, err := s.checkAccess(ctx, userId, formId, PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	customDomain, err := s.domainRepo.GetByForm(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	if customDomain == nil {
+		return nil, errors.NotFound("Custom domain")
+	}
+
+	now := time.Now()
+	cnameConfigured := s.cnameConfigured(ctx, customDomain.Domain)
+	if customDomain.Status == models.DomainStatusVerified {
+		return s.toResponse(customDomain, cnameConfigured), nil
+	}
+
+	if failure := s.checkChallenge(ctx, customDomain); failure != "" {
+		customDomain.Status = models.DomainStatusFailed
+		customDomain.LastError = &failure
+		customDomain.LastCheckedAt = &now
+		err := s.domainRepo.Update(ctx, customDomain.ID, map[string]interface{}{
+			"status":        customDomain.Status,
+			"lastError":     failure,
+			"lastCheckedAt": now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s.toResponse(customDomain, cnameConfigured), nil
+	}
+
+	verified, err := s.domainRepo.GetVerified(ctx, customDomain.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if verified != nil && verified.FormID != formId {
+		return nil, errors.Conflict("Domain is already in use by another form")
+	}
+
+	if err := s.domainRepo.MarkVerified(ctx, customDomain, now); err != nil {
+		return nil, err
+	}
+	customDomain.Status = models.DomainStatusVerified
+	customDomain.LastError = nil
+	customDomain.LastCheckedAt = &now
+	customDomain.VerifiedAt = &now
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceDomain,
+		ResourceID:   customDomain.ID,
+		Action:       models.AuditActionVerify,
+		After:        map[string]interface{}{"domain": customDomain.Domain, "status": customDomain.Status},
+	})
+	return s.toResponse(customDomain, cnameConfigured), nil
+}
+
+func (s *DomainService) Delete(ctx context.Context, userId string, formId string) error {
+
+	form, err := s.checkAccess(ctx, userId, formId, PermissionManage)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.domainRepo.GetByForm(ctx, formId)
+	if err != nil {
+		return err
+	}
+	if err := s.domainRepo.DeleteByForm(ctx, formId); err != nil {
+		return err
+	}
+
+	if existing != nil {
+		s.audit.Record(ctx, &AuditEvent{
+			TeamID:       form.TeamID,
+			ResourceType: models.AuditResourceDomain,
+			ResourceID:   existing.ID,
+			Action:       models.AuditActionDelete,
+			Before:       existing,
+		})
+	}
+	return nil
+}
+
+func (s *DomainService) checkAccess(ctx context.Context, userId string, formId string, permission Permission) (*models.Form, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, permission); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// checkChallenge returns why the challenge failed, or an empty string when it passed
+func (s *DomainService) checkChallenge(ctx context.Context, customDomain *models.CustomDomain) string {
+
+	lookupCtx, cancel := context.WithTimeout(ctx, domainLookupTimeout)
+	defer cancel()
+
+	name := domainChallengePrefix + "." + customDomain.Domain
+	records, err := s.resolver.LookupTXT(lookupCtx, name)
+	if err != nil {
+		return "TXT record " + name + " could not be found"
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == domainChallengeValue+customDomain.Token {
+			return ""
+		}
+	}
+	return "TXT record " + name + " does not contain the verification token"
+}
+
+// cnameConfigured reports whether the domain already routes to the form host. It is
+// not needed to verify ownership, but the form is unreachable on the domain without it.
+func (s *DomainService) cnameConfigured(ctx context.Context, domain string) bool {
+
+	lookupCtx, cancel := context.WithTimeout(ctx, domainLookupTimeout)
+	defer cancel()
+
+	target, err := s.resolver.LookupCNAME(lookupCtx, domain)
+	if err != nil {
+		return false
+	}
+	return normalizeDomain(target) == normalizeDomain(s.cfg.Domains.CNAMETarget)
+}
+
+func (s *DomainService) toResponse(customDomain *models.CustomDomain, cnameConfigured bool) *types.CustomDomainResponse {
+	return &types.CustomDomainResponse{
+		Domain: customDomain.Domain,
+		Status: string(customDomain.Status),
+		Records: []*types.DNSRecord{
+			{
+				Type:    "TXT",
+				Name:    domainChallengePrefix + "." + customDomain.Domain,
+				Value:   domainChallengeValue + customDomain.Token,
+				Purpose: "verification",
+			},
+			{
+				Type:    "CNAME",
+				Name:    customDomain.Domain,
+				Value:   s.cfg.Domains.CNAMETarget,
+				Purpose: "routing",
+			},
+		},
+		CNAMEConfigured: cnameConfigured,
+		LastError:       customDomain.LastError,
+		LastCheckedAt:   utils.GetIsoDateTime(customDomain.LastCheckedAt),
+		VerifiedAt:      utils.GetIsoDateTime(customDomain.VerifiedAt),
+	}
+}
+
+// normalizeDomain lowercases a host name and strips a scheme, port, path and trailing dot
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	if i := strings.LastIndex(domain, ":"); i >= 0 {
+		domain = domain[:i]
+	}
+	return strings.TrimSuffix(domain, ".")
+}
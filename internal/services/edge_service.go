@@ -19,14 +19,18 @@ type IEdgeService interface {
 }
 
 type EdgeService struct {
-	edgeRepo repositories.IEdgeRepo
-	formRepo repositories.IFormRepo
+	edgeRepo    repositories.IEdgeRepo
+	formRepo    repositories.IFormRepo
+	permissions IPermissionService
+	audit       IAuditService
 }
 
-func NewEdgeService(edgeRepo repositories.IEdgeRepo, formRepo repositories.IFormRepo) *EdgeService {
+func NewEdgeService(edgeRepo repositories.IEdgeRepo, formRepo repositories.IFormRepo, permissions IPermissionService, audit IAuditService) *EdgeService {
 	return &EdgeService{
-		edgeRepo: edgeRepo,
-		formRepo: formRepo,
+		edgeRepo:    edgeRepo,
+		formRepo:    formRepo,
+		permissions: permissions,
+		audit:       audit,
 	}
 }
 
@@ -41,8 +45,8 @@ func (s *EdgeService) Get(ctx context.Context, userId string, formId string) ([]
 		return nil, errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionView); err != nil {
+		return nil, err
 	}
 
 	edges, err := s.edgeRepo.Get(ctx, formId)
@@ -65,8 +69,8 @@ func (s *EdgeService) Create(ctx context.Context, userId string, formId string,
 	if form == nil {
 		return nil, errors.NotFound("Form")
 	}
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
 	}
 	newEdge := &models.Edge{
 		ID:           utils.GenerateUUID(),
@@ -79,6 +83,14 @@ func (s *EdgeService) Create(ctx context.Context, userId string, formId string,
 	if err != nil {
 		return nil, err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceEdge,
+		ResourceID:   createdEdge.ID,
+		Action:       models.AuditActionCreate,
+		After:        createdEdge,
+	})
 	return mapper.ToEdgeResponse(createdEdge), nil
 }
 
@@ -91,8 +103,8 @@ func (s *EdgeService) Update(ctx context.Context, userId string, formId string,
 	if form == nil {
 		return errors.NotFound("Form")
 	}
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 
 	edgeModel := &models.Edge{
@@ -104,6 +116,14 @@ func (s *EdgeService) Update(ctx context.Context, userId string, formId string,
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceEdge,
+		ResourceID:   edgeId,
+		Action:       models.AuditActionUpdate,
+		After:        map[string]interface{}{"condition": edge.Condition},
+	})
 	return nil
 }
 
@@ -116,12 +136,19 @@ func (s *EdgeService) Delete(ctx context.Context, userId string, formId string,
 	if form == nil {
 		return errors.NotFound("Form")
 	}
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 	err = s.edgeRepo.Delete(ctx, edgeId)
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceEdge,
+		ResourceID:   edgeId,
+		Action:       models.AuditActionDelete,
+	})
 	return nil
 }
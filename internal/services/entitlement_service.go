@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// Limit is a plan allowance that creating things uses up
+type Limit string
+
+const (
+	LimitProjects Limit = "projects"
+	LimitForms    Limit = "forms"
+	// LimitMembers counts members and pending invitations, which hold a seat
+	LimitMembers Limit = "members"
+	// LimitSubmissions counts responses submitted in the current billing cycle
+	LimitSubmissions Limit = "submissions"
+	// LimitStorage counts the bytes uploaded to the team's forms, metered from usage events
+	LimitStorage Limit = "storage"
+	// LimitTokens counts the AI tokens used in the current billing cycle
+	LimitTokens Limit = "tokens"
+)
+
+// reportedLimits is every limit, in the order usage is reported
+var reportedLimits = []Limit{LimitProjects, LimitForms, LimitMembers, LimitSubmissions, LimitStorage, LimitTokens}
+
+// storageUnit is the unit of Plan.MaxFileStorage and storage add-ons: a gigabyte
+const storageUnit = 1 << 30
+
+type IEntitlementService interface {
+	Check(ctx context.Context, teamId string, limit Limit, adding int64) error
+	CheckTeam(ctx context.Context, team *models.Team, limit Limit, adding int64) error
+	Report(ctx context.Context, team *models.Team) (*types.UsageResponse, error)
+	Usage(ctx context.Context, team *models.Team, limit Limit) (int64, error)
+	OverLimit(ctx context.Context, team *models.Team) (bool, error)
+}
+
+// EntitlementService enforces the limits of a team's plan and add-ons
+type EntitlementService struct {
+	teamRepo       repositories.ITeamRepo
+	projectRepo    repositories.IProjectRepo
+	formRepo       repositories.IFormRepo
+	memberRepo     repositories.IMemberRepo
+	invitationRepo repositories.IInvitationRepo
+	responseRepo   repositories.IResponseRepo
+	usageRepo      repositories.IUsageRepo
+	cfg            *config.Config
+}
+
+func NewEntitlementService(teamRepo repositories.ITeamRepo, projectRepo repositories.IProjectRepo, formRepo repositories.IFormRepo, memberRepo repositories.IMemberRepo, invitationRepo repositories.IInvitationRepo, responseRepo repositories.IResponseRepo, usageRepo repositories.IUsageRepo, cfg *config.Config) *EntitlementService {
+	return &EntitlementService{
+		teamRepo:       teamRepo,
+		projectRepo:    projectRepo,
+		formRepo:       formRepo,
+		memberRepo:     memberRepo,
+		invitationRepo: invitationRepo,
+		responseRepo:   responseRepo,
+		usageRepo:      usageRepo,
+		cfg:            cfg,
+	}
+}
+
+// Check fails with 402 when adding to the team's usage would go over the limit, or when
+// the team is blocked. Teams without a plan, or plans without a cap on the limit, are
+// not limited.
+func (s *EntitlementService) Check(ctx context.Context, teamId string, limit Limit, adding int64) error {
+
+	team, err := s.teamRepo.GetWithPlan(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return errors.NotFound("Team")
+	}
+	return s.CheckTeam(ctx, team, limit, adding)
+}
+
+// CheckTeam is Check for a team already loaded with its plan, subscription and
+// add-ons, such as a team in the trash
+func (s *EntitlementService) CheckTeam(ctx context.Context, team *models.Team, limit Limit, adding int64) error {
+
+	if team.TeamUsage != nil && team.TeamUsage.Status == models.TeamSubscriptionStatusBlocked {
+		return teamBlockedError()
+	}
+
+	max, limited := effectiveLimits(team)[limit]
+	if !limited {
+		return nil
+	}
+
+	current, err := s.Usage(ctx, team, limit)
+	if err != nil {
+		return err
+	}
+	if current+adding > max {
+		return errors.PaymentRequired(limitMessage(limit, max), &types.LimitDetails{
+			Limit:   string(limit),
+			Current: current,
+			Max:     max,
+		})
+	}
+	return nil
+}
+
+// Report is the team's usage of every limit. The team must be loaded with its plan.
+func (s *EntitlementService) Report(ctx context.Context, team *models.Team) (*types.UsageResponse, error) {
+
+	now := time.Now()
+	start := cycleStart(team, now)
+	end := cycleEnd(team, now)
+	effective := effectiveLimits(team)
+
+	resp := &types.UsageResponse{
+		CycleStart: utils.GetIsoDateTime(&start),
+		CycleEnd:   utils.GetIsoDateTime(&end),
+		Limits:     make([]*types.LimitUsage, len(reportedLimits)),
+	}
+	for i, limit := range reportedLimits {
+		current, err := s.Usage(ctx, team, limit)
+		if err != nil {
+			return nil, err
+		}
+		usage := &types.LimitUsage{Limit: string(limit), Current: current}
+		if max, limited := effective[limit]; limited {
+			usage.Max = &max
+		}
+		resp.Limits[i] = usage
+	}
+	return resp, nil
+}
+
+// OverLimit reports whether the team uses more of any capped limit than it is allowed,
+// as it may after a downgrade
+func (s *EntitlementService) OverLimit(ctx context.Context, team *models.Team) (bool, error) {
+
+	for limit, max := range effectiveLimits(team) {
+		current, err := s.Usage(ctx, team, limit)
+		if err != nil {
+			return false, err
+		}
+		if current > max {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Usage is how much of the limit the team currently uses
+func (s *EntitlementService) Usage(ctx context.Context, team *models.Team, limit Limit) (int64, error) {
+
+	switch limit {
+	case LimitProjects:
+		return s.projectRepo.CountByTeam(ctx, team.ID)
+	case LimitForms:
+		return s.formRepo.CountByTeam(ctx, team.ID)
+	case LimitMembers:
+		seats, err := s.memberRepo.CountSeats(ctx, team)
+		if err != nil {
+			return 0, err
+		}
+		pending, err := s.invitationRepo.CountPending(ctx, team.ID, time.Now())
+		if err != nil {
+			return 0, err
+		}
+		return seats + pending, nil
+	case LimitSubmissions:
+		return s.responseRepo.CountSubmittedByTeam(ctx, team.ID, cycleStart(team, time.Now()))
+	case LimitStorage:
+		return s.usageRepo.SumStorage(ctx, team.ID)
+	case LimitTokens:
+		now := time.Now()
+		totals, err := s.usageRepo.SumEvents(ctx, team.ID, cycleStart(team, now), cycleEnd(team, now))
+		if err != nil {
+			return 0, err
+		}
+		return totals[models.UsageEventAITokens], nil
+	}
+	return 0, errors.Internal(fmt.Errorf("unknown limit %q", limit))
+}
+
+// effectiveLimits are the plan's caps raised by the team's add-ons. An add-on whose key
+// names a limit adds its quantity to it, in gigabytes for storage. A limit missing
+// from the result is unlimited, and add-ons do not change that.
+func effectiveLimits(team *models.Team) map[Limit]int64 {
+
+	limits := map[Limit]int64{}
+	plan := team.Plan
+	if plan == nil {
+		return limits
+	}
+	if plan.MaxProjects != nil {
+		limits[LimitProjects] = int64(*plan.MaxProjects)
+	}
+	if plan.MaxForms != nil {
+		limits[LimitForms] = int64(*plan.MaxForms)
+	}
+	if plan.MaxTeamMembers != nil {
+		limits[LimitMembers] = int64(*plan.MaxTeamMembers)
+	}
+	if plan.MaxSubmission != nil {
+		limits[LimitSubmissions] = int64(*plan.MaxSubmission)
+	}
+	if plan.MaxFileStorage != nil {
+		limits[LimitStorage] = int64(*plan.MaxFileStorage * storageUnit)
+	}
+	if plan.MaxTokens != nil {
+		limits[LimitTokens] = int64(*plan.MaxTokens)
+	}
+
+	for _, teamAddon := range team.TeamAddons {
+		if !teamAddon.AddOn.Valid {
+			continue
+		}
+		limit := Limit(teamAddon.AddOn.Key)
+		max, limited := limits[limit]
+		if !limited {
+			continue
+		}
+		extra := int64(teamAddon.Quantity)
+		if limit == LimitStorage {
+			extra *= storageUnit
+		}
+		limits[limit] = max + extra
+	}
+	return limits
+}
+
+// cycleStart is when the team's current billing cycle began. Teams without a
+// subscription are counted by calendar month.
+func cycleStart(team *models.Team, now time.Time) time.Time {
+	if team.TeamUsage != nil && !team.TeamUsage.CycleStart.IsZero() {
+		return team.TeamUsage.CycleStart
+	}
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// cycleEnd is when the team's current billing cycle ends
+func cycleEnd(team *models.Team, now time.Time) time.Time {
+	if team.TeamUsage != nil && !team.TeamUsage.CycleEnd.IsZero() {
+		return team.TeamUsage.CycleEnd
+	}
+	return cycleStart(team, now).AddDate(0, 1, 0)
+}
+
+func limitMessage(limit Limit, max int64) string {
+	switch limit {
+	case LimitMembers:
+		return fmt.Sprintf("The team's plan allows at most %d members, including pending invitations", max)
+	case LimitSubmissions:
+		return fmt.Sprintf("The team's plan allows at most %d submissions per billing cycle", max)
+	case LimitTokens:
+		return fmt.Sprintf("The team's plan allows at most %d AI tokens per billing cycle", max)
+	case LimitStorage:
+		return "The team's plan allows at most " + strconv.FormatFloat(float64(max)/storageUnit, 'f', -1, 64) + " GB of file storage"
+	}
+	return fmt.Sprintf("The team's plan allows at most %d %s", max, limit)
+}
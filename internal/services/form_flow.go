@@ -0,0 +1,412 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+)
+
+// Edge conditions are JSON objects of the form
+//
+//	{"field": "<questionId>", "operator": "eq", "value": "yes"}
+//	{"all": [<condition>, ...]}
+//	{"any": [<condition>, ...]}
+//
+// A field prefixed with "h:" reads a hidden field and "v:" a computed variable
+// instead of an answer.
+// An empty or missing condition always matches.
+const (
+	conditionOpEquals      = "eq"
+	conditionOpNotEquals   = "neq"
+	conditionOpGreater     = "gt"
+	conditionOpGreaterEq   = "gte"
+	conditionOpLess        = "lt"
+	conditionOpLessEq      = "lte"
+	conditionOpContains    = "contains"
+	conditionOpNotContains = "not_contains"
+	conditionOpIn          = "in"
+	conditionOpEmpty       = "empty"
+	conditionOpNotEmpty    = "not_empty"
+)
+
+// flowState holds everything known about a respondent while walking the form
+type flowState struct {
+	answers   map[string]interface{}
+	hidden    map[string]string
+	variables map[string]float64
+}
+
+// lookup resolves a condition or placeholder reference to its current value
+func (st *flowState) lookup(ref string) (interface{}, bool) {
+	if name, ok := strings.CutPrefix(ref, "h:"); ok {
+		value, present := st.hidden[name]
+		return value, present
+	}
+	if name, ok := strings.CutPrefix(ref, "v:"); ok {
+		value, present := st.variables[name]
+		return value, present
+	}
+	value, present := st.answers[ref]
+	return value, present
+}
+
+// formFlow is the navigation graph of a published form. A question without an
+// unconditional outgoing edge falls through to the next question by sort order,
+// so forms without any edges flow linearly.
+type formFlow struct {
+	questions []*types.PublishedQuestion
+	byId      map[string]*types.PublishedQuestion
+	index     map[string]int
+	outgoing  map[string][]types.PublishedEdge
+	variables []*types.FormVariable
+	hidden    []types.HiddenField
+}
+
+func newFormFlow(snapshot *types.PublishVersionSnapshot) *formFlow {
+	flow := &formFlow{
+		questions: make([]*types.PublishedQuestion, len(snapshot.Questions)),
+		byId:      make(map[string]*types.PublishedQuestion, len(snapshot.Questions)),
+		index:     make(map[string]int, len(snapshot.Questions)),
+		outgoing:  make(map[string][]types.PublishedEdge),
+		hidden:    snapshot.HiddenFields,
+	}
+
+	// Metadata is validated when variables are saved, so a decode error only means there are none
+	flow.variables, _ = readFormVariables(snapshot.Metadata)
+
+	for i := range snapshot.Questions {
+		flow.questions[i] = &snapshot.Questions[i]
+	}
+	sort.SliceStable(flow.questions, func(i, j int) bool {
+		return publishedSortOrder(flow.questions[i]) < publishedSortOrder(flow.questions[j])
+	})
+	for i, question := range flow.questions {
+		flow.byId[question.ID] = question
+		flow.index[question.ID] = i
+	}
+
+	for _, edge := range snapshot.Edges {
+		if flow.byId[edge.SourceNodeID] == nil || flow.byId[edge.TargetNodeID] == nil {
+			continue
+		}
+		flow.outgoing[edge.SourceNodeID] = append(flow.outgoing[edge.SourceNodeID], edge)
+	}
+	return flow
+}
+
+// newState builds the state for a set of normalized answers and computes the form variables from them
+func (f *formFlow) newState(answers map[string]interface{}, hidden map[string]string) *flowState {
+	return &flowState{
+		answers:   answers,
+		hidden:    hidden,
+		variables: computeVariables(f, answers),
+	}
+}
+
+func (f *formFlow) start() *types.PublishedQuestion {
+	if len(f.questions) == 0 {
+		return nil
+	}
+	return f.questions[0]
+}
+
+func (f *formFlow) isTerminal(question *types.PublishedQuestion) bool {
+	switch models.QuestionType(question.Type) {
+	case models.QuestionTypeScreenEnd, models.QuestionTypeRedirectToUrl:
+		return true
+	}
+	return false
+}
+
+func (f *formFlow) linearNext(questionId string) *types.PublishedQuestion {
+	i, ok := f.index[questionId]
+	if !ok || i+1 >= len(f.questions) {
+		return nil
+	}
+	return f.questions[i+1]
+}
+
+// successors returns every question that can follow questionId, whatever the answers
+func (f *formFlow) successors(questionId string) []string {
+	question := f.byId[questionId]
+	if question == nil || f.isTerminal(question) {
+		return nil
+	}
+
+	var result []string
+	fallthroughNext := true
+	for _, edge := range f.outgoing[questionId] {
+		result = append(result, edge.TargetNodeID)
+		if isEmptyCondition(edge.Condition) {
+			fallthroughNext = false
+		}
+	}
+	if fallthroughNext {
+		if next := f.linearNext(questionId); next != nil {
+			result = append(result, next.ID)
+		}
+	}
+	return result
+}
+
+// next returns the question shown after questionId for the given state, or nil when the form ends.
+// Conditional edges are tried first in order, then the unconditional edge, then the next question.
+func (f *formFlow) next(questionId string, state *flowState) *types.PublishedQuestion {
+	question := f.byId[questionId]
+	if question == nil || f.isTerminal(question) {
+		return nil
+	}
+
+	var fallback *types.PublishedEdge
+	for i, edge := range f.outgoing[questionId] {
+		if isEmptyCondition(edge.Condition) {
+			if fallback == nil {
+				fallback = &f.outgoing[questionId][i]
+			}
+			continue
+		}
+		if evaluateCondition(edge.Condition, state) {
+			return f.byId[edge.TargetNodeID]
+		}
+	}
+	if fallback != nil {
+		return f.byId[fallback.TargetNodeID]
+	}
+	return f.linearNext(questionId)
+}
+
+// path walks the form from the start and returns the questions the respondent sees
+func (f *formFlow) path(state *flowState) []*types.PublishedQuestion {
+	var result []*types.PublishedQuestion
+	visited := make(map[string]bool)
+	for question := f.start(); question != nil && !visited[question.ID]; question = f.next(question.ID, state) {
+		visited[question.ID] = true
+		result = append(result, question)
+	}
+	return result
+}
+
+// dominators returns, for every question reachable from the start, the set of
+// questions that lie on every path from the start to it (including itself)
+func (f *formFlow) dominators() map[string]map[string]bool {
+	start := f.start()
+	if start == nil {
+		return nil
+	}
+
+	reachable := []string{start.ID}
+	seen := map[string]bool{start.ID: true}
+	preds := make(map[string][]string)
+	for i := 0; i < len(reachable); i++ {
+		for _, succ := range f.successors(reachable[i]) {
+			preds[succ] = append(preds[succ], reachable[i])
+			if !seen[succ] {
+				seen[succ] = true
+				reachable = append(reachable, succ)
+			}
+		}
+	}
+
+	dom := make(map[string]map[string]bool, len(reachable))
+	dom[start.ID] = map[string]bool{start.ID: true}
+	for _, id := range reachable[1:] {
+		dom[id] = make(map[string]bool, len(reachable))
+		for _, other := range reachable {
+			dom[id][other] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, id := range reachable[1:] {
+			updated := map[string]bool{id: true}
+			for candidate := range dom[id] {
+				if candidate == id {
+					continue
+				}
+				inAll := len(preds[id]) > 0
+				for _, pred := range preds[id] {
+					if !dom[pred][candidate] {
+						inAll = false
+						break
+					}
+				}
+				if inAll {
+					updated[candidate] = true
+				}
+			}
+			if len(updated) != len(dom[id]) {
+				dom[id] = updated
+				changed = true
+			}
+		}
+	}
+	return dom
+}
+
+func isEmptyCondition(condition interface{}) bool {
+	if condition == nil {
+		return true
+	}
+	if m, ok := condition.(map[string]interface{}); ok && len(m) == 0 {
+		return true
+	}
+	return false
+}
+
+// evaluateCondition reports whether an edge condition matches the state. Malformed conditions never match.
+func evaluateCondition(condition interface{}, state *flowState) bool {
+	if isEmptyCondition(condition) {
+		return true
+	}
+
+	node, ok := condition.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if group, ok := node["all"].([]interface{}); ok {
+		for _, child := range group {
+			if !evaluateCondition(child, state) {
+				return false
+			}
+		}
+		return len(group) > 0
+	}
+	if group, ok := node["any"].([]interface{}); ok {
+		for _, child := range group {
+			if evaluateCondition(child, state) {
+				return true
+			}
+		}
+		return false
+	}
+
+	field, _ := node["field"].(string)
+	operator, _ := node["operator"].(string)
+	if field == "" || operator == "" {
+		return false
+	}
+
+	actual, present := state.lookup(field)
+	expected := node["value"]
+
+	switch operator {
+	case conditionOpEmpty:
+		return !present || isEmptyAnswer(actual)
+	case conditionOpNotEmpty:
+		return present && !isEmptyAnswer(actual)
+	}
+	if !present {
+		return operator == conditionOpNotEquals || operator == conditionOpNotContains
+	}
+
+	switch operator {
+	case conditionOpEquals:
+		return conditionEquals(actual, expected)
+	case conditionOpNotEquals:
+		return !conditionEquals(actual, expected)
+	case conditionOpGreater, conditionOpGreaterEq, conditionOpLess, conditionOpLessEq:
+		cmp, ok := conditionCompare(actual, expected)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case conditionOpGreater:
+			return cmp > 0
+		case conditionOpGreaterEq:
+			return cmp >= 0
+		case conditionOpLess:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	case conditionOpContains:
+		return conditionContains(actual, expected)
+	case conditionOpNotContains:
+		return !conditionContains(actual, expected)
+	case conditionOpIn:
+		list, ok := expected.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range list {
+			if conditionEquals(actual, item) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func conditionEquals(actual interface{}, expected interface{}) bool {
+	if a, ok := toNumber(actual); ok {
+		if b, ok := toNumber(expected); ok {
+			return a == b
+		}
+	}
+	if a, ok := actual.(bool); ok {
+		b, ok := expected.(bool)
+		return ok && a == b
+	}
+	return strings.EqualFold(fmt.Sprint(actual), fmt.Sprint(expected))
+}
+
+// conditionCompare orders numbers numerically and anything else, such as
+// YYYY-MM-DD dates, lexically
+func conditionCompare(actual interface{}, expected interface{}) (int, bool) {
+	if a, ok := toNumber(actual); ok {
+		b, ok := toNumber(expected)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	a, ok := actual.(string)
+	if !ok {
+		return 0, false
+	}
+	b, ok := expected.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(a, b), true
+}
+
+func conditionContains(actual interface{}, expected interface{}) bool {
+	switch v := actual.(type) {
+	case []string:
+		for _, item := range v {
+			if conditionEquals(item, expected) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if conditionEquals(item, expected) {
+				return true
+			}
+		}
+	case string:
+		return strings.Contains(strings.ToLower(v), strings.ToLower(fmt.Sprint(expected)))
+	}
+	return false
+}
+
+func publishedSortOrder(question *types.PublishedQuestion) int {
+	if question.SortOrder == nil {
+		return 0
+	}
+	return *question.SortOrder
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+)
+
+// testFlow builds a flow of questions q1..qN in that order, with the given types by ID
+// and edges written as source, target and whether the edge has a condition
+func testFlow(count int, questionTypes map[string]models.QuestionType, edges ...[3]interface{}) *formFlow {
+	snapshot := &types.PublishVersionSnapshot{}
+	for i := 1; i <= count; i++ {
+		id := "q" + strconv.Itoa(i)
+		order := i
+		questionType := models.QuestionTypeTextShort
+		if t, ok := questionTypes[id]; ok {
+			questionType = t
+		}
+		snapshot.Questions = append(snapshot.Questions, types.PublishedQuestion{ID: id, Type: string(questionType), SortOrder: &order})
+	}
+	for i, edge := range edges {
+		var condition interface{}
+		if edge[2].(bool) {
+			condition = map[string]interface{}{"field": edge[0], "operator": "eq", "value": "yes"}
+		}
+		snapshot.Edges = append(snapshot.Edges, types.PublishedEdge{
+			ID:           "e" + strconv.Itoa(i),
+			SourceNodeID: edge[0].(string),
+			TargetNodeID: edge[1].(string),
+			Condition:    condition,
+		})
+	}
+	return newFormFlow(snapshot)
+}
+
+func TestDominators(t *testing.T) {
+	tests := []struct {
+		name string
+		flow *formFlow
+		want map[string][]string
+	}{
+		{
+			name: "linear",
+			flow: testFlow(3, nil),
+			want: map[string][]string{"q1": {"q1"}, "q2": {"q1", "q2"}, "q3": {"q1", "q2", "q3"}},
+		},
+		{
+			name: "branch that merges again",
+			flow: testFlow(4, nil, [3]interface{}{"q1", "q3", true}),
+			want: map[string][]string{"q1": {"q1"}, "q2": {"q1", "q2"}, "q3": {"q1", "q3"}, "q4": {"q1", "q3", "q4"}},
+		},
+		{
+			name: "unconditional skip leaves a question unreachable",
+			flow: testFlow(3, nil, [3]interface{}{"q1", "q3", false}),
+			want: map[string][]string{"q1": {"q1"}, "q3": {"q1", "q3"}},
+		},
+		{
+			name: "end screen stops the flow",
+			flow: testFlow(3, map[string]models.QuestionType{"q2": models.QuestionTypeScreenEnd}),
+			want: map[string][]string{"q1": {"q1"}, "q2": {"q1", "q2"}},
+		},
+		{
+			name: "loop back to the start",
+			flow: testFlow(3, nil, [3]interface{}{"q2", "q1", true}),
+			want: map[string][]string{"q1": {"q1"}, "q2": {"q1", "q2"}, "q3": {"q1", "q2", "q3"}},
+		},
+		{
+			name: "diamond",
+			flow: testFlow(4, nil,
+				[3]interface{}{"q1", "q3", true},
+				[3]interface{}{"q1", "q2", false},
+				[3]interface{}{"q2", "q4", false},
+			),
+			want: map[string][]string{"q1": {"q1"}, "q2": {"q1", "q2"}, "q3": {"q1", "q3"}, "q4": {"q1", "q4"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string][]string)
+			for id, set := range tt.flow.dominators() {
+				for dominator := range set {
+					got[id] = append(got[id], dominator)
+				}
+				sort.Strings(got[id])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := testFlow(0, nil).dominators(); got != nil {
+		t.Errorf("empty form: got %v, want nil", got)
+	}
+}
+
+func TestFlowNext(t *testing.T) {
+	flow := testFlow(4, nil,
+		[3]interface{}{"q1", "q3", true},
+		[3]interface{}{"q3", "q1", false},
+	)
+
+	tests := []struct {
+		name     string
+		from     string
+		answers  map[string]interface{}
+		wantNext string
+	}{
+		{"condition met", "q1", map[string]interface{}{"q1": "yes"}, "q3"},
+		{"condition not met falls through", "q1", map[string]interface{}{"q1": "no"}, "q2"},
+		{"no edges goes to the next question", "q2", nil, "q3"},
+		{"unconditional edge wins over the next question", "q3", nil, "q1"},
+		{"last question ends the form", "q4", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &flowState{answers: tt.answers}
+			got := ""
+			if next := flow.next(tt.from, state); next != nil {
+				got = next.ID
+			}
+			if got != tt.wantNext {
+				t.Errorf("got %q, want %q", got, tt.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/mapper"
+)
+
+// Move re-parents a form to another project, possibly in another team the user owns.
+// Responses, versions and uploads are keyed by the form ID, so they move with it, and
+// the storage metered for the uploads moves to the new team.
+func (s *FormService) Move(ctx context.Context, userId string, formId string, body *types.MoveFormRequest) (*types.FormResponse, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionManage); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.GetWithTeam(ctx, body.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.NotFound("Project")
+	}
+	if err := s.permissions.Check(ctx, userId, &project.Team, PermissionManage); err != nil {
+		return nil, err
+	}
+
+	if project.ID != form.ProjectID {
+		if project.TeamID != form.TeamID {
+			if err := s.entitlements.Check(ctx, project.TeamID, LimitForms, 1); err != nil {
+				return nil, err
+			}
+			// The form's uploads count towards the storage of the team it moves to
+			size, err := s.usage.FormStorage(ctx, form.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.entitlements.Check(ctx, project.TeamID, LimitStorage, size); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.formRepo.Move(ctx, form.ID, project.ID, project.TeamID); err != nil {
+			return nil, err
+		}
+
+		// Recorded in both teams, so that each sees the form leave or arrive
+		moved := map[string]interface{}{"projectId": project.ID, "teamId": project.TeamID}
+		s.audit.Record(ctx, &AuditEvent{
+			TeamID:       form.TeamID,
+			ResourceType: models.AuditResourceForm,
+			ResourceID:   form.ID,
+			Action:       models.AuditActionMove,
+			Before:       form,
+			After:        moved,
+		})
+		if project.TeamID != form.TeamID {
+			s.audit.Record(ctx, &AuditEvent{
+				TeamID:       project.TeamID,
+				ResourceType: models.AuditResourceForm,
+				ResourceID:   form.ID,
+				Action:       models.AuditActionMove,
+				Before:       form,
+				After:        moved,
+			})
+		}
+	}
+
+	moved, err := s.formRepo.GetById(ctx, form.ID)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.ToFormResponse(moved), nil
+}
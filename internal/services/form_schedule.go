@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// SchedulePublish snapshots the editor state of the form now and publishes that snapshot
+// at runAt, so that edits made in the meantime are not published with it
+func (s *FormService) SchedulePublish(ctx context.Context, userId string, formId string, body *types.SchedulePublishRequest) (*models.ScheduledJob, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
+	}
+
+	runAt, err := time.Parse(time.RFC3339, body.RunAt)
+	if err != nil {
+		return nil, errors.BadRequest("runAt must be an RFC 3339 date-time")
+	}
+	if !runAt.After(time.Now()) {
+		return nil, errors.BadRequest("runAt must be in the future")
+	}
+	if err := checkTransition(formStatusOf(form), models.FormStatusPublished); err != nil {
+		return nil, err
+	}
+
+	version, err := s.createVersion(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	revision := editorRevisionOf(form)
+
+	job, err := s.jobRepo.Schedule(ctx, &models.ScheduledJob{
+		ID:        utils.GenerateUUID(),
+		FormID:    formId,
+		Type:      models.ScheduledJobPublish,
+		Status:    models.ScheduledJobStatusPending,
+		RunAt:     runAt,
+		VersionID: &version.ID,
+		Revision:  &revision,
+		CreatedBy: userId,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceScheduledJob,
+		ResourceID:   job.ID,
+		Action:       models.AuditActionSchedule,
+		After:        job,
+	})
+	return job, nil
+}
+
+func (s *FormService) GetScheduledJobs(ctx context.Context, userId string, formId string) ([]*models.ScheduledJob, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.jobRepo.GetByForm(ctx, formId)
+}
+
+func (s *FormService) CancelScheduledJob(ctx context.Context, userId string, formId string, jobId string) error {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
+	}
+
+	job, err := s.jobRepo.GetById(ctx, jobId)
+	if err != nil {
+		return err
+	}
+	if job == nil || job.FormID != formId {
+		return errors.NotFound("Scheduled job")
+	}
+	if job.Status != models.ScheduledJobStatusPending {
+		return errors.BadRequest("Only pending jobs can be cancelled")
+	}
+	if err := s.jobRepo.Cancel(ctx, formId, job.Type); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceScheduledJob,
+		ResourceID:   job.ID,
+		Action:       models.AuditActionCancel,
+		Before:       job,
+	})
+	return nil
+}
+
+// RunScheduledJob applies a due job. Jobs are safe to run twice: a form that already
+// has the target status is left alone.
+func (s *FormService) RunScheduledJob(ctx context.Context, job *models.ScheduledJob) error {
+
+	form, err := s.formRepo.GetById(ctx, job.FormID)
+	if err != nil {
+		return err
+	}
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+
+	status := formStatusOf(form)
+	switch job.Type {
+	case models.ScheduledJobPublish:
+		if job.VersionID == nil || job.Revision == nil {
+			return fmt.Errorf("publish job %s has no version", job.ID)
+		}
+		if status == models.FormStatusPublished && form.PublishedVersionID != nil && *form.PublishedVersionID == *job.VersionID {
+			return nil
+		}
+		version, err := s.formVersionRepo.GetByID(ctx, *job.VersionID)
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			return errors.NotFound("Form version")
+		}
+		return s.promoteVersion(ctx, form, version, *job.Revision, systemActor)
+	case models.ScheduledJobOpen:
+		if status == models.FormStatusPublished {
+			return nil
+		}
+		return s.transition(ctx, form, models.FormStatusPublished, systemActor, nil, nil)
+	case models.ScheduledJobClose:
+		if status != models.FormStatusPublished {
+			return nil
+		}
+		return s.transition(ctx, form, models.FormStatusClosed, systemActor, nil, nil)
+	}
+	return fmt.Errorf("unknown job type %s", job.Type)
+}
+
+// syncFormSchedule keeps the OPEN and CLOSE jobs of a form in line with its openAt and
+// closeAt. A past openAt is ignored so that a closed form is not reopened, while a past
+// closeAt closes the form right away.
+func syncFormSchedule(ctx context.Context, jobRepo repositories.IJobRepo, formId string, userId string, openAt *time.Time, closeAt *time.Time) error {
+
+	now := time.Now()
+	if openAt == nil || !openAt.After(now) {
+		if err := jobRepo.Cancel(ctx, formId, models.ScheduledJobOpen); err != nil {
+			return err
+		}
+	} else {
+		_, err := jobRepo.Schedule(ctx, &models.ScheduledJob{
+			ID:        utils.GenerateUUID(),
+			FormID:    formId,
+			Type:      models.ScheduledJobOpen,
+			Status:    models.ScheduledJobStatusPending,
+			RunAt:     *openAt,
+			CreatedBy: userId,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if closeAt == nil {
+		return jobRepo.Cancel(ctx, formId, models.ScheduledJobClose)
+	}
+	runAt := *closeAt
+	if runAt.Before(now) {
+		runAt = now
+	}
+	_, err := jobRepo.Schedule(ctx, &models.ScheduledJob{
+		ID:        utils.GenerateUUID(),
+		FormID:    formId,
+		Type:      models.ScheduledJobClose,
+		Status:    models.ScheduledJobStatusPending,
+		RunAt:     runAt,
+		CreatedBy: userId,
+		CreatedAt: now,
+	})
+	return err
+}
@@ -22,28 +22,53 @@ type IFormService interface {
 	Update(ctx context.Context, userId string, formId string, updates map[string]interface{}) error
 	UpdateStatus(ctx context.Context, userId string, formId string, status models.FormStatus) error
 	Delete(ctx context.Context, userId string, formId string) error
+	Move(ctx context.Context, userId string, formId string, body *types.MoveFormRequest) (*types.FormResponse, error)
 
 	UpdateSequence(ctx context.Context, userId string, formId string, sequences []*types.SequenceItem) error
 	Demo(ctx context.Context, userId string) (string, error)
-	Publish(ctx context.Context, formId string) (*types.FormResponse, error)
-	Unpublish(ctx context.Context, formId string) (*types.FormResponse, error)
+	Publish(ctx context.Context, userId string, formId string) (*types.FormResponse, error)
+	Unpublish(ctx context.Context, userId string, formId string) (*types.FormResponse, error)
+	GetStatusHistory(ctx context.Context, userId string, formId string) ([]*models.FormStatusTransition, error)
+	SchedulePublish(ctx context.Context, userId string, formId string, body *types.SchedulePublishRequest) (*models.ScheduledJob, error)
+	GetScheduledJobs(ctx context.Context, userId string, formId string) ([]*models.ScheduledJob, error)
+	CancelScheduledJob(ctx context.Context, userId string, formId string, jobId string) error
+
+	UpdateVariables(ctx context.Context, userId string, formId string, body *types.UpdateVariablesRequest) error
+	UpdateQuiz(ctx context.Context, userId string, formId string, body *types.QuizSettings) error
+	UpdateHiddenFields(ctx context.Context, userId string, formId string, body *types.UpdateHiddenFieldsRequest) error
+	CheckSlug(ctx context.Context, userId string, formId string, slug string) (*types.SlugAvailabilityResponse, error)
+	UpdateSlug(ctx context.Context, userId string, formId string, body *types.UpdateSlugRequest) (*types.FormResponse, error)
 }
 
 type FormService struct {
 	formRepo        repositories.IFormRepo
 	projectRepo     repositories.IProjectRepo
+	teamRepo        repositories.ITeamRepo
 	formVersionRepo repositories.IFormVersionRepo
 	questionRepo    repositories.IQuestionRepo
 	edgeRepo        repositories.IEdgeRepo
+	translationRepo repositories.ITranslationRepo
+	jobRepo         repositories.IJobRepo
+	permissions     IPermissionService
+	audit           IAuditService
+	entitlements    IEntitlementService
+	usage           IUsageService
 }
 
-func NewFormService(formRepo repositories.IFormRepo, projectRepo repositories.IProjectRepo, formVersionRepo repositories.IFormVersionRepo, questionRepo repositories.IQuestionRepo, edgeRepo repositories.IEdgeRepo) *FormService {
+func NewFormService(formRepo repositories.IFormRepo, projectRepo repositories.IProjectRepo, teamRepo repositories.ITeamRepo, formVersionRepo repositories.IFormVersionRepo, questionRepo repositories.IQuestionRepo, edgeRepo repositories.IEdgeRepo, translationRepo repositories.ITranslationRepo, jobRepo repositories.IJobRepo, permissions IPermissionService, audit IAuditService, entitlements IEntitlementService, usage IUsageService) *FormService {
 	return &FormService{
 		formRepo:        formRepo,
 		projectRepo:     projectRepo,
+		teamRepo:        teamRepo,
 		formVersionRepo: formVersionRepo,
 		questionRepo:    questionRepo,
 		edgeRepo:        edgeRepo,
+		translationRepo: translationRepo,
+		jobRepo:         jobRepo,
+		permissions:     permissions,
+		audit:           audit,
+		entitlements:    entitlements,
+		usage:           usage,
 	}
 }
 
@@ -56,8 +81,8 @@ func (s *FormService) Get(ctx context.Context, userId string, pagination *types.
 	if project == nil {
 		return nil, 0, errors.NotFound("Project")
 	}
-	if project.Team.OwnerID == nil || *project.Team.OwnerID != userId {
-		return nil, 0, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &project.Team, PermissionView); err != nil {
+		return nil, 0, err
 	}
 
 	var statusPtr *models.FormStatus
@@ -86,8 +111,8 @@ func (s *FormService) GetById(ctx context.Context, userId string, formId string)
 		return nil, err
 	}
 
-	if form.CreatedBy != userId {
-		return nil, errors.Unauthorized("")
+	if _, err := s.permissions.CheckTeam(ctx, userId, form.TeamID, PermissionView); err != nil {
+		return nil, err
 	}
 
 	return mapper.ToFormResponse(form), nil
@@ -102,8 +127,17 @@ func (s *FormService) Create(ctx context.Context, userId string, projectId strin
 	if project == nil {
 		return nil, errors.NotFound("Project")
 	}
-	if project.Team.OwnerID == nil || *project.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &project.Team, PermissionEditForms); err != nil {
+		return nil, err
+	}
+	if err := s.entitlements.Check(ctx, project.TeamID, LimitForms, 1); err != nil {
+		return nil, err
+	}
+
+	formId := utils.GenerateUUID()
+	slug, err := s.generateSlug(ctx, formId)
+	if err != nil {
+		return nil, err
 	}
 
 	status := models.FormStatusDraft
@@ -111,7 +145,7 @@ func (s *FormService) Create(ctx context.Context, userId string, projectId strin
 	falseVal := false
 
 	formModel := &models.Form{
-		ID:                  utils.GenerateUUID(),
+		ID:                  formId,
 		ProjectID:           projectId,
 		TeamID:              project.TeamID,
 		Name:                form.Name,
@@ -130,7 +164,7 @@ func (s *FormService) Create(ctx context.Context, userId string, projectId strin
 		PasswordProtected:   &falseVal,
 		Valid:               true,
 		CreatedBy:           userId,
-		UniqueSubdomain:     utils.GenerateRandomString(6),
+		UniqueSubdomain:     &slug,
 		CreatedAt:           utils.GetCurrentTime(),
 		UpdatedAt:           utils.GetCurrentTime(),
 	}
@@ -139,6 +173,15 @@ func (s *FormService) Create(ctx context.Context, userId string, projectId strin
 	if err != nil {
 		return nil, err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       createdForm.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   createdForm.ID,
+		Action:       models.AuditActionCreate,
+		After:        createdForm,
+	})
+	s.usage.Record(ctx, createdForm.TeamID, models.UsageEventFormCreated, 1, createdForm.ID)
 	return mapper.ToFormResponse(createdForm), nil
 }
 
@@ -153,13 +196,25 @@ func (s *FormService) Update(ctx context.Context, userId string, formId string,
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 
 	updates["updatedAt"] = utils.GetCurrentTime()
 
-	return s.formRepo.Update(ctx, form.ID, updates)
+	if err := s.formRepo.Update(ctx, form.ID, updates); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       form,
+		After:        updates,
+	})
+	return nil
 }
 
 func (s *FormService) UpdateStatus(ctx context.Context, userId string, formId string, status models.FormStatus) error {
@@ -173,11 +228,13 @@ func (s *FormService) UpdateStatus(ctx context.Context, userId string, formId st
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 
-	return s.formRepo.UpdateStatus(ctx, form.ID, status)
+	// Publishing through the status only reopens the current version, a new version
+	// is created by Publish
+	return s.transition(ctx, form, status, userId, nil, nil)
 }
 
 func (s *FormService) Delete(ctx context.Context, userId string, formId string) error {
@@ -191,11 +248,22 @@ func (s *FormService) Delete(ctx context.Context, userId string, formId string)
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionManage); err != nil {
+		return err
 	}
 
-	return s.formRepo.Delete(ctx, form.ID)
+	if err := s.formRepo.Delete(ctx, form.ID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionDelete,
+		Before:       form,
+	})
+	return nil
 }
 
 func (s *FormService) UpdateSequence(ctx context.Context, userId string, formId string, sequences []*types.SequenceItem) error {
@@ -209,8 +277,8 @@ func (s *FormService) UpdateSequence(ctx context.Context, userId string, formId
 	if form == nil {
 		return errors.NotFound("Form")
 	}
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 
 	err = s.formRepo.UpdateQuestionSequence(ctx, formId, sequences)
@@ -218,6 +286,13 @@ func (s *FormService) UpdateSequence(ctx context.Context, userId string, formId
 		return err
 	}
 
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionUpdate,
+		After:        map[string]interface{}{"sequence": sequences},
+	})
 	return nil
 }
 
@@ -226,7 +301,7 @@ func (s *FormService) Demo(ctx context.Context, userId string) (string, error) {
 	return "Demo function executed for user: " + userId, nil
 }
 
-func (s *FormService) Publish(ctx context.Context, formId string) (*types.FormResponse, error) {
+func (s *FormService) Publish(ctx context.Context, userId string, formId string) (*types.FormResponse, error) {
 	// Get form details
 	form, err := s.formRepo.GetById(ctx, formId)
 	if err != nil {
@@ -235,6 +310,36 @@ func (s *FormService) Publish(ctx context.Context, formId string) (*types.FormRe
 	if form == nil {
 		return nil, errors.NotFound("Form")
 	}
+	if _, err := s.permissions.CheckTeam(ctx, userId, form.TeamID, PermissionEditForms); err != nil {
+		return nil, err
+	}
+	if err := checkTransition(formStatusOf(form), models.FormStatusPublished); err != nil {
+		return nil, err
+	}
+
+	version, err := s.createVersion(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.promoteVersion(ctx, form, version, editorRevisionOf(form), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get updated form
+	updatedForm, err := s.formRepo.GetById(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ToFormResponse(updatedForm), nil
+}
+
+// createVersion snapshots the editor state of the form into a new version. The version
+// is not published until it is promoted.
+func (s *FormService) createVersion(ctx context.Context, form *models.Form) (*models.FormVersion, error) {
+	formId := form.ID
 
 	// Get all questions with options
 	questions, err := s.questionRepo.GetQuestions(ctx, formId)
@@ -248,8 +353,26 @@ func (s *FormService) Publish(ctx context.Context, formId string) (*types.FormRe
 		return nil, err
 	}
 
+	// Get locales and translations
+	locales, err := s.translationRepo.GetLocales(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translationRepo.GetTranslations(ctx, formId, "")
+	if err != nil {
+		return nil, err
+	}
+
 	// Create snapshot
 	snapshot := s.createPublishSnapshot(form, questions, edges)
+	s.addSnapshotTranslations(snapshot, locales, translations)
+
+	if fieldErrs := validatePlaceholders(snapshot); len(fieldErrs) > 0 {
+		return nil, errors.ValidationFailed("Form has invalid placeholders", fieldErrs)
+	}
+	if fieldErrs := validateVariableReferences(newFormFlow(snapshot)); len(fieldErrs) > 0 {
+		return nil, errors.ValidationFailed("Form has invalid variables", fieldErrs)
+	}
 
 	// Convert snapshot to JSONB
 	schemaBytes, err := json.Marshal(snapshot)
@@ -269,49 +392,39 @@ func (s *FormService) Publish(ctx context.Context, formId string) (*types.FormRe
 		versionNumber = 1
 	}
 
-	now := time.Now()
-
 	// Create form version
-	formVersion := &models.FormVersion{
+	return s.formVersionRepo.Create(ctx, &models.FormVersion{
 		ID:            utils.GenerateUUID(),
 		FormID:        formId,
 		VersionNumber: versionNumber,
 		Schema:        datatypes.JSON(schemaBytes),
-		CreatedAt:     now,
-		PublishedAt:   &now,
-	}
+		CreatedAt:     time.Now(),
+	})
+}
 
-	createdVersion, err := s.formVersionRepo.Create(ctx, formVersion)
-	if err != nil {
-		return nil, err
-	}
+// promoteVersion makes the version the one served to respondents and publishes the
+// form. revision is the editor revision the version was taken from.
+func (s *FormService) promoteVersion(ctx context.Context, form *models.Form, version *models.FormVersion, revision int, actorId string) error {
 
-	// Update form status and revision
-	editorRevision := form.EditorRevision
-	if editorRevision == nil {
-		defaultRevision := 1
-		editorRevision = &defaultRevision
+	if version.PublishedAt == nil {
+		if err := s.formVersionRepo.MarkPublished(ctx, version.ID, time.Now()); err != nil {
+			return err
+		}
 	}
 
 	updates := map[string]interface{}{
-		"status":              models.FormStatusPublished,
-		"publishedVersionId": createdVersion.ID,
-		"publishedRevision":  *editorRevision,
-		"updatedAt":          time.Now(),
-	}
-
-	err = s.formRepo.Update(ctx, formId, updates)
-	if err != nil {
-		return nil, err
+		"publishedVersionId": version.ID,
+		"publishedRevision":  revision,
 	}
+	return s.transition(ctx, form, models.FormStatusPublished, actorId, &version.ID, updates)
+}
 
-	// Get updated form
-	updatedForm, err := s.formRepo.GetById(ctx, formId)
-	if err != nil {
-		return nil, err
+// editorRevisionOf is the form's editor revision, 1 for forms that never had one
+func editorRevisionOf(form *models.Form) int {
+	if form.EditorRevision == nil {
+		return 1
 	}
-
-	return mapper.ToFormResponse(updatedForm), nil
+	return *form.EditorRevision
 }
 
 func (s *FormService) createPublishSnapshot(form *models.Form, questions []*models.Question, edges []*models.Edge) *types.PublishVersionSnapshot {
@@ -325,6 +438,7 @@ func (s *FormService) createPublishSnapshot(form *models.Form, questions []*mode
 				Label:     opt.Label,
 				Value:     opt.Value,
 				SortOrder: opt.SortOrder,
+				Score:     opt.Score,
 			}
 		}
 
@@ -354,6 +468,9 @@ func (s *FormService) createPublishSnapshot(form *models.Form, questions []*mode
 		}
 	}
 
+	// Hidden fields are validated when saved, so a decode error only means there are none
+	hiddenFields, _ := readHiddenFields(form.Metadata)
+
 	// Create snapshot
 	return &types.PublishVersionSnapshot{
 		Name:                form.Name,
@@ -368,19 +485,164 @@ func (s *FormService) createPublishSnapshot(form *models.Form, questions []*mode
 		Metadata:            form.Metadata,
 		Questions:           publishedQuestions,
 		Edges:               publishedEdges,
+		HiddenFields:        hiddenFields,
+	}
+}
+
+func (s *FormService) addSnapshotTranslations(snapshot *types.PublishVersionSnapshot, locales []*models.FormLocale, translations []*models.FormTranslation) {
+	if len(locales) == 0 {
+		return
+	}
+
+	snapshot.Locales = make([]string, len(locales))
+	for i, locale := range locales {
+		snapshot.Locales[i] = locale.Locale
+		if locale.IsDefault {
+			snapshot.DefaultLocale = locale.Locale
+		}
+	}
+	if snapshot.DefaultLocale == "" {
+		snapshot.DefaultLocale = snapshot.Locales[0]
+	}
+
+	// Translations of locales that were removed are left out
+	var active []*models.FormTranslation
+	for _, t := range translations {
+		if containsString(snapshot.Locales, t.Locale) {
+			active = append(active, t)
+		}
 	}
+	snapshot.Translations = buildPublishedTranslations(active, snapshot.DefaultLocale)
 }
 
-func (s *FormService) Unpublish(ctx context.Context, formId string) (*types.FormResponse, error) {
-	err := s.formRepo.UpdateStatus(ctx, formId, models.FormStatusDraft)
+func (s *FormService) Unpublish(ctx context.Context, userId string, formId string) (*types.FormResponse, error) {
+	form, err := s.formRepo.GetById(ctx, formId)
 	if err != nil {
 		return nil, err
 	}
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+	if _, err := s.permissions.CheckTeam(ctx, userId, form.TeamID, PermissionEditForms); err != nil {
+		return nil, err
+	}
 
-	form, err := s.formRepo.GetById(ctx, formId)
+	err = s.transition(ctx, form, models.FormStatusDraft, userId, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err = s.formRepo.GetById(ctx, formId)
 	if err != nil {
 		return nil, err
 	}
 
 	return mapper.ToFormResponse(form), nil
 }
+
+func (s *FormService) UpdateVariables(ctx context.Context, userId string, formId string, body *types.UpdateVariablesRequest) error {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
+	}
+
+	if fieldErrs := validateFormVariables(body.Variables); len(fieldErrs) > 0 {
+		return errors.ValidationFailed("Invalid variables", fieldErrs)
+	}
+
+	variables := body.Variables
+	if variables == nil {
+		variables = []*types.FormVariable{}
+	}
+	return s.setMetadataKey(ctx, form, formMetadataVariables, variables)
+}
+
+func (s *FormService) UpdateQuiz(ctx context.Context, userId string, formId string, body *types.QuizSettings) error {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
+	}
+
+	questions, err := s.questionRepo.GetQuestions(ctx, formId)
+	if err != nil {
+		return err
+	}
+	if fieldErrs := validateQuizSettings(body, questions); len(fieldErrs) > 0 {
+		return errors.ValidationFailed("Invalid quiz settings", fieldErrs)
+	}
+
+	if body.Questions == nil {
+		body.Questions = map[string]*types.QuizQuestion{}
+	}
+	return s.setMetadataKey(ctx, form, formMetadataQuiz, body)
+}
+
+func (s *FormService) UpdateHiddenFields(ctx context.Context, userId string, formId string, body *types.UpdateHiddenFieldsRequest) error {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
+	}
+
+	if fieldErrs := validateHiddenFieldDeclarations(body.HiddenFields); len(fieldErrs) > 0 {
+		return errors.ValidationFailed("Invalid hidden fields", fieldErrs)
+	}
+
+	hiddenFields := body.HiddenFields
+	if hiddenFields == nil {
+		hiddenFields = []*types.HiddenField{}
+	}
+	return s.setMetadataKey(ctx, form, formMetadataHiddenFields, hiddenFields)
+}
+
+// setMetadataKey replaces a single top-level key of the form's metadata, keeping the others
+func (s *FormService) setMetadataKey(ctx context.Context, form *models.Form, key string, value interface{}) error {
+
+	metadata := make(map[string]interface{})
+	if len(form.Metadata) > 0 {
+		if err := json.Unmarshal(form.Metadata, &metadata); err != nil {
+			return errors.Internal(err)
+		}
+	}
+	previous := metadata[key]
+
+	if err := s.formRepo.SetMetadataKey(ctx, form.ID, key, value); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       map[string]interface{}{"metadata." + key: previous},
+		After:        map[string]interface{}{"metadata." + key: value},
+	})
+	return nil
+}
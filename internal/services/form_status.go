@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// systemActor is recorded for transitions made by the API itself rather than a user
+const systemActor = "system"
+
+// formStatusTransitions lists the statuses a form can move to from each status.
+// Publishing again while published creates a new version.
+var formStatusTransitions = map[models.FormStatus][]models.FormStatus{
+	models.FormStatusDraft:     {models.FormStatusPublished, models.FormStatusArchived},
+	models.FormStatusPublished: {models.FormStatusPublished, models.FormStatusClosed, models.FormStatusDraft},
+	models.FormStatusClosed:    {models.FormStatusPublished, models.FormStatusDraft, models.FormStatusArchived},
+	models.FormStatusArchived:  {models.FormStatusDraft},
+}
+
+func formStatusOf(form *models.Form) models.FormStatus {
+	if form.Status == nil {
+		return models.FormStatusDraft
+	}
+	return *form.Status
+}
+
+func canTransition(from models.FormStatus, to models.FormStatus) bool {
+	for _, status := range formStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func checkTransition(from models.FormStatus, to models.FormStatus) error {
+	if !canTransition(from, to) {
+		return errors.BadRequest("A " + string(from) + " form cannot be moved to " + string(to))
+	}
+	return nil
+}
+
+// transition moves the form to a new status and records who did it. updates are
+// applied together with the status change, and the repository runs the side effects of
+// the new status in the same transaction.
+func (s *FormService) transition(ctx context.Context, form *models.Form, to models.FormStatus, actorId string, versionId *string, updates map[string]interface{}) error {
+
+	from := formStatusOf(form)
+	if err := checkTransition(from, to); err != nil {
+		return err
+	}
+
+	if to == models.FormStatusPublished && versionId == nil {
+		if form.PublishedVersionID == nil {
+			return errors.BadRequest("Form has no published version, publish it first")
+		}
+		versionId = form.PublishedVersionID
+	}
+
+	err := s.formRepo.TransitionStatus(ctx, &models.FormStatusTransition{
+		ID:         utils.GenerateUUID(),
+		FormID:     form.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorId,
+		VersionID:  versionId,
+		CreatedAt:  time.Now(),
+	}, updates)
+	if err != nil {
+		return err
+	}
+
+	action := models.AuditActionUpdate
+	switch {
+	case to == models.FormStatusPublished:
+		action = models.AuditActionPublish
+	case from == models.FormStatusPublished && to == models.FormStatusDraft:
+		action = models.AuditActionUnpublish
+	}
+	after := map[string]interface{}{"status": to}
+	for column, value := range updates {
+		after[column] = value
+	}
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       action,
+		Before:       form,
+		After:        after,
+	})
+
+	form.Status = &to
+	return nil
+}
+
+func (s *FormService) GetStatusHistory(ctx context.Context, userId string, formId string) ([]*models.FormStatusTransition, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.formRepo.GetStatusTransitions(ctx, formId)
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/expr"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+const (
+	// scoreVariable is always available and sums the scores of the selected options
+	scoreVariable = "score"
+
+	formMetadataVariables = "variables"
+	maxFormVariables      = 50
+	maxExpressionLength   = 500
+)
+
+var variableNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,39}$`)
+
+// variableFunctions lists the functions expressions may call and their arity (-1 for variadic)
+var variableFunctions = map[string]int{
+	"min":    -1,
+	"max":    -1,
+	"abs":    1,
+	"floor":  1,
+	"ceil":   1,
+	"round":  1,
+	"answer": 1,
+	"score":  1,
+}
+
+// readFormVariables decodes the variables declared in a form's metadata
+func readFormVariables(metadata datatypes.JSON) ([]*types.FormVariable, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	var decoded struct {
+		Variables []*types.FormVariable `json:"variables"`
+	}
+	if err := json.Unmarshal(metadata, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded.Variables, nil
+}
+
+// validateFormVariables checks names and expressions. An expression may use "score",
+// variables declared before it, its own initial value and the functions above.
+func validateFormVariables(variables []*types.FormVariable) []utils.ValidationErrorResponse {
+	var fieldErrs []utils.ValidationErrorResponse
+
+	if len(variables) > maxFormVariables {
+		return []utils.ValidationErrorResponse{fieldError("variables", fmt.Sprintf("A form can have at most %d variables", maxFormVariables))}
+	}
+
+	known := map[string]bool{scoreVariable: true}
+	for i, variable := range variables {
+		field := fmt.Sprintf("variables[%d]", i)
+
+		if !variableNamePattern.MatchString(variable.Name) {
+			fieldErrs = append(fieldErrs, fieldError(field+".name", "name must start with a lowercase letter or underscore and contain only a-z, 0-9 and _"))
+			continue
+		}
+		if variable.Name == scoreVariable {
+			fieldErrs = append(fieldErrs, fieldError(field+".name", "score is a built-in variable"))
+			continue
+		}
+		if known[variable.Name] {
+			fieldErrs = append(fieldErrs, fieldError(field+".name", "name is already used by another variable"))
+			continue
+		}
+		known[variable.Name] = true
+
+		if variable.Expression == "" {
+			continue
+		}
+		if len(variable.Expression) > maxExpressionLength {
+			fieldErrs = append(fieldErrs, fieldError(field+".expression", fmt.Sprintf("expression can be at most %d characters", maxExpressionLength)))
+			continue
+		}
+		program, err := expr.Parse(variable.Expression)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError(field+".expression", err.Error()))
+			continue
+		}
+		for _, name := range program.Vars() {
+			if !known[name] {
+				fieldErrs = append(fieldErrs, fieldError(field+".expression", fmt.Sprintf("%s is not defined before this variable", name)))
+			}
+		}
+		for _, call := range program.Calls() {
+			arity, ok := variableFunctions[call.Name]
+			if !ok {
+				fieldErrs = append(fieldErrs, fieldError(field+".expression", fmt.Sprintf("%s is not a known function", call.Name)))
+				continue
+			}
+			if (arity >= 0 && len(call.Args) != arity) || (arity < 0 && len(call.Args) == 0) {
+				fieldErrs = append(fieldErrs, fieldError(field+".expression", fmt.Sprintf("%s has the wrong number of arguments", call.Name)))
+				continue
+			}
+			if (call.Name == "answer" || call.Name == "score") && !call.Args[0].IsString {
+				fieldErrs = append(fieldErrs, fieldError(field+".expression", fmt.Sprintf(`%s expects a question id such as %s("<id>")`, call.Name, call.Name)))
+			}
+		}
+	}
+	return fieldErrs
+}
+
+// validateVariableReferences checks at publish time that answer() and score() refer to questions of the form
+func validateVariableReferences(flow *formFlow) []utils.ValidationErrorResponse {
+	var fieldErrs []utils.ValidationErrorResponse
+	for i, variable := range flow.variables {
+		if variable.Expression == "" {
+			continue
+		}
+		program, err := expr.Parse(variable.Expression)
+		if err != nil {
+			fieldErrs = append(fieldErrs, fieldError(fmt.Sprintf("variables[%d].expression", i), err.Error()))
+			continue
+		}
+		for _, call := range program.Calls() {
+			if call.Name != "answer" && call.Name != "score" {
+				continue
+			}
+			if len(call.Args) == 1 && flow.byId[call.Args[0].Str] == nil {
+				fieldErrs = append(fieldErrs, fieldError(fmt.Sprintf("variables[%d].expression", i), fmt.Sprintf("%s references a question that does not exist", call.Name)))
+			}
+		}
+	}
+	return fieldErrs
+}
+
+// computeVariables evaluates the built-in score and the declared variables in order.
+// An expression that fails to evaluate, e.g. on division by zero, keeps the initial value.
+func computeVariables(flow *formFlow, answers map[string]interface{}) map[string]float64 {
+	values := map[string]float64{scoreVariable: 0}
+	for _, question := range flow.questions {
+		values[scoreVariable] += questionScore(question, answers[question.ID])
+	}
+
+	for _, variable := range flow.variables {
+		values[variable.Name] = variable.Initial
+	}
+
+	env := &variableEnv{flow: flow, answers: answers, values: values}
+	for _, variable := range flow.variables {
+		if variable.Expression == "" {
+			continue
+		}
+		program, err := expr.Parse(variable.Expression)
+		if err != nil {
+			continue
+		}
+		if result, err := program.Eval(env); err == nil {
+			values[variable.Name] = result
+		}
+	}
+	return values
+}
+
+// questionScore sums the scores of the options selected in an answer
+func questionScore(question *types.PublishedQuestion, answer interface{}) float64 {
+	var selected []string
+	switch v := answer.(type) {
+	case string:
+		selected = []string{v}
+	case []string:
+		selected = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				selected = append(selected, s)
+			}
+		}
+	}
+
+	total := 0.0
+	for _, value := range selected {
+		if option := findOption(question.Options, value); option != nil && option.Score != nil {
+			total += *option.Score
+		}
+	}
+	return total
+}
+
+// variableEnv exposes variables and answers to expressions
+type variableEnv struct {
+	flow    *formFlow
+	answers map[string]interface{}
+	values  map[string]float64
+}
+
+func (e *variableEnv) Var(name string) (float64, bool) {
+	value, ok := e.values[name]
+	return value, ok
+}
+
+func (e *variableEnv) Call(name string, args []expr.Value) (float64, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("%s expects at least one argument", name)
+	}
+
+	switch name {
+	case "answer":
+		return answerNumber(e.answers[args[0].Str]), nil
+	case "score":
+		question := e.flow.byId[args[0].Str]
+		if question == nil {
+			return 0, nil
+		}
+		return questionScore(question, e.answers[question.ID]), nil
+	case "min", "max":
+		result := args[0].Number
+		for _, arg := range args[1:] {
+			if name == "min" {
+				result = math.Min(result, arg.Number)
+			} else {
+				result = math.Max(result, arg.Number)
+			}
+		}
+		return result, nil
+	case "abs":
+		return math.Abs(args[0].Number), nil
+	case "floor":
+		return math.Floor(args[0].Number), nil
+	case "ceil":
+		return math.Ceil(args[0].Number), nil
+	case "round":
+		return math.Round(args[0].Number), nil
+	}
+	return 0, fmt.Errorf("unknown function %s", name)
+}
+
+// answerNumber converts an answer to a number: numeric answers as-is, booleans as 1 or 0,
+// lists as their length and anything else as 0
+func answerNumber(answer interface{}) float64 {
+	if number, ok := toNumber(answer); ok {
+		return number
+	}
+	switch v := answer.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+	case []string:
+		return float64(len(v))
+	case []interface{}:
+		return float64(len(v))
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+const (
+	formMetadataHiddenFields = "hiddenFields"
+
+	maxHiddenFields      = 50
+	maxHiddenValueLength = 1024
+)
+
+var hiddenFieldNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]{0,63}$`)
+
+// reservedHiddenFieldNames are query parameters the submission endpoints use themselves
+var reservedHiddenFieldNames = []string{"locale", prefillQueryParam}
+
+// readHiddenFields decodes the hidden fields declared in a form's metadata
+func readHiddenFields(metadata datatypes.JSON) ([]types.HiddenField, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	var decoded struct {
+		HiddenFields []types.HiddenField `json:"hiddenFields"`
+	}
+	if err := json.Unmarshal(metadata, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded.HiddenFields, nil
+}
+
+func validateHiddenFieldDeclarations(fields []*types.HiddenField) []utils.ValidationErrorResponse {
+	if len(fields) > maxHiddenFields {
+		return []utils.ValidationErrorResponse{fieldError("hiddenFields", fmt.Sprintf("A form can have at most %d hidden fields", maxHiddenFields))}
+	}
+
+	var fieldErrs []utils.ValidationErrorResponse
+	seen := make(map[string]bool)
+	for i, field := range fields {
+		name := fmt.Sprintf("hiddenFields[%d]", i)
+		switch {
+		case !hiddenFieldNamePattern.MatchString(field.Name):
+			fieldErrs = append(fieldErrs, fieldError(name+".name", "name must start with a letter or underscore and contain only letters, digits, _ and -"))
+		case containsString(reservedHiddenFieldNames, strings.ToLower(field.Name)):
+			fieldErrs = append(fieldErrs, fieldError(name+".name", field.Name+" is a reserved parameter name"))
+		case seen[strings.ToLower(field.Name)]:
+			fieldErrs = append(fieldErrs, fieldError(name+".name", "name is already declared"))
+		}
+		seen[strings.ToLower(field.Name)] = true
+
+		if utf8.RuneCountInString(field.DefaultValue) > maxHiddenValueLength {
+			fieldErrs = append(fieldErrs, fieldError(name+".defaultValue", fmt.Sprintf("defaultValue must be at most %d characters", maxHiddenValueLength)))
+		}
+	}
+	return fieldErrs
+}
+
+// collectHiddenValues keeps only the declared hidden fields from the given sources,
+// later sources overriding earlier ones, and applies defaults for missing values.
+// Undeclared keys are dropped because query strings carry unrelated parameters.
+func collectHiddenValues(declared []types.HiddenField, sources ...map[string]string) (map[string]string, []utils.ValidationErrorResponse) {
+	values := make(map[string]string, len(declared))
+	var fieldErrs []utils.ValidationErrorResponse
+
+	for _, field := range declared {
+		value, present := "", false
+		for _, source := range sources {
+			if v, ok := source[field.Name]; ok {
+				value, present = v, true
+			}
+		}
+		if !present || value == "" {
+			value = field.DefaultValue
+		}
+		if value == "" {
+			continue
+		}
+		if utf8.RuneCountInString(value) > maxHiddenValueLength {
+			fieldErrs = append(fieldErrs, fieldError("hidden."+field.Name, fmt.Sprintf("Value must be at most %d characters", maxHiddenValueLength)))
+			continue
+		}
+		values[field.Name] = value
+	}
+	return values, fieldErrs
+}
+
+func hiddenFieldNames(fields []types.HiddenField) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return names
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/mailer"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/mapper"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// invitationTokenBytes is the entropy of an invitation token
+const invitationTokenBytes = 32
+
+type IInvitationService interface {
+	Create(ctx context.Context, userId string, teamId string, body *types.CreateInvitationRequest) (*types.InvitationResponse, error)
+	List(ctx context.Context, userId string, teamId string) ([]*types.InvitationResponse, error)
+	Revoke(ctx context.Context, userId string, teamId string, invitationId string) error
+	Resend(ctx context.Context, userId string, teamId string, invitationId string) (*types.InvitationResponse, error)
+	Accept(ctx context.Context, userId string, body *types.AcceptInvitationRequest) (*types.InvitationResponse, error)
+}
+
+type InvitationService struct {
+	invitationRepo repositories.IInvitationRepo
+	permissions    IPermissionService
+	audit          IAuditService
+	entitlements   IEntitlementService
+	mailer         mailer.Mailer
+	cfg            *config.Config
+}
+
+func NewInvitationService(
+	invitationRepo repositories.IInvitationRepo,
+	permissions IPermissionService,
+	audit IAuditService,
+	entitlements IEntitlementService,
+	mailer mailer.Mailer,
+	cfg *config.Config,
+) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		permissions:    permissions,
+		audit:          audit,
+		entitlements:   entitlements,
+		mailer:         mailer,
+		cfg:            cfg,
+	}
+}
+
+// Create invites an email address to the team. Like role changes, the invited role must
+// be below the inviter's own, and pending invitations hold a seat until they expire.
+func (s *InvitationService) Create(ctx context.Context, userId string, teamId string, body *types.CreateInvitationRequest) (*types.InvitationResponse, error) {
+
+	team, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	actorRole, err := s.permissions.Role(ctx, userId, team)
+	if err != nil {
+		return nil, err
+	}
+	role := models.TeamRole(body.Role)
+	if roleRank(role) >= roleRank(actorRole) {
+		return nil, errors.Unauthorized("")
+	}
+
+	if err := s.entitlements.Check(ctx, team.ID, LimitMembers, 1); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := s.newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.TeamInvitation{
+		ID:        utils.GenerateUUID(),
+		TeamID:    team.ID,
+		Email:     strings.ToLower(strings.TrimSpace(body.Email)),
+		Role:      role,
+		InvitedBy: userId,
+		TokenHash: tokenHash,
+		Status:    models.InvitationStatusPending,
+		ExpiresAt: time.Now().Add(s.cfg.Invites.Expiry),
+		CreatedAt: *utils.GetCurrentTime(),
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceInvitation,
+		ResourceID:   invitation.ID,
+		Action:       models.AuditActionCreate,
+		After:        invitation,
+	})
+	s.send(ctx, team, invitation, token)
+	return mapper.ToInvitationResponse(invitation), nil
+}
+
+func (s *InvitationService) List(ctx context.Context, userId string, teamId string) ([]*types.InvitationResponse, error) {
+
+	if _, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepo.GetPending(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*types.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		resp[i] = mapper.ToInvitationResponse(invitation)
+	}
+	return resp, nil
+}
+
+func (s *InvitationService) Revoke(ctx context.Context, userId string, teamId string, invitationId string) error {
+
+	if _, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionManageMembers); err != nil {
+		return err
+	}
+
+	invitation, err := s.invitationRepo.GetById(ctx, teamId, invitationId)
+	if err != nil {
+		return err
+	}
+	if invitation == nil {
+		return errors.NotFound("Invitation")
+	}
+
+	updated, err := s.invitationRepo.UpdatePending(ctx, invitation.ID, map[string]interface{}{
+		"status": models.InvitationStatusRevoked,
+	})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errors.Conflict("Only pending invitations can be revoked")
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamId,
+		ResourceType: models.AuditResourceInvitation,
+		ResourceID:   invitation.ID,
+		Action:       models.AuditActionRevoke,
+		Before:       invitation,
+		After:        map[string]interface{}{"status": models.InvitationStatusRevoked},
+	})
+	return nil
+}
+
+// Resend emails a fresh token and restarts the expiry; the previous link stops working
+func (s *InvitationService) Resend(ctx context.Context, userId string, teamId string, invitationId string) (*types.InvitationResponse, error) {
+
+	team, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.invitationRepo.GetById(ctx, teamId, invitationId)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, errors.NotFound("Invitation")
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, errors.Conflict("Only pending invitations can be resent")
+	}
+	// A lapsed invitation no longer holds a seat, so renewing it takes one again
+	if !invitation.ExpiresAt.After(time.Now()) {
+		if err := s.entitlements.Check(ctx, team.ID, LimitMembers, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	token, tokenHash, err := s.newToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = time.Now().Add(s.cfg.Invites.Expiry)
+
+	updated, err := s.invitationRepo.UpdatePending(ctx, invitation.ID, map[string]interface{}{
+		"tokenHash": invitation.TokenHash,
+		"expiresAt": invitation.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.Conflict("Only pending invitations can be resent")
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceInvitation,
+		ResourceID:   invitation.ID,
+		Action:       models.AuditActionResend,
+		After:        map[string]interface{}{"expiresAt": invitation.ExpiresAt},
+	})
+	s.send(ctx, team, invitation, token)
+	return mapper.ToInvitationResponse(invitation), nil
+}
+
+// Accept redeems an invitation token for the signed-in user. The token is bound to
+// whoever presents it; the email address is only where it was delivered.
+func (s *InvitationService) Accept(ctx context.Context, userId string, body *types.AcceptInvitationRequest) (*types.InvitationResponse, error) {
+
+	invitation, err := s.invitationRepo.GetByTokenHash(ctx, utils.HashToken(body.Token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || !invitation.Team.Valid {
+		return nil, errors.NotFound("Invitation")
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, errors.Conflict("This invitation is no longer valid")
+	}
+	if !invitation.ExpiresAt.After(time.Now()) {
+		return nil, errors.BadRequest("This invitation has expired, ask for a new one")
+	}
+
+	role, err := s.permissions.Role(ctx, userId, &invitation.Team)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return nil, errors.Conflict("You are already a member of this team")
+	}
+
+	// The invitation's seat was counted while pending; it becomes a member seat now, so
+	// this only fails when the team is already over its limit
+	if err := s.entitlements.Check(ctx, invitation.TeamID, LimitMembers, 0); err != nil {
+		return nil, err
+	}
+
+	member := &models.TeamMember{
+		ID:        utils.GenerateUUID(),
+		TeamID:    invitation.TeamID,
+		UserID:    userId,
+		Role:      invitation.Role,
+		CreatedAt: *utils.GetCurrentTime(),
+	}
+	if err := s.invitationRepo.Accept(ctx, invitation, member); err != nil {
+		return nil, err
+	}
+
+	invitation.Status = models.InvitationStatusAccepted
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       invitation.TeamID,
+		ResourceType: models.AuditResourceInvitation,
+		ResourceID:   invitation.ID,
+		Action:       models.AuditActionAccept,
+		After:        map[string]interface{}{"status": invitation.Status},
+	})
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       invitation.TeamID,
+		ResourceType: models.AuditResourceMember,
+		ResourceID:   member.UserID,
+		Action:       models.AuditActionCreate,
+		After:        member,
+	})
+	return mapper.ToInvitationResponse(invitation), nil
+}
+
+func (s *InvitationService) newToken() (string, string, error) {
+	token, err := utils.GenerateSecureToken(invitationTokenBytes)
+	if err != nil {
+		return "", "", errors.Internal(err)
+	}
+	return token, utils.HashToken(token), nil
+}
+
+// send emails the invitation link. A delivery failure is logged rather than returned
+// since the invitation exists either way and can be resent.
+func (s *InvitationService) send(ctx context.Context, team *models.Team, invitation *models.TeamInvitation, token string) {
+
+	teamName := "a team"
+	if team.Name != nil && *team.Name != "" {
+		teamName = *team.Name
+	}
+
+	msg := &mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You're invited to join %s on EscapeForm", teamName),
+		Body: fmt.Sprintf(
+			"You have been invited to join %s as %s.\n\nAccept the invitation: %s?token=%s\n\nThis link expires on %s.",
+			teamName,
+			strings.ToLower(string(invitation.Role)),
+			s.cfg.Invites.URL,
+			token,
+			invitation.ExpiresAt.UTC().Format(time.RFC1123),
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Error().Err(err).Str("invitationId", invitation.ID).Msg("Failed to send invitation email")
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+)
+
+// localePattern accepts language tags like "en", "pt-BR" or "zh-Hant"
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-([a-zA-Z]{2}|[0-9]{3}|[a-zA-Z]{4}))?$`)
+
+// translatableFields lists the fields that can be translated for each resource type
+var translatableFields = map[models.TranslationResourceType][]string{
+	models.TranslationResourceForm:     {"name", "description"},
+	models.TranslationResourceQuestion: {"title", "description", "placeholder"},
+	models.TranslationResourceOption:   {"label"},
+}
+
+// normalizeLocale returns the canonical casing of a language tag ("pt-br" -> "pt-BR",
+// "zh-hant" -> "zh-Hant"), or false when the tag is not supported
+func normalizeLocale(locale string) (string, bool) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if !localePattern.MatchString(locale) {
+		return "", false
+	}
+
+	parts := strings.SplitN(locale, "-", 2)
+	parts[0] = strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		if len(parts[1]) == 4 {
+			parts[1] = strings.ToUpper(parts[1][:1]) + strings.ToLower(parts[1][1:])
+		} else {
+			parts[1] = strings.ToUpper(parts[1])
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+func isTranslatableField(resourceType models.TranslationResourceType, field string) bool {
+	return containsString(translatableFields[resourceType], field)
+}
+
+// negotiateLocale picks the locale to render. An explicit locale wins, then the
+// Accept-Language preferences in quality order. Each candidate is matched exactly
+// first and then by its base language. Falls back to the default locale.
+func negotiateLocale(requested string, acceptLanguage string, available []string, defaultLocale string) string {
+	if len(available) == 0 {
+		return defaultLocale
+	}
+
+	candidates := parseAcceptLanguage(acceptLanguage)
+	if requested != "" {
+		candidates = append([]string{requested}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		locale, ok := normalizeLocale(candidate)
+		if !ok {
+			continue
+		}
+		if containsString(available, locale) {
+			return locale
+		}
+		base := strings.SplitN(locale, "-", 2)[0]
+		for _, option := range available {
+			if strings.SplitN(option, "-", 2)[0] == base {
+				return option
+			}
+		}
+	}
+	return defaultLocale
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language header ordered by quality
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// buildPublishedTranslations groups stored translations by locale for the publish snapshot.
+// The default locale is the source content and is never included.
+func buildPublishedTranslations(translations []*models.FormTranslation, defaultLocale string) map[string]*types.PublishedTranslation {
+	result := make(map[string]*types.PublishedTranslation)
+
+	for _, t := range translations {
+		if t.Locale == defaultLocale || t.Value == "" {
+			continue
+		}
+
+		pt, ok := result[t.Locale]
+		if !ok {
+			pt = &types.PublishedTranslation{
+				Questions: make(map[string]*types.PublishedQuestionTranslation),
+				Options:   make(map[string]string),
+			}
+			result[t.Locale] = pt
+		}
+
+		value := t.Value
+		switch t.ResourceType {
+		case models.TranslationResourceForm:
+			if t.Field == "name" {
+				pt.Name = &value
+			} else if t.Field == "description" {
+				pt.Description = &value
+			}
+		case models.TranslationResourceQuestion:
+			qt, ok := pt.Questions[t.ResourceID]
+			if !ok {
+				qt = &types.PublishedQuestionTranslation{}
+				pt.Questions[t.ResourceID] = qt
+			}
+			switch t.Field {
+			case "title":
+				qt.Title = value
+			case "description":
+				qt.Description = value
+			case "placeholder":
+				qt.Placeholder = value
+			}
+		case models.TranslationResourceOption:
+			pt.Options[t.ResourceID] = value
+		}
+	}
+	return result
+}
+
+// translateQuestions returns copies of the published questions with the locale's
+// translations applied; untranslated strings keep the default content
+func translateQuestions(questions []types.PublishedQuestion, translation *types.PublishedTranslation) []types.PublishedQuestion {
+	if translation == nil {
+		return questions
+	}
+
+	translated := make([]types.PublishedQuestion, len(questions))
+	for i, question := range questions {
+		if qt, ok := translation.Questions[question.ID]; ok {
+			if qt.Title != "" {
+				question.Title = qt.Title
+			}
+			if qt.Description != "" {
+				question.Description = qt.Description
+			}
+			if qt.Placeholder != "" {
+				question.Placeholder = qt.Placeholder
+			}
+		}
+
+		options := make([]types.PublishedQuestionOption, len(question.Options))
+		for j, option := range question.Options {
+			if label, ok := translation.Options[option.ID]; ok {
+				option.Label = label
+			}
+			options[j] = option
+		}
+		question.Options = options
+
+		translated[i] = question
+	}
+	return translated
+}
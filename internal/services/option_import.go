@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+)
+
+const maxOptionsPerQuestion = 1000
+
+var optionValueStripPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// optionItem is a label/value pair parsed from user input before it becomes a QuestionOption
+type optionItem struct {
+	Label string
+	Value string
+	Score *float64
+}
+
+// parseOptionImport turns pasted lines or CSV content into label/value pairs.
+// CSV rows are "label[,value[,score]]" and a leading header row starting with "label" is skipped.
+func parseOptionImport(format string, content string) ([]optionItem, error) {
+	var items []optionItem
+
+	switch format {
+	case "lines":
+		for _, line := range strings.Split(content, "\n") {
+			label := strings.TrimSpace(line)
+			if label != "" {
+				items = append(items, optionItem{Label: label})
+			}
+		}
+	case "csv":
+		reader := csv.NewReader(strings.NewReader(content))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for row := 0; ; row++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.BadRequest(fmt.Sprintf("Invalid CSV on line %d", row+1))
+			}
+			if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+				continue
+			}
+			if row == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "label") {
+				continue
+			}
+			item := optionItem{Label: strings.TrimSpace(record[0])}
+			if len(record) > 1 {
+				item.Value = strings.TrimSpace(record[1])
+			}
+			if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+				score, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+				if err != nil {
+					return nil, errors.BadRequest(fmt.Sprintf("Invalid score on line %d", row+1))
+				}
+				item.Score = &score
+			}
+			items = append(items, item)
+		}
+	default:
+		return nil, errors.BadRequest("format must be one of: lines, csv")
+	}
+
+	if len(items) == 0 {
+		return nil, errors.BadRequest("No options found in the provided content")
+	}
+	return items, nil
+}
+
+// normalizeOptionItems drops duplicate labels (case-insensitive) and fills in missing values
+// with a slug of the label, keeping values unique across the existing options as well.
+func normalizeOptionItems(items []optionItem, existing []*models.QuestionOption) []optionItem {
+	seenLabels := make(map[string]bool)
+	usedValues := make(map[string]bool)
+	for _, option := range existing {
+		seenLabels[strings.ToLower(option.Label)] = true
+		usedValues[option.Value] = true
+	}
+
+	result := make([]optionItem, 0, len(items))
+	for _, item := range items {
+		key := strings.ToLower(item.Label)
+		if seenLabels[key] {
+			continue
+		}
+		seenLabels[key] = true
+
+		value := item.Value
+		if value == "" {
+			value = optionValueFromLabel(item.Label)
+		}
+		base := value
+		for i := 2; usedValues[value]; i++ {
+			value = fmt.Sprintf("%s_%d", base, i)
+		}
+		usedValues[value] = true
+
+		result = append(result, optionItem{Label: item.Label, Value: value, Score: item.Score})
+	}
+	return result
+}
+
+func optionValueFromLabel(label string) string {
+	value := strings.Trim(optionValueStripPattern.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	if value == "" {
+		value = "option"
+	}
+	return value
+}
+
+func optionItemsFromRequest(options []*types.OptionItemRequest) []optionItem {
+	items := make([]optionItem, len(options))
+	for i, option := range options {
+		items[i] = optionItem{
+			Label: strings.TrimSpace(option.Label),
+			Value: strings.TrimSpace(option.Value),
+			Score: option.Score,
+		}
+	}
+	return items
+}
+
+func hasOptions(questionType models.QuestionType) bool {
+	switch questionType {
+	case models.QuestionTypeChoiceSingle, models.QuestionTypeChoiceMultiple, models.QuestionTypeChoiceDropdown,
+		models.QuestionTypeChoicePicture, models.QuestionTypeChoiceCheckbox, models.QuestionTypeRatingRank:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/auth"
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+)
+
+// Permission is an action on a team and everything in it
+type Permission string
+
+const (
+	// PermissionView covers reading the team, its projects and form builders
+	PermissionView Permission = "view"
+	// PermissionViewResponses covers responses, analytics, reports and exports
+	PermissionViewResponses Permission = "responses:view"
+	// PermissionEditResponses covers changing and deleting responses
+	PermissionEditResponses Permission = "responses:edit"
+	// PermissionEditForms covers building, publishing and scheduling forms and their uploads
+	PermissionEditForms Permission = "forms:edit"
+	// PermissionManage covers projects, deleting and moving forms, security settings,
+	// passwords, domains, API keys and renaming the team
+	PermissionManage Permission = "manage"
+	// PermissionViewSettings covers reading what PermissionManage changes: API keys,
+	// passwords, domains and the trash
+	PermissionViewSettings Permission = "settings:view"
+	// PermissionRevokeAccess covers revoking API keys and form passwords
+	PermissionRevokeAccess Permission = "access:revoke"
+	// PermissionManageMembers covers changing roles and removing members
+	PermissionManageMembers Permission = "members:manage"
+	// PermissionDeleteTeam covers deleting and restoring the team itself
+	PermissionDeleteTeam Permission = "team:delete"
+	// PermissionTransferOwnership covers offering the team to another member
+	PermissionTransferOwnership Permission = "team:transfer"
+	// PermissionViewAudit covers reading the team's audit log
+	PermissionViewAudit Permission = "audit:view"
+)
+
+// blockedPermissions are refused while the team is blocked for billing, which leaves it
+// read-only. Reads, revoking access, members, ownership and deleting the team stay
+// available to resolve it.
+var blockedPermissions = map[Permission]bool{
+	PermissionEditForms:     true,
+	PermissionEditResponses: true,
+	PermissionManage:        true,
+}
+
+// Roles are ordered, each one holding every permission of the roles below it
+var roleRanks = map[models.TeamRole]int{
+	models.TeamRoleViewer:  1,
+	models.TeamRoleAnalyst: 2,
+	models.TeamRoleEditor:  3,
+	models.TeamRoleAdmin:   4,
+	models.TeamRoleOwner:   5,
+}
+
+// permissionRoles is the lowest role holding each permission
+var permissionRoles = map[Permission]models.TeamRole{
+	PermissionView:          models.TeamRoleViewer,
+	PermissionViewResponses: models.TeamRoleAnalyst,
+	PermissionEditResponses: models.TeamRoleEditor,
+	PermissionEditForms:     models.TeamRoleEditor,
+	PermissionManage:        models.TeamRoleAdmin,
+	PermissionViewSettings:  models.TeamRoleAdmin,
+	PermissionRevokeAccess:  models.TeamRoleAdmin,
+	PermissionManageMembers: models.TeamRoleAdmin,
+	PermissionDeleteTeam:    models.TeamRoleOwner,
+	PermissionViewAudit:     models.TeamRoleAdmin,
+
+	PermissionTransferOwnership: models.TeamRoleOwner,
+}
+
+// scopePermissions is what each API key scope grants. Members and deleting the team
+// are never granted to a key.
+var scopePermissions = map[models.APIKeyScope][]Permission{
+	models.APIKeyScopeFormsRead:      {PermissionView},
+	models.APIKeyScopeFormsWrite:     {PermissionView, PermissionEditForms},
+	models.APIKeyScopeResponsesRead:  {PermissionView, PermissionViewResponses},
+	models.APIKeyScopeResponsesWrite: {PermissionView, PermissionViewResponses, PermissionEditResponses},
+	models.APIKeyScopeTeamManage:     {PermissionView, PermissionEditForms, PermissionManage, PermissionViewSettings, PermissionRevokeAccess},
+}
+
+func roleRank(role models.TeamRole) int {
+	return roleRanks[role]
+}
+
+func roleAllows(role models.TeamRole, permission Permission) bool {
+	required, ok := permissionRoles[permission]
+	return ok && roleRank(role) >= roleRank(required)
+}
+
+func scopesAllow(scopes []string, permission Permission) bool {
+	for _, scope := range scopes {
+		for _, granted := range scopePermissions[models.APIKeyScope(scope)] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apiKeyFromContext returns the API key authenticating the request, if any
+func apiKeyFromContext(ctx context.Context) *models.APIKey {
+	if identity := auth.FromContext(ctx); identity != nil {
+		return identity.APIKey
+	}
+	return nil
+}
+
+type IPermissionService interface {
+	Role(ctx context.Context, userId string, team *models.Team) (models.TeamRole, error)
+	Check(ctx context.Context, userId string, team *models.Team, permission Permission) error
+	CheckTeam(ctx context.Context, userId string, teamId string, permission Permission) (*models.Team, error)
+	IsSupportAdmin(ctx context.Context, userId string) bool
+}
+
+// PermissionService is the single place deciding what a user may do in a team
+type PermissionService struct {
+	memberRepo       repositories.IMemberRepo
+	teamRepo         repositories.ITeamRepo
+	subscriptionRepo repositories.ISubscriptionRepo
+	cfg              *config.Config
+}
+
+func NewPermissionService(memberRepo repositories.IMemberRepo, teamRepo repositories.ITeamRepo, subscriptionRepo repositories.ISubscriptionRepo, cfg *config.Config) *PermissionService {
+	return &PermissionService{
+		memberRepo:       memberRepo,
+		teamRepo:         teamRepo,
+		subscriptionRepo: subscriptionRepo,
+		cfg:              cfg,
+	}
+}
+
+// Role returns the user's role in the team, or an empty role when they are not a
+// member. The team's OwnerID is always the owner, which also covers teams created
+// before memberships existed.
+func (s *PermissionService) Role(ctx context.Context, userId string, team *models.Team) (models.TeamRole, error) {
+
+	if team.OwnerID != nil && *team.OwnerID == userId {
+		return models.TeamRoleOwner, nil
+	}
+
+	member, err := s.memberRepo.Get(ctx, team.ID, userId)
+	if err != nil {
+		return "", err
+	}
+	if member == nil {
+		return "", nil
+	}
+	return member.Role, nil
+}
+
+// Check fails with Unauthorized unless the user's role in the team grants the permission.
+// A request made with an API key is also limited to the key's team and scopes. Changes
+// to a blocked team fail with 402.
+func (s *PermissionService) Check(ctx context.Context, userId string, team *models.Team, permission Permission) error {
+
+	if key := apiKeyFromContext(ctx); key != nil {
+		if key.TeamID != team.ID || !scopesAllow(key.Scopes, permission) {
+			return errors.Unauthorized("")
+		}
+	}
+
+	role, err := s.Role(ctx, userId, team)
+	if err != nil {
+		return err
+	}
+	if !roleAllows(role, permission) {
+		return errors.Unauthorized("")
+	}
+
+	if blockedPermissions[permission] && team.TeamUsageID != nil {
+		status, err := s.subscriptionRepo.GetStatus(ctx, *team.TeamUsageID)
+		if err != nil {
+			return err
+		}
+		if status == models.TeamSubscriptionStatusBlocked {
+			return teamBlockedError()
+		}
+	}
+	return nil
+}
+
+// CheckTeam loads an active team and checks the permission on it
+func (s *PermissionService) CheckTeam(ctx context.Context, userId string, teamId string, permission Permission) (*models.Team, error) {
+
+	team, err := s.teamRepo.GetById(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+	if team == nil {
+		return nil, errors.NotFound("Team")
+	}
+	if err := s.Check(ctx, userId, team, permission); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// IsSupportAdmin reports whether the user is support staff, who may step in where a
+// team's own roles cannot, such as an owner who has left. API keys never qualify.
+func (s *PermissionService) IsSupportAdmin(ctx context.Context, userId string) bool {
+
+	if apiKeyFromContext(ctx) != nil {
+		return false
+	}
+	for _, id := range s.cfg.Support.AdminIDs {
+		if id == userId {
+			return true
+		}
+	}
+	return false
+}
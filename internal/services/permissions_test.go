@@ -0,0 +1,127 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+)
+
+func TestRoleAllows(t *testing.T) {
+	roles := []models.TeamRole{
+		models.TeamRoleViewer,
+		models.TeamRoleAnalyst,
+		models.TeamRoleEditor,
+		models.TeamRoleAdmin,
+		models.TeamRoleOwner,
+	}
+
+	// Each permission lists the roles holding it, in the order of roles above
+	tests := []struct {
+		permission Permission
+		want       []bool
+	}{
+		{PermissionView, []bool{true, true, true, true, true}},
+		{PermissionViewResponses, []bool{false, true, true, true, true}},
+		{PermissionEditResponses, []bool{false, false, true, true, true}},
+		{PermissionEditForms, []bool{false, false, true, true, true}},
+		{PermissionManage, []bool{false, false, false, true, true}},
+		{PermissionManageMembers, []bool{false, false, false, true, true}},
+		{PermissionDeleteTeam, []bool{false, false, false, false, true}},
+		{PermissionViewSettings, []bool{false, false, false, true, true}},
+		{PermissionRevokeAccess, []bool{false, false, false, true, true}},
+		{PermissionViewAudit, []bool{false, false, false, true, true}},
+		{PermissionTransferOwnership, []bool{false, false, false, false, true}},
+	}
+
+	for _, tt := range tests {
+		for i, role := range roles {
+			t.Run(string(tt.permission)+"/"+string(role), func(t *testing.T) {
+				if got := roleAllows(role, tt.permission); got != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want[i])
+				}
+			})
+		}
+	}
+
+	t.Run("not a member", func(t *testing.T) {
+		for _, tt := range tests {
+			if roleAllows("", tt.permission) {
+				t.Errorf("allowed %s", tt.permission)
+			}
+		}
+	})
+	t.Run("unknown role", func(t *testing.T) {
+		if roleAllows("SUPERUSER", PermissionView) {
+			t.Error("allowed view")
+		}
+	})
+	t.Run("unknown permission", func(t *testing.T) {
+		if roleAllows(models.TeamRoleOwner, "forms:destroy") {
+			t.Error("allowed an unknown permission to the owner")
+		}
+	})
+}
+
+func TestScopesAllow(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		permission Permission
+		want       bool
+	}{
+		{"forms:read views", []string{"forms:read"}, PermissionView, true},
+		{"forms:read cannot edit forms", []string{"forms:read"}, PermissionEditForms, false},
+		{"forms:read cannot see responses", []string{"forms:read"}, PermissionViewResponses, false},
+		{"forms:write edits forms", []string{"forms:write"}, PermissionEditForms, true},
+		{"forms:write cannot manage", []string{"forms:write"}, PermissionManage, false},
+		{"responses:read sees responses", []string{"responses:read"}, PermissionViewResponses, true},
+		{"responses:read cannot edit responses", []string{"responses:read"}, PermissionEditResponses, false},
+		{"responses:write edits responses", []string{"responses:write"}, PermissionEditResponses, true},
+		{"responses:write cannot edit forms", []string{"responses:write"}, PermissionEditForms, false},
+		{"team:manage manages", []string{"team:manage"}, PermissionManage, true},
+		{"team:manage cannot manage members", []string{"team:manage"}, PermissionManageMembers, false},
+		{"team:manage cannot delete the team", []string{"team:manage"}, PermissionDeleteTeam, false},
+		{"team:manage views settings", []string{"team:manage"}, PermissionViewSettings, true},
+		{"team:manage revokes access", []string{"team:manage"}, PermissionRevokeAccess, true},
+		{"team:manage cannot read the audit log", []string{"team:manage"}, PermissionViewAudit, false},
+		{"team:manage cannot transfer the team", []string{"team:manage"}, PermissionTransferOwnership, false},
+		{"scopes combine", []string{"forms:read", "responses:write"}, PermissionEditResponses, true},
+		{"unknown scope", []string{"admin"}, PermissionView, false},
+		{"no scopes", nil, PermissionView, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopesAllow(tt.scopes, tt.permission); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockedPermissions(t *testing.T) {
+	tests := []struct {
+		permission Permission
+		blocked    bool
+	}{
+		{PermissionView, false},
+		{PermissionViewResponses, false},
+		{PermissionEditResponses, true},
+		{PermissionEditForms, true},
+		{PermissionManage, true},
+		{PermissionViewSettings, false},
+		{PermissionRevokeAccess, false},
+		{PermissionManageMembers, false},
+		{PermissionDeleteTeam, false},
+		{PermissionTransferOwnership, false},
+		{PermissionViewAudit, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.permission), func(t *testing.T) {
+			if got := blockedPermissions[tt.permission]; got != tt.blocked {
+				t.Errorf("got %v, want %v", got, tt.blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// Placeholders recall earlier answers or hidden fields inside question text:
+//
+//	{{q:<questionId>}}  answer to an earlier question
+//	{{h:<name>}}        value of a hidden field
+//	{{v:<name>}}        value of a computed variable, e.g. {{v:score}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z]):([A-Za-z0-9_\-]+)\s*\}\}`)
+
+const (
+	placeholderQuestion = "q"
+	placeholderHidden   = "h"
+	placeholderVariable = "v"
+)
+
+type placeholderRef struct {
+	Kind string
+	Ref  string
+}
+
+func extractPlaceholders(text string) []placeholderRef {
+	var refs []placeholderRef
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		refs = append(refs, placeholderRef{Kind: match[1], Ref: match[2]})
+	}
+	return refs
+}
+
+// pipedTexts returns the texts of a question that may contain placeholders keyed
+// by their field name, including every translation of them
+func pipedTexts(snapshot *types.PublishVersionSnapshot, question *types.PublishedQuestion) map[string][]string {
+	texts := map[string][]string{
+		"title":       {question.Title},
+		"description": {question.Description},
+	}
+	for _, translation := range snapshot.Translations {
+		if qt, ok := translation.Questions[question.ID]; ok {
+			texts["title"] = append(texts["title"], qt.Title)
+			texts["description"] = append(texts["description"], qt.Description)
+		}
+	}
+	return texts
+}
+
+// validatePlaceholders checks every placeholder of a snapshot before it is published.
+// A question placeholder must reference a question that accepts an answer and that
+// comes before the referencing question on every path through the form.
+func validatePlaceholders(snapshot *types.PublishVersionSnapshot) []utils.ValidationErrorResponse {
+
+	flow := newFormFlow(snapshot)
+	dominators := flow.dominators()
+
+	var fieldErrs []utils.ValidationErrorResponse
+	for _, question := range flow.questions {
+		texts := pipedTexts(snapshot, question)
+		fields := make([]string, 0, len(texts))
+		for field := range texts {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			reported := make(map[string]bool)
+			for _, text := range texts[field] {
+				for _, ref := range extractPlaceholders(text) {
+					key := ref.Kind + ":" + ref.Ref
+					if reported[key] {
+						continue
+					}
+					msg := checkPlaceholder(flow, dominators, question, ref)
+					if msg == "" {
+						continue
+					}
+					reported[key] = true
+					fieldErrs = append(fieldErrs, fieldError(fmt.Sprintf("questions.%s.%s", question.ID, field), msg))
+				}
+			}
+		}
+	}
+	return fieldErrs
+}
+
+func checkPlaceholder(flow *formFlow, dominators map[string]map[string]bool, question *types.PublishedQuestion, ref placeholderRef) string {
+	switch ref.Kind {
+	case placeholderHidden:
+		for _, field := range flow.hidden {
+			if field.Name == ref.Ref {
+				return ""
+			}
+		}
+		return fmt.Sprintf("{{h:%s}} references a hidden field that is not declared", ref.Ref)
+	case placeholderVariable:
+		if ref.Ref == scoreVariable {
+			return ""
+		}
+		for _, variable := range flow.variables {
+			if variable.Name == ref.Ref {
+				return ""
+			}
+		}
+		return fmt.Sprintf("{{v:%s}} references a variable that does not exist", ref.Ref)
+	case placeholderQuestion:
+	default:
+		return fmt.Sprintf("{{%s:%s}} is not a known placeholder type", ref.Kind, ref.Ref)
+	}
+
+	source := flow.byId[ref.Ref]
+	if source == nil {
+		return fmt.Sprintf("{{q:%s}} references a question that does not exist", ref.Ref)
+	}
+	if source.ID == question.ID {
+		return fmt.Sprintf("{{q:%s}} cannot reference its own question", ref.Ref)
+	}
+	if !acceptsAnswer(models.QuestionType(source.Type)) {
+		return fmt.Sprintf("{{q:%s}} references a question without an answer", ref.Ref)
+	}
+
+	dom, reachable := dominators[question.ID]
+	if reachable && !dom[source.ID] {
+		return fmt.Sprintf("{{q:%s}} must be answered before this question on every path", ref.Ref)
+	}
+	return ""
+}
+
+// renderPlaceholders replaces placeholders with the respondent's answers. Unknown
+// or unanswered references render as an empty string.
+func renderPlaceholders(text string, flow *formFlow, state *flowState) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := placeholderPattern.FindStringSubmatch(match)
+		switch parts[1] {
+		case placeholderQuestion:
+			question := flow.byId[parts[2]]
+			value, ok := state.answers[parts[2]]
+			if question == nil || !ok {
+				return ""
+			}
+			return formatAnswer(question, value)
+		case placeholderHidden:
+			return state.hidden[parts[2]]
+		case placeholderVariable:
+			value, ok := state.variables[parts[2]]
+			if !ok {
+				return ""
+			}
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		return ""
+	})
+}
+
+// renderQuestion returns a copy of the question with placeholders in its texts rendered
+func renderQuestion(question *types.PublishedQuestion, flow *formFlow, state *flowState) *types.PublishedQuestion {
+	rendered := *question
+	rendered.Title = renderPlaceholders(question.Title, flow, state)
+	rendered.Description = renderPlaceholders(question.Description, flow, state)
+	return &rendered
+}
+
+// formatAnswer turns a normalized answer into display text, using option labels for choices
+func formatAnswer(question *types.PublishedQuestion, value interface{}) string {
+	label := func(v string) string {
+		if option := findOption(question.Options, v); option != nil {
+			return option.Label
+		}
+		return v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return label(v)
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case []string:
+		labels := make([]string, len(v))
+		for i, item := range v {
+			labels[i] = label(item)
+		}
+		return strings.Join(labels, ", ")
+	case []interface{}:
+		labels := make([]string, 0, len(v))
+		for _, item := range v {
+			labels = append(labels, formatAnswer(question, item))
+		}
+		return strings.Join(labels, ", ")
+	case map[string]interface{}:
+		parts := make([]string, 0, len(addressComponents))
+		for _, component := range addressComponents {
+			if text, ok := v[component].(string); ok && text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// prefillQueryParam is the query parameter that carries a prefill token on the public form URL
+const prefillQueryParam = "prefill"
+
+type IPrefillService interface {
+	CreateToken(ctx context.Context, userId string, formId string, body *types.CreatePrefillTokenRequest) (*types.PrefillTokenResponse, error)
+}
+
+type PrefillService struct {
+	formRepo        repositories.IFormRepo
+	formVersionRepo repositories.IFormVersionRepo
+	permissions     IPermissionService
+	cfg             *config.Config
+}
+
+func NewPrefillService(formRepo repositories.IFormRepo, formVersionRepo repositories.IFormVersionRepo, permissions IPermissionService, cfg *config.Config) *PrefillService {
+	return &PrefillService{
+		formRepo:        formRepo,
+		formVersionRepo: formVersionRepo,
+		permissions:     permissions,
+		cfg:             cfg,
+	}
+}
+
+// CreateToken signs answer values for the published version of a form. Values are
+// validated and normalized against the published questions so that the token only
+// carries answers a respondent could have given.
+func (s *PrefillService) CreateToken(ctx context.Context, userId string, formId string, body *types.CreatePrefillTokenRequest) (*types.PrefillTokenResponse, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
+	}
+
+	if form.PublishedVersionID == nil {
+		return nil, errors.BadRequest("Publish the form before creating prefill links")
+	}
+	version, err := s.formVersionRepo.GetByID(ctx, *form.PublishedVersionID)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, errors.BadRequest("Publish the form before creating prefill links")
+	}
+
+	var snapshot types.PublishVersionSnapshot
+	if err := json.Unmarshal([]byte(version.Schema), &snapshot); err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	answers := make(map[string]interface{}, len(body.Fields))
+	var locked []string
+	var fieldErrs []utils.ValidationErrorResponse
+	for i, field := range body.Fields {
+		name := fmt.Sprintf("fields[%d]", i)
+		question := findPublishedQuestion(&snapshot, field.QuestionID)
+		if question == nil {
+			fieldErrs = append(fieldErrs, fieldError(name+".questionId", "Question does not exist in the published form"))
+			continue
+		}
+		if _, duplicate := answers[question.ID]; duplicate {
+			fieldErrs = append(fieldErrs, fieldError(name+".questionId", "Question is prefilled more than once"))
+			continue
+		}
+		if !acceptsAnswer(models.QuestionType(question.Type)) {
+			fieldErrs = append(fieldErrs, fieldError(name+".questionId", "Question does not accept answers"))
+			continue
+		}
+
+		value, errs := ValidateAnswer(form.ID, question, field.Value)
+		if len(errs) > 0 {
+			fieldErrs = append(fieldErrs, errs...)
+			continue
+		}
+		answers[question.ID] = value
+		if field.Locked {
+			locked = append(locked, question.ID)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return nil, errors.ValidationFailed("Some prefill values are invalid", fieldErrs)
+	}
+
+	expiry := s.cfg.Prefill.Expiry
+	if body.ExpiresInSeconds > 0 {
+		expiry = time.Duration(body.ExpiresInSeconds) * time.Second
+	}
+	if expiry > s.cfg.Prefill.MaxExpiry {
+		return nil, errors.BadRequest(fmt.Sprintf("Prefill links can be valid for at most %s", s.cfg.Prefill.MaxExpiry))
+	}
+
+	token, expiresAt, err := utils.GeneratePrefillToken(utils.GenerateUUID(), formId, answers, locked, s.cfg.Prefill.Secret, expiry)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	return &types.PrefillTokenResponse{
+		Token:     token,
+		ExpiresAt: utils.GetIsoDateTime(&expiresAt),
+	}, nil
+}
+
+// verifyPrefill checks a prefill token against the form it is used on and drops values
+// of questions that are no longer part of the published version
+func verifyPrefill(secret string, token string, formId string, snapshot *types.PublishVersionSnapshot) (*utils.PrefillClaims, error) {
+
+	claims, err := utils.ValidatePrefillToken(token, secret)
+	if err != nil || claims.FormID != formId {
+		return nil, errors.BadRequest("Prefill link is invalid or has expired")
+	}
+
+	for questionId := range claims.Answers {
+		if findPublishedQuestion(snapshot, questionId) == nil {
+			delete(claims.Answers, questionId)
+		}
+	}
+	return claims, nil
+}
+
+// applyPrefill fills in the prefilled answers the respondent left out and rejects
+// answers that change a locked value
+func applyPrefill(snapshot *types.PublishVersionSnapshot, claims *utils.PrefillClaims, answers map[string]interface{}) (map[string]interface{}, []utils.ValidationErrorResponse) {
+
+	merged := make(map[string]interface{}, len(answers)+len(claims.Answers))
+	for questionId, value := range answers {
+		merged[questionId] = value
+	}
+
+	var fieldErrs []utils.ValidationErrorResponse
+	for questionId, prefilled := range claims.Answers {
+		submitted, answered := answers[questionId]
+		if !answered {
+			merged[questionId] = prefilled
+			continue
+		}
+		if !containsString(claims.Locked, questionId) {
+			continue
+		}
+
+		question := findPublishedQuestion(snapshot, questionId)
+		normalized, errs := ValidateAnswer(claims.FormID, question, submitted)
+		if len(errs) > 0 || !sameAnswer(normalized, prefilled) {
+			fieldErrs = append(fieldErrs, fieldError("answers."+questionId, "This answer is locked by the prefill link"))
+			continue
+		}
+		merged[questionId] = prefilled
+	}
+	return merged, fieldErrs
+}
+
+// sameAnswer compares two normalized answers through their JSON encoding, since a
+// value read back from a token has lost its Go types
+func sameAnswer(a interface{}, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	var decodedA, decodedB interface{}
+	_ = json.Unmarshal(encodedA, &decodedA)
+	_ = json.Unmarshal(encodedB, &decodedB)
+	return reflect.DeepEqual(decodedA, decodedB)
+}
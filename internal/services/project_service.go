@@ -19,17 +19,29 @@ type IProjectService interface {
 }
 
 type ProjectService struct {
-	projectRepo repositories.IProjectRepo
-	teamRepo    repositories.ITeamRepo
+	projectRepo  repositories.IProjectRepo
+	teamRepo     repositories.ITeamRepo
+	permissions  IPermissionService
+	audit        IAuditService
+	entitlements IEntitlementService
+	usage        IUsageService
 }
 
 func NewProjectService(
 	projectRepo repositories.IProjectRepo,
 	teamRepo repositories.ITeamRepo,
+	permissions IPermissionService,
+	audit IAuditService,
+	entitlements IEntitlementService,
+	usage IUsageService,
 ) *ProjectService {
 	return &ProjectService{
-		projectRepo: projectRepo,
-		teamRepo:    teamRepo,
+		projectRepo:  projectRepo,
+		teamRepo:     teamRepo,
+		permissions:  permissions,
+		audit:        audit,
+		entitlements: entitlements,
+		usage:        usage,
 	}
 }
 
@@ -39,8 +51,8 @@ func (s *ProjectService) Get(ctx context.Context, userId string, pagination *typ
 	if err != nil {
 		return []*types.ProjectResponse{}, 0, errors.NotFound("Team")
 	}
-	if *team.OwnerID != userId {
-		return []*types.ProjectResponse{}, 0, errors.Unauthorized("team's projects")
+	if err := s.permissions.Check(ctx, userId, team, PermissionView); err != nil {
+		return []*types.ProjectResponse{}, 0, err
 	}
 
 	projects, total, err := s.projectRepo.Get(ctx, pagination, teamId)
@@ -58,8 +70,8 @@ func (s *ProjectService) GetById(ctx context.Context, userId string, projectId s
 		return nil, errors.NotFound("Project")
 	}
 
-	if *project.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("project")
+	if err := s.permissions.Check(ctx, userId, &project.Team, PermissionView); err != nil {
+		return nil, err
 	}
 
 	return &types.ProjectResponse{
@@ -79,8 +91,11 @@ func (s *ProjectService) Create(ctx context.Context, userId string, project *typ
 		return types.ProjectResponse{}, errors.NotFound("Team")
 	}
 
-	if *team.OwnerID != userId {
-		return types.ProjectResponse{}, errors.Unauthorized("team")
+	if err := s.permissions.Check(ctx, userId, team, PermissionManage); err != nil {
+		return types.ProjectResponse{}, err
+	}
+	if err := s.entitlements.Check(ctx, team.ID, LimitProjects, 1); err != nil {
+		return types.ProjectResponse{}, err
 	}
 
 	createdProject, err := s.projectRepo.Create(ctx, &models.Project{
@@ -94,6 +109,15 @@ func (s *ProjectService) Create(ctx context.Context, userId string, project *typ
 		return types.ProjectResponse{}, err
 	}
 
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       createdProject.TeamID,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   createdProject.ID,
+		Action:       models.AuditActionCreate,
+		After:        createdProject,
+	})
+	s.usage.Record(ctx, createdProject.TeamID, models.UsageEventProjectCreated, 1, createdProject.ID)
+
 	return types.ProjectResponse{
 		ID:          createdProject.ID,
 		Name:        createdProject.Name,
@@ -111,8 +135,8 @@ func (s *ProjectService) Update(ctx context.Context, userId string, project *typ
 	if err != nil {
 		return false, errors.NotFound("Project")
 	}
-	if *existingProject.Team.OwnerID != userId {
-		return false, errors.Unauthorized("project")
+	if err := s.permissions.Check(ctx, userId, &existingProject.Team, PermissionManage); err != nil {
+		return false, err
 	}
 
 	ok, err := s.projectRepo.Update(ctx, &models.Project{
@@ -123,6 +147,23 @@ func (s *ProjectService) Update(ctx context.Context, userId string, project *typ
 	if err != nil || !ok {
 		return false, err
 	}
+
+	// Unset fields are left unchanged by the update
+	changed := map[string]interface{}{}
+	if project.Name != "" {
+		changed["name"] = project.Name
+	}
+	if project.Description != nil {
+		changed["description"] = *project.Description
+	}
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       existingProject.TeamID,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   existingProject.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       existingProject,
+		After:        changed,
+	})
 	return true, nil
 }
 
@@ -132,12 +173,20 @@ func (s *ProjectService) Delete(ctx context.Context, userId string, projectId st
 	if err != nil {
 		return false, errors.NotFound("Project")
 	}
-	if *existingProject.Team.OwnerID != userId {
-		return false, errors.Unauthorized("project")
+	if err := s.permissions.Check(ctx, userId, &existingProject.Team, PermissionManage); err != nil {
+		return false, err
 	}
 	err = s.projectRepo.Delete(ctx, projectId)
 	if err != nil {
 		return false, err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       existingProject.TeamID,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   existingProject.ID,
+		Action:       models.AuditActionDelete,
+		Before:       existingProject,
+	})
 	return true, nil
 }
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+// metadataSchema is implemented by every typed question metadata struct
+type metadataSchema interface {
+	Validate() []utils.ValidationErrorResponse
+}
+
+// questionMetadataSchemas maps a question type to a constructor of its metadata schema.
+// Types that are not listed accept any JSON object as metadata.
+var questionMetadataSchemas = map[models.QuestionType]func() metadataSchema{
+	models.QuestionTypeTextShort:        func() metadataSchema { return &TextMetadata{} },
+	models.QuestionTypeTextLong:         func() metadataSchema { return &TextMetadata{} },
+	models.QuestionTypeChoiceMultiple:   func() metadataSchema { return &SelectionMetadata{} },
+	models.QuestionTypeChoiceCheckbox:   func() metadataSchema { return &SelectionMetadata{} },
+	models.QuestionTypeInfoPhone:        func() metadataSchema { return &PhoneMetadata{} },
+	models.QuestionTypeUserAddress:      func() metadataSchema { return &AddressMetadata{} },
+	models.QuestionTypeNumber:           func() metadataSchema { return &NumberMetadata{} },
+	models.QuestionTypeRatingStar:       func() metadataSchema { return &RatingStarMetadata{} },
+	models.QuestionTypeFileAny:          func() metadataSchema { return &FileMetadata{} },
+	models.QuestionTypeFileImageOrVideo: func() metadataSchema { return &FileMetadata{mediaOnly: true} },
+	models.QuestionTypeRedirectToUrl:    func() metadataSchema { return &RedirectMetadata{} },
+	models.QuestionTypeDate:             func() metadataSchema { return &DateMetadata{} },
+}
+
+const (
+	minRatingStars = 3
+	maxRatingStars = 10
+	maxFileSizeMB  = 100
+	metadataDate   = "2006-01-02"
+)
+
+var mimeTypePattern = regexp.MustCompile(`^[a-z]+/([a-z0-9.+-]+|\*)$`)
+
+var countryCallingCodePattern = regexp.MustCompile(`^\+?[1-9][0-9]{0,2}$`)
+
+// addressComponents lists the keys a USER_ADDRESS answer may contain
+var addressComponents = []string{"line1", "line2", "city", "state", "postalCode", "country"}
+
+type TextMetadata struct {
+	MinLength      *int    `json:"minLength"`
+	MaxLength      *int    `json:"maxLength"`
+	Pattern        *string `json:"pattern"`
+	PatternMessage *string `json:"patternMessage"`
+}
+
+func (m *TextMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	if m.MinLength != nil && *m.MinLength < 0 {
+		errs = append(errs, fieldError("metadata.minLength", "minLength must not be negative"))
+	}
+	if m.MaxLength != nil && *m.MaxLength < 1 {
+		errs = append(errs, fieldError("metadata.maxLength", "maxLength must be at least 1"))
+	}
+	if m.MinLength != nil && m.MaxLength != nil && *m.MinLength > *m.MaxLength {
+		errs = append(errs, fieldError("metadata.minLength", "minLength must be less than or equal to maxLength"))
+	}
+	if m.Pattern != nil && *m.Pattern != "" {
+		if _, err := regexp.Compile(*m.Pattern); err != nil {
+			errs = append(errs, fieldError("metadata.pattern", "pattern is not a valid regular expression"))
+		}
+	}
+	return errs
+}
+
+type SelectionMetadata struct {
+	MinSelections *int `json:"minSelections"`
+	MaxSelections *int `json:"maxSelections"`
+}
+
+func (m *SelectionMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	if m.MinSelections != nil && *m.MinSelections < 0 {
+		errs = append(errs, fieldError("metadata.minSelections", "minSelections must not be negative"))
+	}
+	if m.MaxSelections != nil && *m.MaxSelections < 1 {
+		errs = append(errs, fieldError("metadata.maxSelections", "maxSelections must be at least 1"))
+	}
+	if m.MinSelections != nil && m.MaxSelections != nil && *m.MinSelections > *m.MaxSelections {
+		errs = append(errs, fieldError("metadata.minSelections", "minSelections must be less than or equal to maxSelections"))
+	}
+	return errs
+}
+
+type PhoneMetadata struct {
+	DefaultCallingCode *string `json:"defaultCallingCode"`
+}
+
+func (m *PhoneMetadata) Validate() []utils.ValidationErrorResponse {
+	if m.DefaultCallingCode != nil && *m.DefaultCallingCode != "" && !countryCallingCodePattern.MatchString(*m.DefaultCallingCode) {
+		return []utils.ValidationErrorResponse{
+			fieldError("metadata.defaultCallingCode", "defaultCallingCode must be a country calling code such as +1 or 91"),
+		}
+	}
+	return nil
+}
+
+type AddressMetadata struct {
+	RequiredComponents []string `json:"requiredComponents"`
+}
+
+func (m *AddressMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	for i, component := range m.RequiredComponents {
+		known := false
+		for _, c := range addressComponents {
+			if c == component {
+				known = true
+				break
+			}
+		}
+		if !known {
+			errs = append(errs, fieldError(
+				fmt.Sprintf("metadata.requiredComponents[%d]", i),
+				"must be one of: "+strings.Join(addressComponents, ", "),
+			))
+		}
+	}
+	return errs
+}
+
+type NumberMetadata struct {
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+	Step *float64 `json:"step"`
+}
+
+func (m *NumberMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	if m.Min != nil && m.Max != nil && *m.Min > *m.Max {
+		errs = append(errs, fieldError("metadata.min", "min must be less than or equal to max"))
+	}
+	if m.Step != nil && *m.Step <= 0 {
+		errs = append(errs, fieldError("metadata.step", "step must be greater than 0"))
+	}
+	return errs
+}
+
+type RatingStarMetadata struct {
+	Stars *int `json:"stars"`
+}
+
+func (m *RatingStarMetadata) Validate() []utils.ValidationErrorResponse {
+	if m.Stars != nil && (*m.Stars < minRatingStars || *m.Stars > maxRatingStars) {
+		return []utils.ValidationErrorResponse{
+			fieldError("metadata.stars", fmt.Sprintf("stars must be between %d and %d", minRatingStars, maxRatingStars)),
+		}
+	}
+	return nil
+}
+
+type FileMetadata struct {
+	AllowedMimeTypes []string `json:"allowedMimeTypes"`
+	MaxSizeMB        *float64 `json:"maxSizeMb"`
+	MaxFiles         *int     `json:"maxFiles"`
+
+	mediaOnly bool
+}
+
+func (m *FileMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	for i, mime := range m.AllowedMimeTypes {
+		field := fmt.Sprintf("metadata.allowedMimeTypes[%d]", i)
+		mime = strings.ToLower(strings.TrimSpace(mime))
+		if !mimeTypePattern.MatchString(mime) {
+			errs = append(errs, fieldError(field, "must be a MIME type such as image/png or image/*"))
+			continue
+		}
+		if m.mediaOnly && !strings.HasPrefix(mime, "image/") && !strings.HasPrefix(mime, "video/") {
+			errs = append(errs, fieldError(field, "only image and video MIME types are allowed"))
+		}
+	}
+	if m.MaxSizeMB != nil && (*m.MaxSizeMB <= 0 || *m.MaxSizeMB > maxFileSizeMB) {
+		errs = append(errs, fieldError("metadata.maxSizeMb", fmt.Sprintf("maxSizeMb must be greater than 0 and at most %d", maxFileSizeMB)))
+	}
+	if m.MaxFiles != nil && *m.MaxFiles < 1 {
+		errs = append(errs, fieldError("metadata.maxFiles", "maxFiles must be at least 1"))
+	}
+	return errs
+}
+
+type RedirectMetadata struct {
+	RedirectURL  *string `json:"redirectUrl"`
+	DelaySeconds *int    `json:"delaySeconds"`
+}
+
+func (m *RedirectMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	if m.RedirectURL != nil && *m.RedirectURL != "" {
+		parsed, err := url.Parse(*m.RedirectURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, fieldError("metadata.redirectUrl", "redirectUrl must be an absolute http(s) URL"))
+		}
+	}
+	if m.DelaySeconds != nil && (*m.DelaySeconds < 0 || *m.DelaySeconds > 60) {
+		errs = append(errs, fieldError("metadata.delaySeconds", "delaySeconds must be between 0 and 60"))
+	}
+	return errs
+}
+
+type DateMetadata struct {
+	MinDate *string `json:"minDate"`
+	MaxDate *string `json:"maxDate"`
+}
+
+func (m *DateMetadata) Validate() []utils.ValidationErrorResponse {
+	var errs []utils.ValidationErrorResponse
+	minDate, minErr := parseMetadataDate(m.MinDate)
+	if minErr != nil {
+		errs = append(errs, fieldError("metadata.minDate", "minDate must be formatted as YYYY-MM-DD"))
+	}
+	maxDate, maxErr := parseMetadataDate(m.MaxDate)
+	if maxErr != nil {
+		errs = append(errs, fieldError("metadata.maxDate", "maxDate must be formatted as YYYY-MM-DD"))
+	}
+	if minDate != nil && maxDate != nil && minDate.After(*maxDate) {
+		errs = append(errs, fieldError("metadata.minDate", "minDate must be on or before maxDate"))
+	}
+	return errs
+}
+
+func parseMetadataDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(metadataDate, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// ValidateQuestionMetadata checks metadata against the schema registered for the question type
+func ValidateQuestionMetadata(questionType models.QuestionType, metadata datatypes.JSON) []utils.ValidationErrorResponse {
+	raw := bytes.TrimSpace(metadata)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		raw = []byte("{}")
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return []utils.ValidationErrorResponse{fieldError("metadata", "metadata must be a JSON object")}
+	}
+
+	schema, err := decodeQuestionMetadata(questionType, raw)
+	if err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			return []utils.ValidationErrorResponse{
+				fieldError("metadata."+typeErr.Field, fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type.String())),
+			}
+		}
+		return []utils.ValidationErrorResponse{fieldError("metadata", "metadata is invalid")}
+	}
+	if schema == nil {
+		return nil
+	}
+	return schema.Validate()
+}
+
+// decodeQuestionMetadata decodes metadata into the schema registered for the question type.
+// It returns nil when the type has no registered schema.
+func decodeQuestionMetadata(questionType models.QuestionType, metadata datatypes.JSON) (metadataSchema, error) {
+	newSchema, ok := questionMetadataSchemas[questionType]
+	if !ok {
+		return nil, nil
+	}
+
+	schema := newSchema()
+	raw := bytes.TrimSpace(metadata)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return schema, nil
+	}
+	if err := json.Unmarshal(raw, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func isValidQuestionType(questionType models.QuestionType) bool {
+	switch questionType {
+	case models.QuestionTypeTextShort, models.QuestionTypeTextLong, models.QuestionTypeNumber,
+		models.QuestionTypeDate, models.QuestionTypeFileAny, models.QuestionTypeFileImageOrVideo,
+		models.QuestionTypeChoiceSingle, models.QuestionTypeChoiceMultiple, models.QuestionTypeChoiceDropdown,
+		models.QuestionTypeChoicePicture, models.QuestionTypeChoiceCheckbox, models.QuestionTypeChoiceBool,
+		models.QuestionTypeInfoEmail, models.QuestionTypeInfoPhone, models.QuestionTypeInfoUrl,
+		models.QuestionTypeUserDetail, models.QuestionTypeUserAddress, models.QuestionTypeScreenWelcome,
+		models.QuestionTypeScreenEnd, models.QuestionTypeScreenStatement, models.QuestionTypeRatingZeroToTen,
+		models.QuestionTypeRatingStar, models.QuestionTypeRatingRank, models.QuestionTypeLegal,
+		models.QuestionTypeRedirectToUrl:
+		return true
+	}
+	return false
+}
+
+func fieldError(field string, message string) utils.ValidationErrorResponse {
+	return utils.ValidationErrorResponse{
+		Field:   field,
+		Message: message,
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
@@ -9,6 +11,7 @@ import (
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
 	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"gorm.io/datatypes"
 )
 
 type IQuestionService interface {
@@ -21,17 +24,29 @@ type IQuestionService interface {
 	CreateOption(ctx context.Context, userId string, formId string, questionId string, option *types.QuestionOptionRequest) (*models.QuestionOption, error)
 	UpdateOption(ctx context.Context, userId string, formId string, questionId string, optionId string, option *types.QuestionOptionRequest) error
 	DeleteOption(ctx context.Context, userId string, formId string, optionId string) error
+
+	BulkCreateOptions(ctx context.Context, userId string, formId string, questionId string, body *types.BulkCreateOptionsRequest) ([]*models.QuestionOption, error)
+	ReplaceOptions(ctx context.Context, userId string, formId string, questionId string, body *types.ReplaceOptionsRequest) ([]*models.QuestionOption, error)
+	UpdateOptionSequence(ctx context.Context, userId string, formId string, questionId string, sequence []*types.SequenceItem) error
+
+	TransferQuestions(ctx context.Context, userId string, formId string, body *types.TransferQuestionsRequest) (*types.TransferQuestionsResponse, error)
 }
 
 type QuestionService struct {
 	questionRepo repositories.IQuestionRepo
 	formRepo     repositories.IFormRepo
+	edgeRepo     repositories.IEdgeRepo
+	permissions  IPermissionService
+	audit        IAuditService
 }
 
-func NewQuestionService(questionRepo repositories.IQuestionRepo, formRepo repositories.IFormRepo) *QuestionService {
+func NewQuestionService(questionRepo repositories.IQuestionRepo, formRepo repositories.IFormRepo, edgeRepo repositories.IEdgeRepo, permissions IPermissionService, audit IAuditService) *QuestionService {
 	return &QuestionService{
 		questionRepo: questionRepo,
 		formRepo:     formRepo,
+		edgeRepo:     edgeRepo,
+		permissions:  permissions,
+		audit:        audit,
 	}
 }
 
@@ -46,8 +61,8 @@ func (s *QuestionService) GetQuestions(ctx context.Context, userId string, formI
 		return nil, errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionView); err != nil {
+		return nil, err
 	}
 
 	questions, err := s.questionRepo.GetQuestions(ctx, formId)
@@ -69,8 +84,17 @@ func (s *QuestionService) CreateQuestion(ctx context.Context, userId string, for
 		return nil, errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
+	}
+
+	if !isValidQuestionType(question.Type) {
+		return nil, errors.ValidationFailed("", []utils.ValidationErrorResponse{
+			fieldError("type", "type is not a supported question type"),
+		})
+	}
+	if fieldErrs := ValidateQuestionMetadata(question.Type, question.Metadata); len(fieldErrs) > 0 {
+		return nil, errors.ValidationFailed("Invalid question metadata", fieldErrs)
 	}
 
 	queModel := &models.Question{
@@ -93,6 +117,14 @@ func (s *QuestionService) CreateQuestion(ctx context.Context, userId string, for
 	if err != nil {
 		return nil, err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceQuestion,
+		ResourceID:   createdQuestion.ID,
+		Action:       models.AuditActionCreate,
+		After:        createdQuestion,
+	})
 	return createdQuestion, nil
 }
 
@@ -107,8 +139,16 @@ func (s *QuestionService) UpdateQuestion(ctx context.Context, userId string, for
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
+	}
+
+	existing, err := s.questionRepo.GetQuestionById(ctx, formId, questionId)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.NotFound("Question")
 	}
 
 	updates := make(map[string]interface{})
@@ -140,10 +180,51 @@ func (s *QuestionService) UpdateQuestion(ctx context.Context, userId string, for
 		updates["sortOrder"] = (*question)["sortOrder"]
 	}
 
+	// A type or metadata change is checked against the resulting combination so
+	// switching types cannot leave metadata that is invalid for the new type.
+	_, typeChanged := updates["type"]
+	_, metadataChanged := updates["metadata"]
+	if typeChanged || metadataChanged {
+		questionType := existing.Type
+		if typeChanged {
+			typeStr, ok := updates["type"].(string)
+			if !ok || !isValidQuestionType(models.QuestionType(typeStr)) {
+				return errors.ValidationFailed("", []utils.ValidationErrorResponse{
+					fieldError("type", "type is not a supported question type"),
+				})
+			}
+			questionType = models.QuestionType(typeStr)
+			updates["type"] = questionType
+		}
+
+		metadata := existing.Metadata
+		if metadataChanged {
+			metadataBytes, err := json.Marshal(updates["metadata"])
+			if err != nil {
+				return errors.BadRequest("Invalid metadata")
+			}
+			metadata = datatypes.JSON(metadataBytes)
+			updates["metadata"] = metadata
+		}
+
+		if fieldErrs := ValidateQuestionMetadata(questionType, metadata); len(fieldErrs) > 0 {
+			return errors.ValidationFailed("Invalid question metadata", fieldErrs)
+		}
+	}
+
 	err = s.questionRepo.UpdateQuestion(ctx, questionId, &updates)
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceQuestion,
+		ResourceID:   existing.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       existing,
+		After:        updates,
+	})
 	return nil
 }
 
@@ -158,14 +239,30 @@ func (s *QuestionService) DeleteQuestion(ctx context.Context, userId string, for
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
+	}
+
+	existing, err := s.questionRepo.GetQuestionById(ctx, formId, questionId)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.NotFound("Question")
 	}
 
 	err = s.questionRepo.DeleteQuestion(ctx, questionId)
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceQuestion,
+		ResourceID:   existing.ID,
+		Action:       models.AuditActionDelete,
+		Before:       existing,
+	})
 	return nil
 }
 
@@ -180,8 +277,8 @@ func (s *QuestionService) GetOptions(ctx context.Context, userId string, formId
 		return nil, errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionView); err != nil {
+		return nil, err
 	}
 	options, err := s.questionRepo.GetOptions(ctx, questionId)
 	if err != nil {
@@ -201,8 +298,8 @@ func (s *QuestionService) CreateOption(ctx context.Context, userId string, formI
 		return nil, errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return nil, errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
 	}
 	optionModel := &models.QuestionOption{
 		ID:         utils.GenerateUUID(),
@@ -210,11 +307,20 @@ func (s *QuestionService) CreateOption(ctx context.Context, userId string, formI
 		Label:      option.Label,
 		Value:      option.Value,
 		SortOrder:  option.SortOrder,
+		Score:      option.Score,
 	}
 	createdOption, err := s.questionRepo.CreateOption(ctx, optionModel)
 	if err != nil {
 		return nil, err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceOption,
+		ResourceID:   createdOption.ID,
+		Action:       models.AuditActionCreate,
+		After:        createdOption,
+	})
 	return createdOption, nil
 }
 
@@ -229,8 +335,8 @@ func (s *QuestionService) UpdateOption(ctx context.Context, userId string, formI
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 
 	updates := make(map[string]interface{})
@@ -243,11 +349,22 @@ func (s *QuestionService) UpdateOption(ctx context.Context, userId string, formI
 	if &option.SortOrder != nil {
 		updates["sort_order"] = option.SortOrder
 	}
+	if option.Score != nil {
+		updates["score"] = *option.Score
+	}
 
 	err = s.questionRepo.UpdateOption(ctx, optionId, &updates)
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceOption,
+		ResourceID:   optionId,
+		Action:       models.AuditActionUpdate,
+		After:        updates,
+	})
 	return nil
 }
 
@@ -262,12 +379,192 @@ func (s *QuestionService) DeleteOption(ctx context.Context, userId string, formI
 		return errors.NotFound("Form")
 	}
 
-	if form.Team.OwnerID == nil || *form.Team.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return err
 	}
 	err = s.questionRepo.DeleteOption(ctx, optionId)
 	if err != nil {
 		return err
 	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceOption,
+		ResourceID:   optionId,
+		Action:       models.AuditActionDelete,
+	})
+	return nil
+}
+
+func (s *QuestionService) BulkCreateOptions(ctx context.Context, userId string, formId string, questionId string, body *types.BulkCreateOptionsRequest) ([]*models.QuestionOption, error) {
+
+	form, question, err := s.getOptionQuestion(ctx, userId, formId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := parseOptionImport(body.Format, body.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.questionRepo.GetOptions(ctx, question.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	items = normalizeOptionItems(items, existing)
+	if len(existing)+len(items) > maxOptionsPerQuestion {
+		return nil, errors.BadRequest(fmt.Sprintf("A question can have at most %d options", maxOptionsPerQuestion))
+	}
+
+	nextOrder := 0
+	for _, option := range existing {
+		if option.SortOrder >= nextOrder {
+			nextOrder = option.SortOrder + 1
+		}
+	}
+
+	options := make([]*models.QuestionOption, len(items))
+	for i, item := range items {
+		options[i] = &models.QuestionOption{
+			ID:         utils.GenerateUUID(),
+			QuestionID: question.ID,
+			Label:      item.Label,
+			Value:      item.Value,
+			SortOrder:  nextOrder + i,
+			Score:      item.Score,
+		}
+	}
+
+	created, err := s.questionRepo.CreateOptions(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceQuestion,
+		ResourceID:   question.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       map[string]interface{}{"options": optionLabels(existing)},
+		After:        map[string]interface{}{"options": append(optionLabels(existing), optionLabels(created)...)},
+	})
+	return created, nil
+}
+
+func (s *QuestionService) ReplaceOptions(ctx context.Context, userId string, formId string, questionId string, body *types.ReplaceOptionsRequest) ([]*models.QuestionOption, error) {
+
+	form, question, err := s.getOptionQuestion(ctx, userId, formId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	items := normalizeOptionItems(optionItemsFromRequest(body.Options), nil)
+	if len(items) > maxOptionsPerQuestion {
+		return nil, errors.BadRequest(fmt.Sprintf("A question can have at most %d options", maxOptionsPerQuestion))
+	}
+
+	existing, err := s.questionRepo.GetOptions(ctx, question.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// An option whose value is kept keeps its ID, so conditions and translations that
+	// refer to it stay valid
+	existingIds := make(map[string]string, len(existing))
+	for _, option := range existing {
+		existingIds[option.Value] = option.ID
+	}
+
+	options := make([]*models.QuestionOption, len(items))
+	for i, item := range items {
+		id, ok := existingIds[item.Value]
+		if !ok {
+			id = utils.GenerateUUID()
+		}
+		options[i] = &models.QuestionOption{
+			ID:         id,
+			QuestionID: question.ID,
+			Label:      item.Label,
+			Value:      item.Value,
+			SortOrder:  i,
+			Score:      item.Score,
+		}
+	}
+
+	replaced, err := s.questionRepo.ReplaceOptions(ctx, question.ID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceQuestion,
+		ResourceID:   question.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       map[string]interface{}{"options": optionLabels(existing)},
+		After:        map[string]interface{}{"options": optionLabels(replaced)},
+	})
+	return replaced, nil
+}
+
+func (s *QuestionService) UpdateOptionSequence(ctx context.Context, userId string, formId string, questionId string, sequence []*types.SequenceItem) error {
+
+	form, question, err := s.getOptionQuestion(ctx, userId, formId, questionId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.questionRepo.UpdateOptionSequence(ctx, question.ID, sequence); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceQuestion,
+		ResourceID:   question.ID,
+		Action:       models.AuditActionUpdate,
+		After:        map[string]interface{}{"optionSequence": sequence},
+	})
 	return nil
 }
+
+// getOptionQuestion loads a question the user can edit, with its form, and checks that
+// its type supports options
+func (s *QuestionService) getOptionQuestion(ctx context.Context, userId string, formId string, questionId string) (*models.Form, *models.Question, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if form == nil {
+		return nil, nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, nil, err
+	}
+
+	question, err := s.questionRepo.GetQuestionById(ctx, formId, questionId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if question == nil {
+		return nil, nil, errors.NotFound("Question")
+	}
+	if !hasOptions(question.Type) {
+		return nil, nil, errors.BadRequest("Question type does not support options")
+	}
+	return form, question, nil
+}
+
+// optionLabels lists option labels in order, to record option changes compactly
+func optionLabels(options []*models.QuestionOption) []string {
+	labels := make([]string, len(options))
+	for i, option := range options {
+		labels[i] = option.Label
+	}
+	return labels
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/mapper"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+const (
+	transferModeCopy = "copy"
+	transferModeMove = "move"
+
+	// transferCanvasGap is the horizontal space left between the target form's
+	// existing nodes and the transferred block on the editor canvas
+	transferCanvasGap = 400
+)
+
+// TransferQuestions copies or moves a set of questions, their options and the edges
+// between them into another form owned by the user. Edges that connect a selected
+// question to one that is not selected cannot follow and are reported as dropped, as
+// are edges whose condition refers to a question that ends up in the other form.
+func (s *QuestionService) TransferQuestions(ctx context.Context, userId string, formId string, body *types.TransferQuestionsRequest) (*types.TransferQuestionsResponse, error) {
+
+	if body.TargetFormID == formId {
+		return nil, errors.BadRequest("Target form must be different from the source form")
+	}
+
+	forms := make([]*models.Form, 2)
+	for i, id := range []string{formId, body.TargetFormID} {
+		form, err := s.formRepo.GetWithTeam(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if form == nil {
+			return nil, errors.NotFound("Form")
+		}
+		if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+			return nil, err
+		}
+		forms[i] = form
+	}
+	source, target := forms[0], forms[1]
+
+	sourceQuestions, err := s.questionRepo.GetQuestions(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(body.QuestionIDs))
+	for _, id := range body.QuestionIDs {
+		selected[id] = true
+	}
+
+	questions := make([]*models.Question, 0, len(selected))
+	for _, question := range sourceQuestions {
+		if selected[question.ID] {
+			questions = append(questions, question)
+		}
+	}
+	if len(questions) != len(selected) {
+		return nil, errors.BadRequest("Some questions do not belong to the source form")
+	}
+
+	sort.SliceStable(questions, func(i, j int) bool {
+		return sortOrderOf(questions[i]) < sortOrderOf(questions[j])
+	})
+
+	sourceEdges, err := s.edgeRepo.Get(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	// refs resolves the question and option IDs of the source form to their question
+	refs := make(map[string]string)
+	for _, question := range sourceQuestions {
+		refs[question.ID] = question.ID
+		for _, option := range question.Options {
+			refs[option.ID] = question.ID
+		}
+	}
+
+	var kept, dropped []*models.Edge
+	var droppedRefs []*types.DroppedReference
+	for _, edge := range sourceEdges {
+		inSource, inTarget := selected[edge.SourceNodeID], selected[edge.TargetNodeID]
+		transferred := inSource && inTarget
+		if inSource != inTarget {
+			dropped = append(dropped, edge)
+			continue
+		}
+		// A moved question leaves the source form, so the edges left behind must not
+		// refer to it either
+		if !transferred && body.Mode != transferModeMove {
+			continue
+		}
+
+		referenced, err := conditionQuestions(edge.Condition, refs)
+		if err != nil {
+			return nil, err
+		}
+		var dangling []string
+		for _, questionId := range referenced {
+			if selected[questionId] != transferred {
+				dangling = append(dangling, questionId)
+			}
+		}
+		if len(dangling) > 0 {
+			dropped = append(dropped, edge)
+			for _, questionId := range dangling {
+				droppedRefs = append(droppedRefs, &types.DroppedReference{EdgeID: edge.ID, QuestionID: questionId})
+			}
+			continue
+		}
+		if transferred {
+			kept = append(kept, edge)
+		}
+	}
+
+	targetQuestions, err := s.questionRepo.GetQuestions(ctx, body.TargetFormID)
+	if err != nil {
+		return nil, err
+	}
+	s.rebaseTransferredQuestions(questions, targetQuestions)
+
+	res := &types.TransferQuestionsResponse{
+		Mode:              body.Mode,
+		TargetFormID:      body.TargetFormID,
+		IDMap:             make(map[string]string, len(questions)),
+		DroppedEdges:      make([]*types.EdgeResponse, len(dropped)),
+		DroppedReferences: droppedRefs,
+	}
+	for i, edge := range dropped {
+		res.DroppedEdges[i] = mapper.ToEdgeResponse(edge)
+	}
+
+	if body.Mode == transferModeMove {
+		dropIds := make([]string, len(dropped))
+		for i, edge := range dropped {
+			dropIds[i] = edge.ID
+		}
+		if err := s.questionRepo.MoveQuestions(ctx, body.TargetFormID, questions, dropIds); err != nil {
+			return nil, err
+		}
+		for _, question := range questions {
+			question.FormID = body.TargetFormID
+			res.IDMap[question.ID] = question.ID
+
+			moved := &AuditEvent{
+				TeamID:       source.TeamID,
+				ResourceType: models.AuditResourceQuestion,
+				ResourceID:   question.ID,
+				Action:       models.AuditActionMove,
+				Before:       map[string]interface{}{"formId": formId},
+				After:        map[string]interface{}{"formId": target.ID},
+			}
+			s.audit.Record(ctx, moved)
+			if target.TeamID != source.TeamID {
+				moved.TeamID = target.TeamID
+				s.audit.Record(ctx, moved)
+			}
+		}
+		res.Questions = questions
+		return res, nil
+	}
+
+	copies, options, edges, err := cloneQuestions(body.TargetFormID, questions, kept, res.IDMap)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.questionRepo.CopyQuestions(ctx, copies, options, edges); err != nil {
+		return nil, err
+	}
+	for _, copied := range copies {
+		s.audit.Record(ctx, &AuditEvent{
+			TeamID:       target.TeamID,
+			ResourceType: models.AuditResourceQuestion,
+			ResourceID:   copied.ID,
+			Action:       models.AuditActionCreate,
+			After:        copied,
+		})
+	}
+	res.Questions = copies
+	return res, nil
+}
+
+// rebaseTransferredQuestions appends the questions after the target form's last
+// question and shifts the block to the right of the target's canvas, keeping the
+// relative layout of the selection intact
+func (s *QuestionService) rebaseTransferredQuestions(questions []*models.Question, target []*models.Question) {
+
+	nextOrder := 0
+	for _, question := range target {
+		if order := sortOrderOf(question); order >= nextOrder {
+			nextOrder = order + 1
+		}
+	}
+
+	minX, minY := questions[0].PosX, questions[0].PosY
+	for _, question := range questions {
+		minX = min(minX, question.PosX)
+		minY = min(minY, question.PosY)
+	}
+
+	offsetX, offsetY := 0, 0
+	if len(target) > 0 {
+		maxX, targetMinY := target[0].PosX, target[0].PosY
+		for _, question := range target {
+			maxX = max(maxX, question.PosX)
+			targetMinY = min(targetMinY, question.PosY)
+		}
+		offsetX = maxX + transferCanvasGap - minX
+		offsetY = targetMinY - minY
+	}
+
+	for i, question := range questions {
+		order := nextOrder + i
+		question.SortOrder = &order
+		question.PosX += offsetX
+		question.PosY += offsetY
+	}
+}
+
+// cloneQuestions re-keys questions, options and internal edges for the target form.
+// idMap is filled with old question id -> new question id.
+func cloneQuestions(targetFormId string, questions []*models.Question, edges []*models.Edge, idMap map[string]string) ([]*models.Question, []*models.QuestionOption, []*models.Edge, error) {
+
+	// conditionIds maps the old question and option IDs conditions may refer to
+	conditionIds := make(map[string]string)
+	for _, question := range questions {
+		idMap[question.ID] = utils.GenerateUUID()
+		conditionIds[question.ID] = idMap[question.ID]
+	}
+
+	copies := make([]*models.Question, len(questions))
+	var options []*models.QuestionOption
+	for i, question := range questions {
+		copies[i] = &models.Question{
+			ID:          idMap[question.ID],
+			FormID:      targetFormId,
+			Title:       question.Title,
+			Placeholder: question.Placeholder,
+			Description: question.Description,
+			Required:    question.Required,
+			Type:        question.Type,
+			Metadata:    question.Metadata,
+			PosX:        question.PosX,
+			PosY:        question.PosY,
+			SortOrder:   question.SortOrder,
+		}
+		for _, option := range question.Options {
+			conditionIds[option.ID] = utils.GenerateUUID()
+			options = append(options, &models.QuestionOption{
+				ID:         conditionIds[option.ID],
+				QuestionID: copies[i].ID,
+				Label:      option.Label,
+				Value:      option.Value,
+				SortOrder:  option.SortOrder,
+				Score:      option.Score,
+			})
+		}
+	}
+
+	clonedEdges := make([]*models.Edge, len(edges))
+	for i, edge := range edges {
+		condition, err := remapCondition(edge.Condition, conditionIds)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		clonedEdges[i] = &models.Edge{
+			ID:           utils.GenerateUUID(),
+			FormID:       targetFormId,
+			SourceNodeID: idMap[edge.SourceNodeID],
+			TargetNodeID: idMap[edge.TargetNodeID],
+			Condition:    condition,
+		}
+	}
+
+	return copies, options, clonedEdges, nil
+}
+
+// parseCondition decodes an edge condition into plain JSON values
+func parseCondition(condition *interface{}) (interface{}, error) {
+
+	raw, err := json.Marshal(*condition)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, errors.Internal(err)
+	}
+	return parsed, nil
+}
+
+// mapConditionStrings returns a copy of a parsed condition with every string value,
+// such as a field or an option value, replaced by fn
+func mapConditionStrings(node interface{}, fn func(string) string) interface{} {
+	switch v := node.(type) {
+	case string:
+		return fn(v)
+	case []interface{}:
+		mapped := make([]interface{}, len(v))
+		for i, item := range v {
+			mapped[i] = mapConditionStrings(item, fn)
+		}
+		return mapped
+	case map[string]interface{}:
+		mapped := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			mapped[key] = mapConditionStrings(item, fn)
+		}
+		return mapped
+	}
+	return node
+}
+
+// conditionQuestions lists the questions an edge condition refers to, by their own ID
+// or the ID of one of their options. refs maps those IDs to the question's ID.
+func conditionQuestions(condition *interface{}, refs map[string]string) ([]string, error) {
+	if condition == nil {
+		return nil, nil
+	}
+
+	parsed, err := parseCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	var questionIds []string
+	seen := make(map[string]bool)
+	mapConditionStrings(parsed, func(value string) string {
+		if questionId, ok := refs[value]; ok && !seen[questionId] {
+			seen[questionId] = true
+			questionIds = append(questionIds, questionId)
+		}
+		return value
+	})
+	sort.Strings(questionIds)
+	return questionIds, nil
+}
+
+// remapCondition rewrites the question and option IDs an edge condition refers to, so
+// that copied branches keep pointing at the copied questions and options. Only whole
+// values are replaced, never parts of a longer string.
+func remapCondition(condition *interface{}, idMap map[string]string) (*interface{}, error) {
+	if condition == nil {
+		return nil, nil
+	}
+
+	parsed, err := parseCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	remapped := mapConditionStrings(parsed, func(value string) string {
+		if newId, ok := idMap[value]; ok {
+			return newId
+		}
+		return value
+	})
+	return &remapped, nil
+}
+
+func sortOrderOf(question *models.Question) int {
+	if question.SortOrder == nil {
+		return 0
+	}
+	return *question.SortOrder
+}
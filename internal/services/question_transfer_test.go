@@ -0,0 +1,74 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemapCondition(t *testing.T) {
+	idMap := map[string]string{
+		"q1":    "new-q1",
+		"opt-a": "new-opt-a",
+	}
+
+	tests := []struct {
+		name      string
+		condition interface{}
+		want      interface{}
+	}{
+		{"field", map[string]interface{}{"field": "q1", "operator": "eq", "value": "opt-a"}, map[string]interface{}{"field": "new-q1", "operator": "eq", "value": "new-opt-a"}},
+		{"nested group", map[string]interface{}{"any": []interface{}{map[string]interface{}{"field": "q1", "operator": "in", "value": []interface{}{"opt-a", "other"}}}}, map[string]interface{}{"any": []interface{}{map[string]interface{}{"field": "new-q1", "operator": "in", "value": []interface{}{"new-opt-a", "other"}}}}},
+		{"id inside a longer string", map[string]interface{}{"field": "q1", "operator": "contains", "value": "q1 and opt-a"}, map[string]interface{}{"field": "new-q1", "operator": "contains", "value": "q1 and opt-a"}},
+		{"numbers and booleans", map[string]interface{}{"field": "q1", "operator": "gt", "value": 3.0, "negate": false}, map[string]interface{}{"field": "new-q1", "operator": "gt", "value": 3.0, "negate": false}},
+		{"empty", map[string]interface{}{}, map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := remapCondition(&tt.condition, idMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %#v, want %#v", *got, tt.want)
+			}
+		})
+	}
+
+	if got, err := remapCondition(nil, idMap); got != nil || err != nil {
+		t.Errorf("nil condition: got %v, %v", got, err)
+	}
+}
+
+func TestConditionQuestions(t *testing.T) {
+	refs := map[string]string{
+		"q1":    "q1",
+		"q2":    "q2",
+		"opt-a": "q1",
+		"opt-b": "q2",
+	}
+
+	tests := []struct {
+		name      string
+		condition interface{}
+		want      []string
+	}{
+		{"field", map[string]interface{}{"field": "q2", "operator": "not_empty"}, []string{"q2"}},
+		{"option of another question", map[string]interface{}{"field": "q1", "operator": "eq", "value": "opt-b"}, []string{"q1", "q2"}},
+		{"each question once", map[string]interface{}{"all": []interface{}{map[string]interface{}{"field": "q1", "operator": "eq", "value": "opt-a"}, map[string]interface{}{"field": "q1", "operator": "not_empty"}}}, []string{"q1"}},
+		{"hidden field", map[string]interface{}{"field": "h:utm_source", "operator": "eq", "value": "ads"}, nil},
+		{"unknown id", map[string]interface{}{"field": "q9", "operator": "empty"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conditionQuestions(&tt.condition, refs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"gorm.io/datatypes"
+)
+
+const (
+	formMetadataQuiz = "quiz"
+
+	// quizTimeGrace absorbs network latency between the respondent's last answer and the submit request
+	quizTimeGrace = 5 * time.Second
+
+	defaultQuizPoints = 1.0
+)
+
+// readQuizSettings decodes Form.Metadata["quiz"], returning nil when quiz mode is not configured
+func readQuizSettings(metadata datatypes.JSON) (*types.QuizSettings, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	var decoded struct {
+		Quiz *types.QuizSettings `json:"quiz"`
+	}
+	if err := json.Unmarshal(metadata, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded.Quiz, nil
+}
+
+func activeQuiz(metadata datatypes.JSON) *types.QuizSettings {
+	quiz, err := readQuizSettings(metadata)
+	if err != nil || quiz == nil || !quiz.Enabled {
+		return nil
+	}
+	return quiz
+}
+
+// publicFormMetadata removes the quiz answer key from metadata sent to respondents
+func publicFormMetadata(metadata datatypes.JSON) datatypes.JSON {
+	if len(metadata) == 0 {
+		return metadata
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(metadata, &decoded); err != nil {
+		return metadata
+	}
+	quiz, ok := decoded[formMetadataQuiz].(map[string]interface{})
+	if !ok {
+		return metadata
+	}
+	delete(quiz, "questions")
+
+	public, err := json.Marshal(decoded)
+	if err != nil {
+		return metadata
+	}
+	return datatypes.JSON(public)
+}
+
+// validateQuizSettings checks the answer key against the form's questions
+func validateQuizSettings(quiz *types.QuizSettings, questions []*models.Question) []utils.ValidationErrorResponse {
+	byId := make(map[string]*models.Question, len(questions))
+	for _, question := range questions {
+		byId[question.ID] = question
+	}
+
+	var fieldErrs []utils.ValidationErrorResponse
+	for questionId, key := range quiz.Questions {
+		field := "questions." + questionId
+		question := byId[questionId]
+		if question == nil {
+			fieldErrs = append(fieldErrs, fieldError(field, "Question does not exist in this form"))
+			continue
+		}
+		if !isGradable(question.Type) {
+			fieldErrs = append(fieldErrs, fieldError(field, fmt.Sprintf("%s questions cannot be graded", question.Type)))
+			continue
+		}
+
+		if hasOptions(question.Type) {
+			for _, value := range key.Correct {
+				found := false
+				for _, option := range question.Options {
+					if option.Value == value {
+						found = true
+						break
+					}
+				}
+				if !found {
+					fieldErrs = append(fieldErrs, fieldError(field+".correct", fmt.Sprintf("%q is not an option of this question", value)))
+				}
+			}
+			if (question.Type == models.QuestionTypeChoiceSingle || question.Type == models.QuestionTypeChoiceDropdown ||
+				question.Type == models.QuestionTypeChoicePicture) && len(key.Correct) > 1 {
+				fieldErrs = append(fieldErrs, fieldError(field+".correct", "Single choice questions have exactly one correct option"))
+			}
+		}
+	}
+	return fieldErrs
+}
+
+func isGradable(questionType models.QuestionType) bool {
+	switch questionType {
+	case models.QuestionTypeFileAny, models.QuestionTypeFileImageOrVideo, models.QuestionTypeUserAddress,
+		models.QuestionTypeUserDetail, models.QuestionTypeLegal:
+		return false
+	}
+	return acceptsAnswer(questionType)
+}
+
+// gradeResponse grades the keyed questions on the respondent's path. Unanswered
+// questions score zero. Multiple selections must match the key exactly.
+func gradeResponse(quiz *types.QuizSettings, flow *formFlow, state *flowState) *types.QuizGrade {
+	grade := &types.QuizGrade{
+		Questions: make(map[string]*types.QuizQuestionGrade),
+	}
+
+	for _, question := range flow.path(state) {
+		key, ok := quiz.Questions[question.ID]
+		if !ok {
+			continue
+		}
+
+		points := defaultQuizPoints
+		if key.Points != nil {
+			points = *key.Points
+		}
+		grade.MaxScore += points
+
+		result := &types.QuizQuestionGrade{
+			Correct: isCorrectAnswer(question, key, state.answers[question.ID]),
+		}
+		if result.Correct {
+			result.Points = points
+			grade.Score += points
+		}
+		if quiz.ShowCorrectAnswers {
+			result.Answer = key.Correct
+		}
+		grade.Questions[question.ID] = result
+	}
+
+	if grade.MaxScore > 0 {
+		grade.Percent = math.Round(grade.Score/grade.MaxScore*10000) / 100
+	}
+	if quiz.PassPercent != nil {
+		passed := grade.Percent >= *quiz.PassPercent
+		grade.Passed = &passed
+	}
+	return grade
+}
+
+func isCorrectAnswer(question *types.PublishedQuestion, key *types.QuizQuestion, answer interface{}) bool {
+	if isEmptyAnswer(answer) {
+		return false
+	}
+
+	switch models.QuestionType(question.Type) {
+	case models.QuestionTypeChoiceMultiple, models.QuestionTypeChoiceCheckbox:
+		selected := answerStrings(answer)
+		if len(selected) != len(key.Correct) {
+			return false
+		}
+		for _, value := range selected {
+			if !containsString(key.Correct, value) {
+				return false
+			}
+		}
+		return true
+
+	case models.QuestionTypeRatingRank:
+		ranked := answerStrings(answer)
+		if len(ranked) != len(key.Correct) {
+			return false
+		}
+		for i := range ranked {
+			if ranked[i] != key.Correct[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Numbers compare numerically, everything else as case-insensitive text
+	for _, accepted := range key.Correct {
+		accepted = strings.TrimSpace(accepted)
+		if conditionEquals(answer, accepted) || strings.EqualFold(fmt.Sprint(answer), accepted) {
+			return true
+		}
+	}
+	return false
+}
+
+func answerStrings(answer interface{}) []string {
+	switch v := answer.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case string:
+		return []string{v}
+	}
+	return nil
+}
+
+// quizDeadline returns when a timed quiz started at startedAt must be submitted
+func quizDeadline(quiz *types.QuizSettings, startedAt time.Time) *time.Time {
+	if quiz == nil || quiz.TimeLimitSeconds <= 0 {
+		return nil
+	}
+	deadline := startedAt.Add(time.Duration(quiz.TimeLimitSeconds) * time.Second)
+	return &deadline
+}
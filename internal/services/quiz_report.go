@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+)
+
+// GetQuizReport aggregates the grades of completed responses into a per-question
+// difficulty report, hardest questions first
+func (s *DashService) GetQuizReport(ctx context.Context, userId string, formId string) (*types.QuizReport, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionViewResponses); err != nil {
+		return nil, err
+	}
+
+	quiz, err := readQuizSettings(form.Metadata)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	if quiz == nil {
+		return nil, errors.BadRequest("Quiz mode is not configured for this form")
+	}
+
+	questions, err := s.dashRepo.GetQuestions(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*types.QuestionDifficulty)
+	for _, question := range questions {
+		if _, keyed := quiz.Questions[question.ID]; keyed {
+			stats[question.ID] = &types.QuestionDifficulty{QuestionID: question.ID, Title: question.Title}
+		}
+	}
+
+	report := &types.QuizReport{}
+	percentSum := 0.0
+	passed := 0
+	err = s.dashRepo.EachResponse(ctx, formId, func(response *models.Response) error {
+		if response.SubmittedAt == nil || len(response.MetaData) == 0 {
+			return nil
+		}
+		var meta struct {
+			Grade *types.QuizGrade `json:"grade"`
+		}
+		if err := json.Unmarshal(response.MetaData, &meta); err != nil || meta.Grade == nil {
+			return nil
+		}
+
+		report.Responses++
+		percentSum += meta.Grade.Percent
+		if meta.Grade.Passed != nil && *meta.Grade.Passed {
+			passed++
+		}
+		for questionId, result := range meta.Grade.Questions {
+			stat, ok := stats[questionId]
+			if !ok {
+				continue
+			}
+			stat.Attempts++
+			if result.Correct {
+				stat.Correct++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Responses > 0 {
+		report.AvgPercent = math.Round(percentSum/float64(report.Responses)*100) / 100
+		if quiz.PassPercent != nil {
+			rate := math.Round(float64(passed)/float64(report.Responses)*10000) / 100
+			report.PassRate = &rate
+		}
+	}
+
+	report.Questions = make([]*types.QuestionDifficulty, 0, len(stats))
+	for _, stat := range stats {
+		if stat.Attempts > 0 {
+			stat.PercentCorrect = math.Round(float64(stat.Correct)/float64(stat.Attempts)*10000) / 100
+		}
+		report.Questions = append(report.Questions, stat)
+	}
+	sort.Slice(report.Questions, func(i, j int) bool {
+		if report.Questions[i].PercentCorrect != report.Questions[j].PercentCorrect {
+			return report.Questions[i].PercentCorrect < report.Questions[j].PercentCorrect
+		}
+		return report.Questions[i].QuestionID < report.Questions[j].QuestionID
+	})
+
+	return report, nil
+}
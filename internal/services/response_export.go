@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// ExportResponses renders the form's responses as CSV with one column per answerable
+// question, then one column per declared hidden field and one per computed variable
+func (s *DashService) ExportResponses(ctx context.Context, userId string, formId string) ([]byte, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionViewResponses); err != nil {
+		return nil, err
+	}
+
+	questions, err := s.dashRepo.GetQuestions(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]*types.PublishedQuestion, 0, len(questions))
+	for _, question := range questions {
+		if !acceptsAnswer(question.Type) {
+			continue
+		}
+		columns = append(columns, exportQuestion(question))
+	}
+
+	variableNames := []string{scoreVariable}
+	declared, err := readFormVariables(form.Metadata)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	for _, variable := range declared {
+		variableNames = append(variableNames, variable.Name)
+	}
+
+	hiddenFields, err := readHiddenFields(form.Metadata)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	hiddenNames := hiddenFieldNames(hiddenFields)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"Response ID", "Status", "Started At", "Submitted At"}
+	for _, question := range columns {
+		header = append(header, question.Title)
+	}
+	header = append(header, hiddenNames...)
+	header = append(header, variableNames...)
+	if err := writer.Write(csvSafeRow(header)); err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	err = s.dashRepo.EachResponse(ctx, formId, func(response *models.Response) error {
+		answers := make(map[string]interface{})
+		if len(response.Data) > 0 {
+			_ = json.Unmarshal(response.Data, &answers)
+		}
+		var meta struct {
+			Hidden map[string]string `json:"hidden"`
+		}
+		if len(response.MetaData) > 0 {
+			_ = json.Unmarshal(response.MetaData, &meta)
+		}
+		variables := make(map[string]float64)
+		if len(response.Variables) > 0 {
+			_ = json.Unmarshal(response.Variables, &variables)
+		}
+
+		status := ""
+		if response.Status != nil {
+			status = string(*response.Status)
+		}
+		row := []string{
+			response.ID,
+			status,
+			utils.GetIsoDateTime(response.StartedAt),
+			utils.GetIsoDateTime(response.SubmittedAt),
+		}
+		for _, question := range columns {
+			row = append(row, formatAnswer(question, answers[question.ID]))
+		}
+		for _, name := range hiddenNames {
+			row = append(row, meta.Hidden[name])
+		}
+		for _, name := range variableNames {
+			value, ok := variables[name]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.FormatFloat(value, 'f', -1, 64))
+		}
+		if err := writer.Write(csvSafeRow(row)); err != nil {
+			return errors.Internal(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, errors.Internal(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// exportQuestion keeps what formatAnswer needs from an editor question
+func exportQuestion(question *models.Question) *types.PublishedQuestion {
+	options := make([]types.PublishedQuestionOption, len(question.Options))
+	for i, option := range question.Options {
+		options[i] = types.PublishedQuestionOption{
+			ID:    option.ID,
+			Label: option.Label,
+			Value: option.Value,
+		}
+	}
+	return &types.PublishedQuestion{
+		ID:      question.ID,
+		Title:   question.Title,
+		Type:    string(question.Type),
+		Options: options,
+	}
+}
+
+// csvSafeRow escapes cells that spreadsheets would run as formulas. Answers, hidden
+// values and titles are written by respondents and editors, so a cell starting with
+// =, +, -, @, a tab or a carriage return gets a leading quote. Plain numbers, such as
+// negative variables, are left as they are.
+func csvSafeRow(row []string) []string {
+	for i, cell := range row {
+		if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			continue
+		}
+		row[i] = "'" + cell
+	}
+	return row
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxJobAttempts = 5
+	jobRetryDelay  = time.Minute
+)
+
+// Scheduler runs due scheduled jobs, purges the trash and old audit entries, aggregates
+// usage and advances billing cycles. Every API instance runs one; jobs are claimed with
+// row locks so each job runs on a single instance, purging and aggregating are
+// idempotent, and a cycle is only rolled by the instance whose update lands first.
+type Scheduler struct {
+	jobRepo        repositories.IJobRepo
+	formService    *FormService
+	trashService   *TrashService
+	auditService   *AuditService
+	usageService   *UsageService
+	billingService *BillingService
+	cfg            *config.Config
+}
+
+func NewScheduler(jobRepo repositories.IJobRepo, formService *FormService, trashService *TrashService, auditService *AuditService, usageService *UsageService, billingService *BillingService, cfg *config.Config) *Scheduler {
+	return &Scheduler{
+		jobRepo:        jobRepo,
+		formService:    formService,
+		trashService:   trashService,
+		auditService:   auditService,
+		usageService:   usageService,
+		billingService: billingService,
+		cfg:            cfg,
+	}
+}
+
+// Start polls for due jobs until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Scheduler.Interval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(s.cfg.Trash.PurgeInterval)
+	defer purgeTicker.Stop()
+	usageTicker := time.NewTicker(s.cfg.Usage.AggregateInterval)
+	defer usageTicker.Stop()
+	billingTicker := time.NewTicker(s.cfg.Billing.Interval)
+	defer billingTicker.Stop()
+
+	s.RunDue(ctx)
+	s.purge(ctx)
+	s.aggregateUsage(ctx)
+	s.advanceBilling(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunDue(ctx)
+		case <-purgeTicker.C:
+			s.purge(ctx)
+		case <-usageTicker.C:
+			s.aggregateUsage(ctx)
+		case <-billingTicker.C:
+			s.advanceBilling(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) purge(ctx context.Context) {
+	if err := s.trashService.Purge(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to purge trash")
+	}
+	if err := s.auditService.Purge(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to purge audit log")
+	}
+}
+
+func (s *Scheduler) aggregateUsage(ctx context.Context) {
+	if err := s.usageService.Aggregate(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to aggregate usage")
+	}
+}
+
+func (s *Scheduler) advanceBilling(ctx context.Context) {
+	if err := s.billingService.Run(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to advance billing cycles")
+	}
+}
+
+func (s *Scheduler) RunDue(ctx context.Context) {
+	s.scheduleMissing(ctx)
+
+	jobs, err := s.jobRepo.ClaimDue(ctx, time.Now(), s.cfg.Scheduler.Lease, s.cfg.Scheduler.BatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to claim scheduled jobs")
+		return
+	}
+
+	for _, job := range jobs {
+		s.run(ctx, job)
+	}
+}
+
+// scheduleMissing queues the OPEN and CLOSE jobs of forms whose openAt or closeAt was
+// set without one, such as forms saved before scheduling existed. Enqueue merges into
+// an existing pending job, so instances sweeping at the same time do not duplicate it.
+func (s *Scheduler) scheduleMissing(ctx context.Context) {
+	now := time.Now()
+	for _, jobType := range []models.ScheduledJobType{models.ScheduledJobOpen, models.ScheduledJobClose} {
+		forms, err := s.jobRepo.GetFormsMissingJob(ctx, jobType, now, s.cfg.Scheduler.BatchSize)
+		if err != nil {
+			log.Error().Err(err).Str("type", string(jobType)).Msg("Failed to find forms missing a scheduled job")
+			continue
+		}
+
+		for _, form := range forms {
+			runAt := now
+			if jobType == models.ScheduledJobOpen {
+				runAt = *form.OpenAt
+			} else if form.CloseAt.After(now) {
+				runAt = *form.CloseAt
+			}
+			err := s.jobRepo.Enqueue(ctx, &models.ScheduledJob{
+				ID:        utils.GenerateUUID(),
+				FormID:    form.ID,
+				Type:      jobType,
+				Status:    models.ScheduledJobStatusPending,
+				RunAt:     runAt,
+				CreatedBy: systemActor,
+				CreatedAt: now,
+			})
+			if err != nil {
+				log.Error().Err(err).Str("formId", form.ID).Str("type", string(jobType)).Msg("Failed to queue scheduled job")
+			}
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job *models.ScheduledJob) {
+	err := s.formService.RunScheduledJob(ctx, job)
+	if err == nil {
+		if err := s.jobRepo.Finish(ctx, job.ID, models.ScheduledJobStatusDone, nil); err != nil {
+			log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to finish scheduled job")
+		}
+		return
+	}
+
+	msg := err.Error()
+	log.Warn().Err(err).Str("jobId", job.ID).Str("formId", job.FormID).Str("type", string(job.Type)).Msg("Scheduled job failed")
+
+	if job.Attempts < maxJobAttempts {
+		retryErr := s.jobRepo.Retry(ctx, job.ID, time.Now().Add(time.Duration(job.Attempts)*jobRetryDelay), msg)
+		if retryErr == nil {
+			return
+		}
+		// Retrying fails when a newer job of the same type is already pending
+		log.Error().Err(retryErr).Str("jobId", job.ID).Msg("Failed to retry scheduled job")
+	}
+	if err := s.jobRepo.Finish(ctx, job.ID, models.ScheduledJobStatusFailed, &msg); err != nil {
+		log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to finish scheduled job")
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+const (
+	minSlugLength = 3
+	maxSlugLength = 63
+
+	// slugRedirectGrace is how long a previous slug keeps redirecting to the form
+	slugRedirectGrace = 30 * 24 * time.Hour
+
+	randomSlugLength   = 8
+	randomSlugAttempts = 5
+)
+
+// reservedSlugs are names of our own hosts and pages that a form cannot take
+var reservedSlugs = map[string]bool{
+	"admin": true, "api": true, "app": true, "assets": true, "auth": true, "billing": true,
+	"blog": true, "cdn": true, "dashboard": true, "docs": true, "escapeform": true, "escform": true,
+	"form": true, "forms": true, "help": true, "hosted": true, "login": true, "logout": true,
+	"mail": true, "preview": true, "signin": true, "signup": true, "static": true, "status": true,
+	"submissions": true, "support": true, "www": true,
+}
+
+// slugFormatError returns why a slug is not usable as a subdomain, or an empty string.
+// Slugs are DNS labels: lowercase letters, digits and single hyphens between them.
+func slugFormatError(slug string) string {
+	if len(slug) < minSlugLength || len(slug) > maxSlugLength {
+		return "Slug must be between 3 and 63 characters"
+	}
+	for _, r := range slug {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return "Slug can only contain lowercase letters, digits and hyphens"
+		}
+	}
+	if strings.HasPrefix(slug, "-") || strings.HasSuffix(slug, "-") {
+		return "Slug cannot start or end with a hyphen"
+	}
+	if strings.Contains(slug, "--") {
+		return "Slug cannot contain consecutive hyphens"
+	}
+	if reservedSlugs[slug] {
+		return "Slug is reserved"
+	}
+	return ""
+}
+
+// CheckSlug reports whether the form could take the slug
+func (s *FormService) CheckSlug(ctx context.Context, userId string, formId string, slug string) (*types.SlugAvailabilityResponse, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
+	}
+
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	res := &types.SlugAvailabilityResponse{Slug: slug}
+
+	if reason := slugFormatError(slug); reason != "" {
+		res.Reason = reason
+		return res, nil
+	}
+
+	taken, err := s.formRepo.IsSlugTaken(ctx, slug, formId)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		res.Reason = "Slug is already taken"
+		return res, nil
+	}
+
+	res.Available = true
+	return res, nil
+}
+
+// UpdateSlug sets a custom slug for the form. The previous slug keeps redirecting to
+// the form for slugRedirectGrace.
+func (s *FormService) UpdateSlug(ctx context.Context, userId string, formId string, body *types.UpdateSlugRequest) (*types.FormResponse, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionEditForms); err != nil {
+		return nil, err
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(body.Slug))
+	if reason := slugFormatError(slug); reason != "" {
+		return nil, errors.BadRequest(reason)
+	}
+	if form.UniqueSubdomain != nil && *form.UniqueSubdomain == slug {
+		return s.GetById(ctx, userId, formId)
+	}
+
+	taken, err := s.formRepo.IsSlugTaken(ctx, slug, formId)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errors.Conflict("Slug is already taken")
+	}
+
+	previous := form.UniqueSubdomain
+	if err := s.formRepo.UpdateSlug(ctx, form, slug, time.Now().Add(slugRedirectGrace)); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       map[string]interface{}{"uniqueSubdomain": previous},
+		After:        map[string]interface{}{"uniqueSubdomain": slug},
+	})
+	return s.GetById(ctx, userId, formId)
+}
+
+// generateSlug picks a random slug that is valid and not in use
+func (s *FormService) generateSlug(ctx context.Context, formId string) (string, error) {
+	for i := 0; i < randomSlugAttempts; i++ {
+		random := utils.GenerateRandomString(randomSlugLength)
+		if random == nil {
+			continue
+		}
+		slug := strings.ToLower(*random)
+		if slugFormatError(slug) != "" {
+			continue
+		}
+		taken, err := s.formRepo.IsSlugTaken(ctx, slug, formId)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+	}
+	return "", errors.Internal(fmt.Errorf("could not generate a free slug after %d attempts", randomSlugAttempts))
+}
@@ -3,65 +3,84 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"time"
 
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
 	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"gorm.io/datatypes"
 )
 
 type ISubmissionService interface {
-	GetForm(ctx context.Context, domain string) (*types.GetSubmissionFormResponse, error)
+	GetForm(ctx context.Context, domain string, locale string, acceptLanguage string, prefillToken string) (*types.GetSubmissionFormResponse, error)
+	Submit(ctx context.Context, domain string, query map[string]string, body *types.SubmitResponseRequest) (*types.SubmitResponseResponse, error)
+	ValidateAnswer(ctx context.Context, domain string, body *types.ValidateAnswerRequest) (*types.ValidateAnswerResponse, error)
+	Start(ctx context.Context, domain string) (*types.StartResponseResponse, error)
+	NextQuestion(ctx context.Context, domain string, locale string, acceptLanguage string, body *types.NextQuestionRequest) (*types.NextQuestionResponse, error)
 }
 
 type SubmissionService struct {
 	formRepo        repositories.IFormRepo
 	formVersionRepo repositories.IFormVersionRepo
+	responseRepo    repositories.IResponseRepo
+	dashRepo        repositories.IDashRepo
+	jobRepo         repositories.IJobRepo
+	entitlements    IEntitlementService
+	usage           IUsageService
+	cfg             *config.Config
 }
 
-func NewSubmissionService(formRepo repositories.IFormRepo, formVersionRepo repositories.IFormVersionRepo) *SubmissionService {
+func NewSubmissionService(formRepo repositories.IFormRepo, formVersionRepo repositories.IFormVersionRepo, responseRepo repositories.IResponseRepo, dashRepo repositories.IDashRepo, jobRepo repositories.IJobRepo, entitlements IEntitlementService, usage IUsageService, cfg *config.Config) *SubmissionService {
 	return &SubmissionService{
 		formRepo:        formRepo,
 		formVersionRepo: formVersionRepo,
+		responseRepo:    responseRepo,
+		dashRepo:        dashRepo,
+		jobRepo:         jobRepo,
+		entitlements:    entitlements,
+		usage:           usage,
+		cfg:             cfg,
 	}
 }
 
-func (s *SubmissionService) GetForm(ctx context.Context, domain string) (*types.GetSubmissionFormResponse, error) {
+func (s *SubmissionService) GetForm(ctx context.Context, domain string, locale string, acceptLanguage string, prefillToken string) (*types.GetSubmissionFormResponse, error) {
 
-	form, err := s.formRepo.GetByDomain(ctx, domain)
+	form, version, snapshot, err := s.getPublishedSnapshot(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
 
-	if form == nil {
-		return nil, errors.NotFound("Form not found")
-	}
-
-	if form.UniqueSubdomain == nil && form.CustomDomain == nil {
-		return nil, errors.BadRequest("Form does not have a publish URL")
-	}
-
-	version, err := s.formVersionRepo.GetLatestVersion(ctx, form.ID)
-	if err != nil {
-		return nil, err
-	}
-	if version == nil {
-		return nil, errors.NotFound("Published version not found")
-	}
+	selected := negotiateLocale(locale, acceptLanguage, snapshot.Locales, snapshot.DefaultLocale)
+	translation := snapshot.Translations[selected]
 
-	var snapshot types.PublishVersionSnapshot
-	err = json.Unmarshal([]byte(version.Schema), &snapshot)
-	if err != nil {
-		return nil, errors.Internal(err)
+	name, description := form.Name, form.Description
+	if translation != nil {
+		if translation.Name != nil {
+			name = *translation.Name
+		}
+		if translation.Description != nil {
+			description = translation.Description
+		}
 	}
 
 	resp := &types.GetSubmissionFormResponse{
 		FormID:      form.ID,
 		PublishedAt: utils.GetIsoDateTime(version.PublishedAt),
 		FormVersion: version.VersionNumber,
+
+		Locale:        selected,
+		DefaultLocale: snapshot.DefaultLocale,
+		Locales:       snapshot.Locales,
+
+		HiddenFields: hiddenFieldNames(snapshot.HiddenFields),
+
 		FormMetadata: &types.SubmissionFormMetadata{
-			Name:                form.Name,
-			Description:         form.Description,
+			Name:                name,
+			Description:         description,
 			Theme:               form.Theme,
 			LogoURL:             form.LogoURL,
 			RequireConsent:      form.RequireConsent,
@@ -69,11 +88,466 @@ func (s *SubmissionService) GetForm(ctx context.Context, domain string) (*types.
 			MultipleSubmissions: form.MultipleSubmissions,
 			PasswordProtected:   form.PasswordProtected,
 			FormPageType:        string(form.FormPageType),
-			Metadata:            form.Metadata,
+			Metadata:            publicFormMetadata(form.Metadata),
 		},
-		Questions: snapshot.Questions,
+		Questions: translateQuestions(snapshot.Questions, translation),
 		Edges:     snapshot.Edges,
 	}
 
+	if prefillToken != "" {
+		claims, err := verifyPrefill(s.cfg.Prefill.Secret, prefillToken, form.ID, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		resp.Prefill = &types.PrefillValues{
+			Answers: claims.Answers,
+			Locked:  claims.Locked,
+		}
+	}
+
 	return resp, nil
 }
+
+// Submit stores a completed response. Hidden field values and the prefill token are read
+// from the query parameters of the submission and from the request body, the body taking
+// precedence.
+func (s *SubmissionService) Submit(ctx context.Context, domain string, query map[string]string, body *types.SubmitResponseRequest) (*types.SubmitResponseResponse, error) {
+
+	form, version, snapshot, err := s.getPublishedSnapshot(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.Status == nil || *form.Status != models.FormStatusPublished {
+		return nil, errors.BadRequest("Form is not accepting responses")
+	}
+
+	if form.MaxResponses != nil {
+		count, err := s.responseRepo.CountCompleted(ctx, form.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(*form.MaxResponses) {
+			return nil, errors.BadRequest("Form has reached its response limit")
+		}
+	}
+	if err := s.entitlements.Check(ctx, form.TeamID, LimitSubmissions, 1); err != nil {
+		return nil, err
+	}
+
+	if form.PasswordProtected != nil && *form.PasswordProtected {
+		if err := s.checkPassword(ctx, form.ID, body.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	var started *models.Response
+	if body.ResponseID != "" {
+		started, err = s.getStartedResponse(ctx, form.ID, body.ResponseID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	quiz := activeQuiz(snapshot.Metadata)
+	if quiz != nil && quiz.TimeLimitSeconds > 0 {
+		if started == nil || started.StartedAt == nil {
+			return nil, errors.BadRequest("This quiz is timed, start it before submitting")
+		}
+		if now.After(quizDeadline(quiz, *started.StartedAt).Add(quizTimeGrace)) {
+			return nil, errors.BadRequest("The time limit for this quiz has passed")
+		}
+	}
+
+	hidden, fieldErrs := collectHiddenValues(snapshot.HiddenFields, query, body.Hidden)
+	if len(fieldErrs) > 0 {
+		return nil, errors.ValidationFailed("Some hidden fields are invalid", fieldErrs)
+	}
+
+	submitted := body.Answers
+	prefillToken := body.Prefill
+	if prefillToken == "" {
+		prefillToken = query[prefillQueryParam]
+	}
+	var prefill *utils.PrefillClaims
+	if prefillToken != "" {
+		prefill, err = verifyPrefill(s.cfg.Prefill.Secret, prefillToken, form.ID, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		submitted, fieldErrs = applyPrefill(snapshot, prefill, submitted)
+		if len(fieldErrs) > 0 {
+			return nil, errors.ValidationFailed("Some answers are locked", fieldErrs)
+		}
+	}
+
+	answers, fieldErrs := validateAnswers(form.ID, snapshot, submitted, hidden)
+	if len(fieldErrs) > 0 {
+		return nil, errors.ValidationFailed("Some answers are invalid", fieldErrs)
+	}
+
+	flow := newFormFlow(snapshot)
+	state := flow.newState(answers, hidden)
+
+	meta := map[string]interface{}{
+		"formVersion":   version.VersionNumber,
+		"formVersionId": version.ID,
+	}
+	if len(hidden) > 0 {
+		meta["hidden"] = hidden
+	}
+	if prefill != nil {
+		meta["prefillId"] = prefill.ID
+	}
+	var grade *types.QuizGrade
+	if quiz != nil {
+		grade = gradeResponse(quiz, flow, state)
+		meta["grade"] = grade
+	}
+
+	data, err := json.Marshal(answers)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	variables, err := json.Marshal(state.variables)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	status := models.ResponseStatusCompleted
+	response := &models.Response{
+		ID:          utils.GenerateUUID(),
+		FormID:      form.ID,
+		Data:        datatypes.JSON(data),
+		MetaData:    datatypes.JSON(metaData),
+		Variables:   datatypes.JSON(variables),
+		Status:      &status,
+		Valid:       true,
+		StartedAt:   &now,
+		SubmittedAt: &now,
+	}
+	if started != nil {
+		response.ID = started.ID
+	}
+
+	// The limit is checked again while storing, since other submissions may have
+	// arrived in the meantime
+	if err := s.responseRepo.Submit(ctx, response, started != nil, form.MaxResponses); err != nil {
+		return nil, err
+	}
+	responseId := response.ID
+	s.usage.Record(ctx, form.TeamID, models.UsageEventSubmission, 1, responseId)
+
+	s.closeWhenFull(ctx, form)
+
+	return &types.SubmitResponseResponse{
+		ResponseID:  responseId,
+		SubmittedAt: utils.GetIsoDateTime(&now),
+		Grade:       grade,
+	}, nil
+}
+
+// Start opens a response before the respondent answers, which starts the clock of timed quizzes
+func (s *SubmissionService) Start(ctx context.Context, domain string) (*types.StartResponseResponse, error) {
+
+	form, version, snapshot, err := s.getPublishedSnapshot(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.Status == nil || *form.Status != models.FormStatusPublished {
+		return nil, errors.BadRequest("Form is not accepting responses")
+	}
+	if err := s.entitlements.Check(ctx, form.TeamID, LimitSubmissions, 1); err != nil {
+		return nil, err
+	}
+
+	metaData, err := json.Marshal(map[string]interface{}{
+		"formVersion":   version.VersionNumber,
+		"formVersionId": version.ID,
+	})
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	now := time.Now()
+	status := models.ResponseStatusStarted
+	response := &models.Response{
+		ID:        utils.GenerateUUID(),
+		FormID:    form.ID,
+		MetaData:  datatypes.JSON(metaData),
+		Status:    &status,
+		Valid:     true,
+		StartedAt: &now,
+	}
+
+	created, err := s.responseRepo.Create(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.StartResponseResponse{
+		ResponseID: created.ID,
+		StartedAt:  utils.GetIsoDateTime(created.StartedAt),
+		ExpiresAt:  utils.GetIsoDateTime(quizDeadline(activeQuiz(snapshot.Metadata), now)),
+	}, nil
+}
+
+// closeWhenFull queues the closing of a form that has reached its response limit. The
+// scheduler closes it, so the status change is recorded like any other transition.
+// Failures are logged rather than returned, since the response is already stored and
+// the limit is enforced on submission anyway.
+func (s *SubmissionService) closeWhenFull(ctx context.Context, form *models.Form) {
+	if form.MaxResponses == nil {
+		return
+	}
+
+	count, err := s.responseRepo.CountCompleted(ctx, form.ID)
+	if err != nil {
+		log.Error().Err(err).Str("formId", form.ID).Msg("Failed to count responses of a form with a response limit")
+		return
+	}
+	if count < int64(*form.MaxResponses) {
+		return
+	}
+
+	now := time.Now()
+	err = s.jobRepo.Enqueue(ctx, &models.ScheduledJob{
+		ID:        utils.GenerateUUID(),
+		FormID:    form.ID,
+		Type:      models.ScheduledJobClose,
+		Status:    models.ScheduledJobStatusPending,
+		RunAt:     now,
+		CreatedBy: systemActor,
+		CreatedAt: now,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("formId", form.ID).Msg("Failed to queue the closing of a full form")
+	}
+}
+
+func (s *SubmissionService) getStartedResponse(ctx context.Context, formId string, responseId string) (*models.Response, error) {
+
+	response, err := s.responseRepo.GetById(ctx, responseId)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil || response.FormID != formId {
+		return nil, errors.NotFound("Response")
+	}
+	if response.Status == nil || *response.Status != models.ResponseStatusStarted {
+		return nil, errors.BadRequest("Response has already been submitted")
+	}
+	return response, nil
+}
+
+func (s *SubmissionService) ValidateAnswer(ctx context.Context, domain string, body *types.ValidateAnswerRequest) (*types.ValidateAnswerResponse, error) {
+
+	form, _, snapshot, err := s.getPublishedSnapshot(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	question := findPublishedQuestion(snapshot, body.QuestionID)
+	if question == nil {
+		return nil, errors.NotFound("Question")
+	}
+
+	value, fieldErrs := ValidateAnswer(form.ID, question, body.Value)
+	return &types.ValidateAnswerResponse{
+		Valid:  len(fieldErrs) == 0,
+		Value:  value,
+		Errors: fieldErrs,
+	}, nil
+}
+
+// NextQuestion resolves the question that follows currentQuestionId for the given answers,
+// or the first question when none is given, with its placeholders rendered
+func (s *SubmissionService) NextQuestion(ctx context.Context, domain string, locale string, acceptLanguage string, body *types.NextQuestionRequest) (*types.NextQuestionResponse, error) {
+
+	form, _, snapshot, err := s.getPublishedSnapshot(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := negotiateLocale(locale, acceptLanguage, snapshot.Locales, snapshot.DefaultLocale)
+	snapshot.Questions = translateQuestions(snapshot.Questions, snapshot.Translations[selected])
+
+	flow := newFormFlow(snapshot)
+	hidden, _ := collectHiddenValues(snapshot.HiddenFields, body.Hidden)
+	state := flow.newState(normalizeKnownAnswers(form.ID, flow, body.Answers), hidden)
+
+	var next *types.PublishedQuestion
+	if body.CurrentQuestionID == "" {
+		next = flow.start()
+	} else {
+		if flow.byId[body.CurrentQuestionID] == nil {
+			return nil, errors.NotFound("Question")
+		}
+		next = flow.next(body.CurrentQuestionID, state)
+	}
+
+	res := &types.NextQuestionResponse{
+		Locale:   selected,
+		Finished: next == nil,
+	}
+	if next != nil {
+		res.Question = renderQuestion(next, flow, state)
+	}
+	return res, nil
+}
+
+// getPublishedSnapshot resolves a form by its publish domain and decodes the version it serves
+func (s *SubmissionService) getPublishedSnapshot(ctx context.Context, domain string) (*models.Form, *models.FormVersion, *types.PublishVersionSnapshot, error) {
+
+	form, err := s.formRepo.GetByDomain(ctx, domain)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if form == nil {
+		redirect, err := s.formRepo.GetSlugRedirect(ctx, domain)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if redirect != nil && redirect.Form.Valid && redirect.Form.UniqueSubdomain != nil {
+			return nil, nil, nil, errors.Moved(*redirect.Form.UniqueSubdomain)
+		}
+		return nil, nil, nil, errors.NotFound("Form not found")
+	}
+
+	if form.UniqueSubdomain == nil && form.CustomDomain == nil {
+		return nil, nil, nil, errors.BadRequest("Form does not have a publish URL")
+	}
+
+	if form.PublishedVersionID == nil {
+		return nil, nil, nil, errors.NotFound("Published version not found")
+	}
+	version, err := s.formVersionRepo.GetByID(ctx, *form.PublishedVersionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if version == nil {
+		return nil, nil, nil, errors.NotFound("Published version not found")
+	}
+
+	var snapshot types.PublishVersionSnapshot
+	err = json.Unmarshal([]byte(version.Schema), &snapshot)
+	if err != nil {
+		return nil, nil, nil, errors.Internal(err)
+	}
+
+	return form, version, &snapshot, nil
+}
+
+func (s *SubmissionService) checkPassword(ctx context.Context, formId string, password string) error {
+
+	if password == "" {
+		return errors.Unauthorized("form")
+	}
+
+	passwords, err := s.dashRepo.GetPasswords(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, p := range passwords {
+		if !p.IsValid || p.Password != password {
+			continue
+		}
+		if p.ExpireAt != nil && p.ExpireAt.Before(now) {
+			continue
+		}
+		return nil
+	}
+	return errors.Unauthorized("form")
+}
+
+// validateAnswers checks every submitted answer against its published question and
+// enforces the required questions on the path the answers and hidden fields lead
+// through. Unknown question IDs are rejected.
+func validateAnswers(formId string, snapshot *types.PublishVersionSnapshot, answers map[string]interface{}, hidden map[string]string) (map[string]interface{}, []utils.ValidationErrorResponse) {
+
+	var fieldErrs []utils.ValidationErrorResponse
+	normalized := make(map[string]interface{})
+
+	for questionId := range answers {
+		if findPublishedQuestion(snapshot, questionId) == nil {
+			fieldErrs = append(fieldErrs, fieldError("answers."+questionId, "Question does not exist in this form"))
+		}
+	}
+
+	for i := range snapshot.Questions {
+		question := &snapshot.Questions[i]
+		value, answered := answers[question.ID]
+		if !answered {
+			continue
+		}
+
+		normalizedValue, errs := ValidateAnswer(formId, question, value)
+		if len(errs) > 0 {
+			fieldErrs = append(fieldErrs, errs...)
+			continue
+		}
+		if normalizedValue != nil {
+			normalized[question.ID] = normalizedValue
+		}
+	}
+
+	// With conditional branching a respondent never sees some questions, so only the
+	// required questions on the path taken by these answers must be answered.
+	flow := newFormFlow(snapshot)
+	for _, question := range flow.path(flow.newState(normalized, hidden)) {
+		if _, answered := answers[question.ID]; answered {
+			continue
+		}
+		if !question.Required || !acceptsAnswer(models.QuestionType(question.Type)) {
+			continue
+		}
+		_, errs := ValidateAnswer(formId, question, nil)
+		fieldErrs = append(fieldErrs, errs...)
+	}
+
+	return normalized, fieldErrs
+}
+
+// normalizeKnownAnswers returns the answers that pass validation in their normalized
+// form, so that partially filled forms can still be navigated
+func normalizeKnownAnswers(formId string, flow *formFlow, answers map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(answers))
+	for questionId, value := range answers {
+		question := flow.byId[questionId]
+		if question == nil {
+			continue
+		}
+		normalizedValue, errs := ValidateAnswer(formId, question, value)
+		if len(errs) == 0 && normalizedValue != nil {
+			normalized[questionId] = normalizedValue
+		}
+	}
+	return normalized
+}
+
+func findPublishedQuestion(snapshot *types.PublishVersionSnapshot, questionId string) *types.PublishedQuestion {
+	for i := range snapshot.Questions {
+		if snapshot.Questions[i].ID == questionId {
+			return &snapshot.Questions[i]
+		}
+	}
+	return nil
+}
+
+func acceptsAnswer(questionType models.QuestionType) bool {
+	switch questionType {
+	case models.QuestionTypeScreenWelcome, models.QuestionTypeScreenEnd,
+		models.QuestionTypeScreenStatement, models.QuestionTypeRedirectToUrl:
+		return false
+	}
+	return true
+}
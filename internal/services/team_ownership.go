@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/mapper"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+// GetOwnershipTransfer returns the team's pending ownership transfer
+func (ts *TeamService) GetOwnershipTransfer(ctx context.Context, userId string, teamId string) (*types.OwnershipTransferResponse, error) {
+
+	if _, err := ts.permissions.CheckTeam(ctx, userId, teamId, PermissionView); err != nil {
+		return nil, err
+	}
+
+	transfer, err := ts.transferRepo.GetPending(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, errors.NotFound("Ownership transfer")
+	}
+	return mapper.ToOwnershipTransferResponse(transfer), nil
+}
+
+// TransferOwnership offers the team to another member. Nothing changes until they
+// accept; the owner can cancel the offer meanwhile.
+func (ts *TeamService) TransferOwnership(ctx context.Context, userId string, teamId string, body *types.TransferOwnershipRequest) (*types.OwnershipTransferResponse, error) {
+
+	team, err := ts.permissions.CheckTeam(ctx, userId, teamId, PermissionTransferOwnership)
+	if err != nil {
+		return nil, err
+	}
+	if body.UserID == userId {
+		return nil, errors.BadRequest("You already own this team")
+	}
+
+	member, err := ts.memberRepo.Get(ctx, teamId, body.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.BadRequest("Ownership can only be transferred to a member of the team")
+	}
+
+	transfer := &models.TeamOwnershipTransfer{
+		ID:         utils.GenerateUUID(),
+		TeamID:     team.ID,
+		FromUserID: userId,
+		ToUserID:   body.UserID,
+		Status:     models.OwnershipTransferStatusPending,
+		ExpiresAt:  time.Now().Add(ts.cfg.Teams.TransferExpiry),
+		CreatedAt:  *utils.GetCurrentTime(),
+	}
+	if err := ts.transferRepo.Create(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceOwnershipTransfer,
+		ResourceID:   transfer.ID,
+		Action:       models.AuditActionCreate,
+		After:        transfer,
+	})
+	return mapper.ToOwnershipTransferResponse(transfer), nil
+}
+
+func (ts *TeamService) CancelOwnershipTransfer(ctx context.Context, userId string, teamId string) error {
+
+	if _, err := ts.permissions.CheckTeam(ctx, userId, teamId, PermissionTransferOwnership); err != nil {
+		return err
+	}
+
+	transfer, err := ts.transferRepo.GetPending(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if transfer == nil {
+		return errors.NotFound("Ownership transfer")
+	}
+
+	return ts.respondToTransfer(ctx, transfer, models.OwnershipTransferStatusCancelled, models.AuditActionCancel)
+}
+
+// AcceptOwnershipTransfer makes the user the owner of the team they were offered. The
+// previous owner stays on as an admin.
+func (ts *TeamService) AcceptOwnershipTransfer(ctx context.Context, userId string, teamId string) (*types.OwnershipTransferResponse, error) {
+
+	team, transfer, err := ts.getOfferedTransfer(ctx, userId, teamId)
+	if err != nil {
+		return nil, err
+	}
+	if !transfer.ExpiresAt.After(time.Now()) {
+		return nil, errors.BadRequest("This ownership transfer has expired, ask for a new one")
+	}
+
+	role, err := ts.permissions.Role(ctx, userId, team)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, errors.Conflict("You are no longer a member of this team")
+	}
+
+	if err := ts.transferRepo.Accept(ctx, transfer); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	transfer.Status = models.OwnershipTransferStatusAccepted
+	transfer.RespondedAt = &now
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceOwnershipTransfer,
+		ResourceID:   transfer.ID,
+		Action:       models.AuditActionAccept,
+		After:        map[string]interface{}{"status": transfer.Status},
+	})
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceTeam,
+		ResourceID:   team.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       team,
+		After:        map[string]interface{}{"ownerId": userId},
+	})
+	return mapper.ToOwnershipTransferResponse(transfer), nil
+}
+
+func (ts *TeamService) DeclineOwnershipTransfer(ctx context.Context, userId string, teamId string) error {
+
+	_, transfer, err := ts.getOfferedTransfer(ctx, userId, teamId)
+	if err != nil {
+		return err
+	}
+	return ts.respondToTransfer(ctx, transfer, models.OwnershipTransferStatusDeclined, models.AuditActionDecline)
+}
+
+// LeaveTeam removes the user's own membership. The owner has to hand the team over
+// first so that it is never left without one.
+func (ts *TeamService) LeaveTeam(ctx context.Context, userId string, teamId string) error {
+
+	team, err := ts.teamRepo.GetById(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return errors.NotFound("Team")
+	}
+
+	role, err := ts.permissions.Role(ctx, userId, team)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return errors.NotFound("Team")
+	}
+	if role == models.TeamRoleOwner {
+		return errors.BadRequest("Transfer ownership of the team before leaving it")
+	}
+
+	member, err := ts.memberRepo.Get(ctx, teamId, userId)
+	if err != nil {
+		return err
+	}
+	if err := ts.memberRepo.Delete(ctx, teamId, userId); err != nil {
+		return err
+	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamId,
+		ResourceType: models.AuditResourceMember,
+		ResourceID:   userId,
+		Action:       models.AuditActionLeave,
+		Before:       member,
+	})
+	return nil
+}
+
+// OverrideOwner lets support staff hand a team to a user without the owner, for when
+// the owner is gone. The user joins the team if they are not a member yet.
+func (ts *TeamService) OverrideOwner(ctx context.Context, userId string, teamId string, body *types.OverrideOwnerRequest) error {
+
+	if !ts.permissions.IsSupportAdmin(ctx, userId) {
+		return errors.Unauthorized("")
+	}
+
+	team, err := ts.teamRepo.GetById(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return errors.NotFound("Team")
+	}
+	if team.OwnerID != nil && *team.OwnerID == body.UserID {
+		return errors.BadRequest("The user already owns this team")
+	}
+
+	if err := ts.transferRepo.SetOwner(ctx, team, body.UserID); err != nil {
+		return err
+	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceTeam,
+		ResourceID:   team.ID,
+		Action:       models.AuditActionOverride,
+		Before:       map[string]interface{}{"ownerId": team.OwnerID},
+		After:        map[string]interface{}{"ownerId": body.UserID, "reason": body.Reason},
+	})
+	return nil
+}
+
+// getOfferedTransfer loads the team's pending transfer when it is offered to the user
+func (ts *TeamService) getOfferedTransfer(ctx context.Context, userId string, teamId string) (*models.Team, *models.TeamOwnershipTransfer, error) {
+
+	team, err := ts.teamRepo.GetById(ctx, teamId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if team == nil {
+		return nil, nil, errors.NotFound("Ownership transfer")
+	}
+
+	transfer, err := ts.transferRepo.GetPending(ctx, teamId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transfer == nil || transfer.ToUserID != userId {
+		return nil, nil, errors.NotFound("Ownership transfer")
+	}
+	return team, transfer, nil
+}
+
+// respondToTransfer closes a pending transfer without moving ownership
+func (ts *TeamService) respondToTransfer(ctx context.Context, transfer *models.TeamOwnershipTransfer, status models.OwnershipTransferStatus, action models.AuditAction) error {
+
+	updated, err := ts.transferRepo.UpdatePending(ctx, transfer.ID, map[string]interface{}{
+		"status":      status,
+		"respondedAt": time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errors.Conflict("This ownership transfer is no longer pending")
+	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       transfer.TeamID,
+		ResourceType: models.AuditResourceOwnershipTransfer,
+		ResourceID:   transfer.ID,
+		Action:       action,
+		Before:       map[string]interface{}{"status": transfer.Status},
+		After:        map[string]interface{}{"status": status},
+	})
+	return nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
@@ -15,15 +16,37 @@ type ITeamService interface {
 	Create(ctx context.Context, userId string, team *types.TeamRequest) error
 	Update(ctx context.Context, userId string, team *types.TeamRequest) error
 	Delete(ctx context.Context, userId string, teamId string) error
+
+	GetMembers(ctx context.Context, userId string, teamId string) ([]*types.TeamMemberResponse, error)
+	UpdateMemberRole(ctx context.Context, userId string, teamId string, memberId string, body *types.UpdateMemberRoleRequest) error
+	RemoveMember(ctx context.Context, userId string, teamId string, memberId string) error
+	LeaveTeam(ctx context.Context, userId string, teamId string) error
+
+	GetOwnershipTransfer(ctx context.Context, userId string, teamId string) (*types.OwnershipTransferResponse, error)
+	TransferOwnership(ctx context.Context, userId string, teamId string, body *types.TransferOwnershipRequest) (*types.OwnershipTransferResponse, error)
+	CancelOwnershipTransfer(ctx context.Context, userId string, teamId string) error
+	AcceptOwnershipTransfer(ctx context.Context, userId string, teamId string) (*types.OwnershipTransferResponse, error)
+	DeclineOwnershipTransfer(ctx context.Context, userId string, teamId string) error
+	OverrideOwner(ctx context.Context, userId string, teamId string, body *types.OverrideOwnerRequest) error
 }
 
 type TeamService struct {
-	teamRepo repositories.ITeamRepo
+	teamRepo     repositories.ITeamRepo
+	memberRepo   repositories.IMemberRepo
+	transferRepo repositories.ITransferRepo
+	permissions  IPermissionService
+	audit        IAuditService
+	cfg          *config.Config
 }
 
-func NewTeamService(teamRepo repositories.ITeamRepo) *TeamService {
+func NewTeamService(teamRepo repositories.ITeamRepo, memberRepo repositories.IMemberRepo, transferRepo repositories.ITransferRepo, permissions IPermissionService, audit IAuditService, cfg *config.Config) *TeamService {
 	return &TeamService{
-		teamRepo: teamRepo,
+		teamRepo:     teamRepo,
+		memberRepo:   memberRepo,
+		transferRepo: transferRepo,
+		permissions:  permissions,
+		audit:        audit,
+		cfg:          cfg,
 	}
 }
 
@@ -51,6 +74,14 @@ func (ts *TeamService) Create(ctx context.Context, userId string, team *types.Te
 	if err != nil {
 		return err
 	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       newTeam.ID,
+		ResourceType: models.AuditResourceTeam,
+		ResourceID:   newTeam.ID,
+		Action:       models.AuditActionCreate,
+		After:        newTeam,
+	})
 	return nil
 }
 
@@ -65,8 +96,8 @@ func (ts *TeamService) Update(ctx context.Context, userId string, team *types.Te
 		return errors.NotFound("Team")
 	}
 
-	if teamDb.OwnerID == nil || *teamDb.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := ts.permissions.Check(ctx, userId, teamDb, PermissionManage); err != nil {
+		return err
 	}
 
 	err = ts.teamRepo.Update(ctx, &models.Team{
@@ -77,6 +108,15 @@ func (ts *TeamService) Update(ctx context.Context, userId string, team *types.Te
 	if err != nil {
 		return err
 	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamDb.ID,
+		ResourceType: models.AuditResourceTeam,
+		ResourceID:   teamDb.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       teamDb,
+		After:        map[string]interface{}{"name": team.Name},
+	})
 	return nil
 }
 
@@ -89,13 +129,141 @@ func (ts *TeamService) Delete(ctx context.Context, userId string, teamId string)
 	if teamDb == nil {
 		return errors.NotFound("Team")
 	}
-	if teamDb.OwnerID == nil || *teamDb.OwnerID != userId {
-		return errors.Unauthorized("")
+	if err := ts.permissions.Check(ctx, userId, teamDb, PermissionDeleteTeam); err != nil {
+		return err
 	}
 
 	err = ts.teamRepo.Delete(ctx, teamId)
 	if err != nil {
 		return err
 	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamDb.ID,
+		ResourceType: models.AuditResourceTeam,
+		ResourceID:   teamDb.ID,
+		Action:       models.AuditActionDelete,
+		Before:       teamDb,
+	})
+	return nil
+}
+
+func (ts *TeamService) GetMembers(ctx context.Context, userId string, teamId string) ([]*types.TeamMemberResponse, error) {
+
+	team, err := ts.permissions.CheckTeam(ctx, userId, teamId, PermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := ts.memberRepo.GetByTeam(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*types.TeamMemberResponse, 0, len(members)+1)
+	hasOwner := false
+	for _, member := range members {
+		role := member.Role
+		if team.OwnerID != nil && member.UserID == *team.OwnerID {
+			role = models.TeamRoleOwner
+			hasOwner = true
+		}
+		resp = append(resp, &types.TeamMemberResponse{
+			UserID:    member.UserID,
+			Role:      string(role),
+			CreatedAt: utils.GetIsoDateTime(&member.CreatedAt),
+		})
+	}
+	// Teams created before memberships existed only know their owner through OwnerID
+	if !hasOwner && team.OwnerID != nil {
+		resp = append([]*types.TeamMemberResponse{{
+			UserID:    *team.OwnerID,
+			Role:      string(models.TeamRoleOwner),
+			CreatedAt: utils.GetIsoDateTime(&team.CreatedAt),
+		}}, resp...)
+	}
+	return resp, nil
+}
+
+// UpdateMemberRole changes a member's role. Members can only manage those below them and
+// grant roles below their own; ownership changes hands through a transfer instead.
+func (ts *TeamService) UpdateMemberRole(ctx context.Context, userId string, teamId string, memberId string, body *types.UpdateMemberRoleRequest) error {
+
+	actorRole, member, err := ts.getManagedMember(ctx, userId, teamId, memberId)
+	if err != nil {
+		return err
+	}
+
+	role := models.TeamRole(body.Role)
+	if roleRank(role) >= roleRank(actorRole) {
+		return errors.Unauthorized("")
+	}
+	if member.Role == role {
+		return nil
+	}
+	if err := ts.memberRepo.UpdateRole(ctx, teamId, memberId, role); err != nil {
+		return err
+	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamId,
+		ResourceType: models.AuditResourceMember,
+		ResourceID:   memberId,
+		Action:       models.AuditActionUpdate,
+		Before:       member,
+		After:        map[string]interface{}{"role": role},
+	})
+	return nil
+}
+
+func (ts *TeamService) RemoveMember(ctx context.Context, userId string, teamId string, memberId string) error {
+
+	_, member, err := ts.getManagedMember(ctx, userId, teamId, memberId)
+	if err != nil {
+		return err
+	}
+	if err := ts.memberRepo.Delete(ctx, teamId, memberId); err != nil {
+		return err
+	}
+
+	ts.audit.Record(ctx, &AuditEvent{
+		TeamID:       teamId,
+		ResourceType: models.AuditResourceMember,
+		ResourceID:   memberId,
+		Action:       models.AuditActionDelete,
+		Before:       member,
+	})
 	return nil
 }
+
+// getManagedMember loads a member the user may manage, returning the user's own role too
+func (ts *TeamService) getManagedMember(ctx context.Context, userId string, teamId string, memberId string) (models.TeamRole, *models.TeamMember, error) {
+
+	team, err := ts.permissions.CheckTeam(ctx, userId, teamId, PermissionManageMembers)
+	if err != nil {
+		return "", nil, err
+	}
+	if memberId == userId {
+		return "", nil, errors.BadRequest("You cannot change your own membership")
+	}
+
+	member, err := ts.memberRepo.Get(ctx, teamId, memberId)
+	if err != nil {
+		return "", nil, err
+	}
+	if member == nil {
+		return "", nil, errors.NotFound("Member")
+	}
+	if team.OwnerID != nil && *team.OwnerID == memberId {
+		member.Role = models.TeamRoleOwner
+	}
+
+	actorRole, err := ts.permissions.Role(ctx, userId, team)
+	if err != nil {
+		return "", nil, err
+	}
+	if roleRank(member.Role) >= roleRank(actorRole) {
+		return "", nil, errors.Unauthorized("")
+	}
+	return actorRole, member, nil
+}
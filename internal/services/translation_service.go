@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+)
+
+type ITranslationService interface {
+	GetLocales(ctx context.Context, userId string, formId string) ([]*models.FormLocale, error)
+	CreateLocale(ctx context.Context, userId string, formId string, body *types.CreateLocaleRequest) (*models.FormLocale, error)
+	DeleteLocale(ctx context.Context, userId string, formId string, locale string) error
+	SetDefaultLocale(ctx context.Context, userId string, formId string, locale string) error
+
+	GetTranslations(ctx context.Context, userId string, formId string, locale string) ([]*models.FormTranslation, error)
+	UpsertTranslations(ctx context.Context, userId string, formId string, locale string, body *types.UpsertTranslationsRequest) error
+	GetCompleteness(ctx context.Context, userId string, formId string) (*types.TranslationCompletenessResponse, error)
+}
+
+type TranslationService struct {
+	translationRepo repositories.ITranslationRepo
+	formRepo        repositories.IFormRepo
+	questionRepo    repositories.IQuestionRepo
+	permissions     IPermissionService
+	audit           IAuditService
+}
+
+func NewTranslationService(translationRepo repositories.ITranslationRepo, formRepo repositories.IFormRepo, questionRepo repositories.IQuestionRepo, permissions IPermissionService, audit IAuditService) *TranslationService {
+	return &TranslationService{
+		translationRepo: translationRepo,
+		formRepo:        formRepo,
+		questionRepo:    questionRepo,
+		permissions:     permissions,
+		audit:           audit,
+	}
+}
+
+func (s *TranslationService) GetLocales(ctx context.Context, userId string, formId string) ([]*models.FormLocale, error) {
+
+	if _, err := s.getForm(ctx, userId, formId, PermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.translationRepo.GetLocales(ctx, formId)
+}
+
+func (s *TranslationService) CreateLocale(ctx context.Context, userId string, formId string, body *types.CreateLocaleRequest) (*models.FormLocale, error) {
+
+	form, err := s.getForm(ctx, userId, formId, PermissionEditForms)
+	if err != nil {
+		return nil, err
+	}
+
+	locale, ok := normalizeLocale(body.Locale)
+	if !ok {
+		return nil, errors.ValidationFailed("", []utils.ValidationErrorResponse{
+			fieldError("locale", "locale must be a language tag such as en or pt-BR"),
+		})
+	}
+
+	existing, err := s.translationRepo.GetLocales(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range existing {
+		if l.Locale == locale {
+			return nil, errors.Conflict("Locale already exists")
+		}
+	}
+
+	formLocale := &models.FormLocale{
+		ID:     utils.GenerateUUID(),
+		FormID: formId,
+		Locale: locale,
+		// The first locale of a form describes its source content
+		IsDefault: body.IsDefault || len(existing) == 0,
+	}
+
+	created, err := s.translationRepo.CreateLocale(ctx, formLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceLocale,
+		ResourceID:   created.ID,
+		Action:       models.AuditActionCreate,
+		After:        created,
+	})
+	return created, nil
+}
+
+func (s *TranslationService) DeleteLocale(ctx context.Context, userId string, formId string, locale string) error {
+
+	form, err := s.getForm(ctx, userId, formId, PermissionEditForms)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.translationRepo.GetLocales(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	target := findLocale(existing, locale)
+	if target == nil {
+		return errors.NotFound("Locale")
+	}
+	if target.IsDefault && len(existing) > 1 {
+		return errors.BadRequest("Set another default locale before deleting this one")
+	}
+
+	if err := s.translationRepo.DeleteLocale(ctx, formId, target.Locale); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceLocale,
+		ResourceID:   target.ID,
+		Action:       models.AuditActionDelete,
+		Before:       target,
+	})
+	return nil
+}
+
+func (s *TranslationService) SetDefaultLocale(ctx context.Context, userId string, formId string, locale string) error {
+
+	form, err := s.getForm(ctx, userId, formId, PermissionEditForms)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.translationRepo.GetLocales(ctx, formId)
+	if err != nil {
+		return err
+	}
+
+	target := findLocale(existing, locale)
+	if target == nil {
+		return errors.NotFound("Locale")
+	}
+
+	if err := s.translationRepo.SetDefaultLocale(ctx, formId, target.Locale); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceLocale,
+		ResourceID:   target.ID,
+		Action:       models.AuditActionUpdate,
+		Before:       target,
+		After:        map[string]interface{}{"isDefault": true},
+	})
+	return nil
+}
+
+func (s *TranslationService) GetTranslations(ctx context.Context, userId string, formId string, locale string) ([]*models.FormTranslation, error) {
+
+	if _, err := s.getForm(ctx, userId, formId, PermissionView); err != nil {
+		return nil, err
+	}
+
+	if locale != "" {
+		normalized, ok := normalizeLocale(locale)
+		if !ok {
+			return nil, errors.BadRequest("Invalid locale")
+		}
+		locale = normalized
+	}
+
+	return s.translationRepo.GetTranslations(ctx, formId, locale)
+}
+
+// UpsertTranslations stores translations for one locale. An empty value removes the translation.
+func (s *TranslationService) UpsertTranslations(ctx context.Context, userId string, formId string, locale string, body *types.UpsertTranslationsRequest) error {
+
+	form, err := s.getForm(ctx, userId, formId, PermissionEditForms)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.translationRepo.GetLocales(ctx, formId)
+	if err != nil {
+		return err
+	}
+	target := findLocale(existing, locale)
+	if target == nil {
+		return errors.NotFound("Locale")
+	}
+	if target.IsDefault {
+		return errors.BadRequest("The default locale is edited through the form and its questions")
+	}
+
+	questions, err := s.questionRepo.GetQuestions(ctx, formId)
+	if err != nil {
+		return err
+	}
+	resources := translatableResources(formId, questions)
+
+	var fieldErrs []utils.ValidationErrorResponse
+	var upserts, deletes []*models.FormTranslation
+	changed := make(map[string]interface{}, len(body.Translations))
+	for i, item := range body.Translations {
+		if resources[item.ResourceID] != item.ResourceType {
+			fieldErrs = append(fieldErrs, fieldError(fmt.Sprintf("translations[%d].resourceId", i), "resource does not belong to this form"))
+			continue
+		}
+		if !isTranslatableField(item.ResourceType, item.Field) {
+			fieldErrs = append(fieldErrs, fieldError(fmt.Sprintf("translations[%d].field", i), "field cannot be translated for "+string(item.ResourceType)))
+			continue
+		}
+
+		translation := &models.FormTranslation{
+			ID:           utils.GenerateUUID(),
+			FormID:       formId,
+			Locale:       target.Locale,
+			ResourceType: item.ResourceType,
+			ResourceID:   item.ResourceID,
+			Field:        item.Field,
+			Value:        item.Value,
+		}
+		changed[string(item.ResourceType)+":"+item.ResourceID+"."+item.Field] = item.Value
+		if item.Value == "" {
+			deletes = append(deletes, translation)
+		} else {
+			upserts = append(upserts, translation)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return errors.ValidationFailed("Invalid translations", fieldErrs)
+	}
+
+	for _, translation := range deletes {
+		if err := s.translationRepo.DeleteTranslation(ctx, translation); err != nil {
+			return err
+		}
+	}
+	if err := s.translationRepo.UpsertTranslations(ctx, upserts); err != nil {
+		return err
+	}
+
+	// Keyed by RESOURCE_TYPE:resourceId.field; an empty value is a removed translation
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceTranslation,
+		ResourceID:   target.ID,
+		Action:       models.AuditActionUpdate,
+		After:        changed,
+	})
+	return nil
+}
+
+// GetCompleteness reports, per non-default locale, how many of the form's
+// non-empty source strings have a translation and which ones are missing
+func (s *TranslationService) GetCompleteness(ctx context.Context, userId string, formId string) (*types.TranslationCompletenessResponse, error) {
+
+	form, err := s.getForm(ctx, userId, formId, PermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	locales, err := s.translationRepo.GetLocales(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.questionRepo.GetQuestions(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translationRepo.GetTranslations(ctx, formId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	sources := translationSources(form, questions)
+
+	translated := make(map[string]map[string]bool)
+	for _, t := range translations {
+		if t.Value == "" {
+			continue
+		}
+		if translated[t.Locale] == nil {
+			translated[t.Locale] = make(map[string]bool)
+		}
+		translated[t.Locale][translationKey(t.ResourceType, t.ResourceID, t.Field)] = true
+	}
+
+	res := &types.TranslationCompletenessResponse{
+		Locales: make([]*types.LocaleCompleteness, 0, len(locales)),
+	}
+	for _, locale := range locales {
+		if locale.IsDefault {
+			res.DefaultLocale = locale.Locale
+		}
+
+		report := &types.LocaleCompleteness{
+			Locale:    locale.Locale,
+			IsDefault: locale.IsDefault,
+			Total:     len(sources),
+			Missing:   []*types.TranslationSource{},
+		}
+		for _, source := range sources {
+			if locale.IsDefault || translated[locale.Locale][translationKey(source.ResourceType, source.ResourceID, source.Field)] {
+				report.Translated++
+			} else {
+				report.Missing = append(report.Missing, source)
+			}
+		}
+		if report.Total > 0 {
+			report.Percent = math.Round(float64(report.Translated)/float64(report.Total)*10000) / 100
+		} else {
+			report.Percent = 100
+		}
+		res.Locales = append(res.Locales, report)
+	}
+
+	return res, nil
+}
+
+// getForm loads a form after checking the user holds the permission in its team
+func (s *TranslationService) getForm(ctx context.Context, userId string, formId string, permission Permission) (*models.Form, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+	if err := s.permissions.Check(ctx, userId, &form.Team, permission); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+func findLocale(locales []*models.FormLocale, locale string) *models.FormLocale {
+	normalized, ok := normalizeLocale(locale)
+	if !ok {
+		return nil
+	}
+	for _, l := range locales {
+		if l.Locale == normalized {
+			return l
+		}
+	}
+	return nil
+}
+
+// translatableResources maps every resource id of the form to its resource type
+func translatableResources(formId string, questions []*models.Question) map[string]models.TranslationResourceType {
+	resources := map[string]models.TranslationResourceType{
+		formId: models.TranslationResourceForm,
+	}
+	for _, question := range questions {
+		resources[question.ID] = models.TranslationResourceQuestion
+		for _, option := range question.Options {
+			resources[option.ID] = models.TranslationResourceOption
+		}
+	}
+	return resources
+}
+
+// translationSources lists every non-empty string of the form that needs a translation
+func translationSources(form *models.Form, questions []*models.Question) []*types.TranslationSource {
+	var sources []*types.TranslationSource
+	add := func(resourceType models.TranslationResourceType, resourceId string, field string, value string) {
+		if value == "" {
+			return
+		}
+		sources = append(sources, &types.TranslationSource{
+			ResourceType: resourceType,
+			ResourceID:   resourceId,
+			Field:        field,
+			Source:       value,
+		})
+	}
+
+	add(models.TranslationResourceForm, form.ID, "name", form.Name)
+	if form.Description != nil {
+		add(models.TranslationResourceForm, form.ID, "description", *form.Description)
+	}
+
+	for _, question := range questions {
+		add(models.TranslationResourceQuestion, question.ID, "title", question.Title)
+		add(models.TranslationResourceQuestion, question.ID, "description", question.Description)
+		add(models.TranslationResourceQuestion, question.ID, "placeholder", question.Placeholder)
+		for _, option := range question.Options {
+			add(models.TranslationResourceOption, option.ID, "label", option.Label)
+		}
+	}
+	return sources
+}
+
+func translationKey(resourceType models.TranslationResourceType, resourceId string, field string) string {
+	return string(resourceType) + ":" + resourceId + ":" + field
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/storage"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+type ITrashService interface {
+	GetDeletedTeams(ctx context.Context, userId string) ([]*types.TrashItem, error)
+	GetTeamTrash(ctx context.Context, userId string, teamId string) (*types.TeamTrashResponse, error)
+	RestoreTeam(ctx context.Context, userId string, teamId string) error
+	RestoreProject(ctx context.Context, userId string, projectId string) error
+	RestoreForm(ctx context.Context, userId string, formId string) error
+}
+
+type TrashService struct {
+	trashRepo    repositories.ITrashRepo
+	teamRepo     repositories.ITeamRepo
+	projectRepo  repositories.IProjectRepo
+	formRepo     repositories.IFormRepo
+	jobRepo      repositories.IJobRepo
+	permissions  IPermissionService
+	audit        IAuditService
+	entitlements IEntitlementService
+	usage        IUsageService
+	cfg          *config.Config
+}
+
+func NewTrashService(
+	trashRepo repositories.ITrashRepo,
+	teamRepo repositories.ITeamRepo,
+	projectRepo repositories.IProjectRepo,
+	formRepo repositories.IFormRepo,
+	jobRepo repositories.IJobRepo,
+	permissions IPermissionService,
+	audit IAuditService,
+	entitlements IEntitlementService,
+	usage IUsageService,
+	cfg *config.Config,
+) *TrashService {
+	return &TrashService{
+		trashRepo:    trashRepo,
+		teamRepo:     teamRepo,
+		projectRepo:  projectRepo,
+		formRepo:     formRepo,
+		jobRepo:      jobRepo,
+		permissions:  permissions,
+		audit:        audit,
+		entitlements: entitlements,
+		usage:        usage,
+		cfg:          cfg,
+	}
+}
+
+func (s *TrashService) GetDeletedTeams(ctx context.Context, userId string) ([]*types.TrashItem, error) {
+
+	teams, err := s.trashRepo.GetDeletedTeams(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*types.TrashItem, len(teams))
+	for i, team := range teams {
+		name := ""
+		if team.Name != nil {
+			name = *team.Name
+		}
+		items[i] = s.trashItem(team.ID, "team", name, "", team.DeletedAt)
+	}
+	return items, nil
+}
+
+func (s *TrashService) GetTeamTrash(ctx context.Context, userId string, teamId string) (*types.TeamTrashResponse, error) {
+
+	team, err := s.teamRepo.GetById(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+	if team == nil {
+		return nil, errors.NotFound("Team")
+	}
+	if err := s.permissions.Check(ctx, userId, team, PermissionViewSettings); err != nil {
+		return nil, err
+	}
+
+	projects, err := s.trashRepo.GetDeletedProjects(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+	forms, err := s.trashRepo.GetDeletedForms(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.TeamTrashResponse{
+		Projects: make([]*types.TrashItem, len(projects)),
+		Forms:    make([]*types.TrashItem, len(forms)),
+	}
+	for i, project := range projects {
+		resp.Projects[i] = s.trashItem(project.ID, "project", project.Name, "", project.DeletedAt)
+	}
+	for i, form := range forms {
+		resp.Forms[i] = s.trashItem(form.ID, "form", form.Name, form.ProjectID, form.DeletedAt)
+	}
+	return resp, nil
+}
+
+func (s *TrashService) trashItem(id string, itemType string, name string, projectId string, deletedAt *time.Time) *types.TrashItem {
+	var purgeAt *time.Time
+	if deletedAt != nil {
+		at := deletedAt.Add(s.cfg.Trash.Retention)
+		purgeAt = &at
+	}
+	return &types.TrashItem{
+		ID:        id,
+		Type:      itemType,
+		Name:      name,
+		ProjectID: projectId,
+		DeletedAt: utils.GetIsoDateTime(deletedAt),
+		PurgeAt:   utils.GetIsoDateTime(purgeAt),
+	}
+}
+
+// RestoreTeam brings back a deleted team with the projects and forms deleted along with
+// it, as long as they fit the team's plan limits
+func (s *TrashService) RestoreTeam(ctx context.Context, userId string, teamId string) error {
+
+	team, err := s.teamRepo.GetDeleted(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return errors.NotFound("Team")
+	}
+	if err := s.permissions.Check(ctx, userId, team, PermissionDeleteTeam); err != nil {
+		return err
+	}
+
+	var forms []*models.Form
+	if team.DeletedAt != nil {
+		forms, err = s.trashRepo.GetFormsDeletedWith(ctx, "teamId", team.ID, *team.DeletedAt)
+		if err != nil {
+			return err
+		}
+		projects, err := s.trashRepo.CountDeletedProjects(ctx, team.ID, *team.DeletedAt)
+		if err != nil {
+			return err
+		}
+		if err := s.entitlements.CheckTeam(ctx, team, LimitProjects, projects); err != nil {
+			return err
+		}
+		count, err := s.trashRepo.CountDeletedForms(ctx, "teamId", team.ID, *team.DeletedAt)
+		if err != nil {
+			return err
+		}
+		if err := s.entitlements.CheckTeam(ctx, team, LimitForms, count); err != nil {
+			return err
+		}
+	}
+
+	if err := s.teamRepo.Restore(ctx, team); err != nil {
+		return err
+	}
+	s.restoreSchedules(ctx, userId, forms)
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       team.ID,
+		ResourceType: models.AuditResourceTeam,
+		ResourceID:   team.ID,
+		Action:       models.AuditActionRestore,
+	})
+	return nil
+}
+
+// RestoreProject brings back a deleted project with the forms deleted along with it.
+// A project deleted with its team comes back by restoring the team. Restoring counts
+// against the plan limits like creating, so that deleting, re-creating and restoring
+// cannot get around them.
+func (s *TrashService) RestoreProject(ctx context.Context, userId string, projectId string) error {
+
+	project, err := s.projectRepo.GetDeleted(ctx, projectId)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.NotFound("Project")
+	}
+	if err := s.permissions.Check(ctx, userId, &project.Team, PermissionManage); err != nil {
+		return err
+	}
+	if !project.Team.Valid {
+		return errors.Conflict("The project's team is deleted, restore the team first")
+	}
+	if err := s.entitlements.Check(ctx, project.TeamID, LimitProjects, 1); err != nil {
+		return err
+	}
+	var forms []*models.Form
+	if project.DeletedAt != nil {
+		forms, err = s.trashRepo.GetFormsDeletedWith(ctx, "projectId", project.ID, *project.DeletedAt)
+		if err != nil {
+			return err
+		}
+		count, err := s.trashRepo.CountDeletedForms(ctx, "projectId", project.ID, *project.DeletedAt)
+		if err != nil {
+			return err
+		}
+		if err := s.entitlements.Check(ctx, project.TeamID, LimitForms, count); err != nil {
+			return err
+		}
+	}
+
+	if err := s.projectRepo.Restore(ctx, project); err != nil {
+		return err
+	}
+	s.restoreSchedules(ctx, userId, forms)
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       project.TeamID,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   project.ID,
+		Action:       models.AuditActionRestore,
+	})
+	return nil
+}
+
+func (s *TrashService) RestoreForm(ctx context.Context, userId string, formId string) error {
+
+	form, err := s.formRepo.GetDeleted(ctx, formId)
+	if err != nil {
+		return err
+	}
+	if form == nil {
+		return errors.NotFound("Form")
+	}
+	if err := s.permissions.Check(ctx, userId, &form.Team, PermissionManage); err != nil {
+		return err
+	}
+	if !form.Team.Valid {
+		return errors.Conflict("The form's team is deleted, restore the team first")
+	}
+	if !form.Project.Valid {
+		return errors.Conflict("The form's project is deleted, restore the project first")
+	}
+	if err := s.entitlements.Check(ctx, form.TeamID, LimitForms, 1); err != nil {
+		return err
+	}
+
+	if err := s.formRepo.Restore(ctx, form.ID); err != nil {
+		return err
+	}
+	s.restoreSchedules(ctx, userId, []*models.Form{form})
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceForm,
+		ResourceID:   form.ID,
+		Action:       models.AuditActionRestore,
+	})
+	return nil
+}
+
+// restoreSchedules re-creates the OPEN and CLOSE jobs that trashing the forms cancelled.
+// The forms are already restored, so a failure is logged and left to the scheduler,
+// which queues the missing jobs of active forms on its own.
+func (s *TrashService) restoreSchedules(ctx context.Context, userId string, forms []*models.Form) {
+	for _, form := range forms {
+		if err := syncFormSchedule(ctx, s.jobRepo, form.ID, userId, form.OpenAt, form.CloseAt); err != nil {
+			log.Error().Err(err).Str("formId", form.ID).Msg("Failed to restore form schedule")
+		}
+	}
+}
+
+// Purge permanently deletes trashed items older than the retention period. Uploads are
+// removed and their storage released before the form row, so that a failure leaves the
+// form to retry on the next run instead of orphaning its files or its metered storage.
+func (s *TrashService) Purge(ctx context.Context) error {
+
+	before := time.Now().Add(-s.cfg.Trash.Retention)
+
+	formIds, err := s.trashRepo.GetExpiredForms(ctx, before, s.cfg.Trash.PurgeBatch)
+	if err != nil {
+		return err
+	}
+
+	purged := 0
+	for _, formId := range formIds {
+		if err := storage.DeletePrefix(ctx, s.cfg.AWS.BucketName, formUploadPrefix(formId)); err != nil {
+			log.Error().Err(err).Str("formId", formId).Msg("Failed to delete form uploads")
+			continue
+		}
+		if err := s.usage.ReleaseFormStorage(ctx, formId); err != nil {
+			log.Error().Err(err).Str("formId", formId).Msg("Failed to release form storage")
+			continue
+		}
+		if err := s.trashRepo.PurgeForm(ctx, formId); err != nil {
+			log.Error().Err(err).Str("formId", formId).Msg("Failed to purge form")
+			continue
+		}
+		purged++
+	}
+
+	projects, err := s.trashRepo.PurgeProjects(ctx, before)
+	if err != nil {
+		return err
+	}
+	teams, err := s.trashRepo.PurgeTeams(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 || projects > 0 || teams > 0 {
+		log.Info().Int("forms", purged).Int64("projects", projects).Int64("teams", teams).Msg("Purged trash")
+	}
+	return nil
+}
+
+// formUploadPrefix is the S3 prefix holding every upload of a form
+func formUploadPrefix(formId string) string {
+	return fmt.Sprintf("uploads/form_%s/", formId)
+}
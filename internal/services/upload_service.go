@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
 	"github.com/HarshKanjiya/escape-form-api/internal/storage"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
@@ -21,12 +23,22 @@ type IUploadService interface {
 }
 
 type UploadService struct {
-	cfg *config.Config
+	formRepo     repositories.IFormRepo
+	permissions  IPermissionService
+	audit        IAuditService
+	entitlements IEntitlementService
+	usage        IUsageService
+	cfg          *config.Config
 }
 
-func NewUploadService(cfg *config.Config) *UploadService {
+func NewUploadService(formRepo repositories.IFormRepo, permissions IPermissionService, audit IAuditService, entitlements IEntitlementService, usage IUsageService, cfg *config.Config) *UploadService {
 	return &UploadService{
-		cfg: cfg,
+		formRepo:     formRepo,
+		permissions:  permissions,
+		audit:        audit,
+		entitlements: entitlements,
+		usage:        usage,
+		cfg:          cfg,
 	}
 }
 
@@ -68,6 +80,14 @@ func (s *UploadService) GenerateUploadURL(ctx context.Context, userId string, re
 		return nil, errors.BadRequest("Invalid intent. Must be one of: settings, response, question, other")
 	}
 
+	form, err := s.checkFormAccess(ctx, userId, req.FormID, PermissionEditForms)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.entitlements.Check(ctx, form.TeamID, LimitStorage, req.FileSize); err != nil {
+		return nil, err
+	}
+
 	// Generate unique file key: uploads/form_{formid}/{intent}/{fileName}_{uuid}
 	ext := strings.TrimPrefix(filepath.Ext(req.FileName), ".")
 	if ext == "" {
@@ -75,13 +95,14 @@ func (s *UploadService) GenerateUploadURL(ctx context.Context, userId string, re
 		ext = strings.Split(req.FileType, "/")[1]
 	}
 	fileNameWithoutExt := strings.TrimSuffix(req.FileName, filepath.Ext(req.FileName))
-	fileKey := fmt.Sprintf("uploads/form_%s/%s/%s_%s.%s", req.FormID, req.Intent, fileNameWithoutExt, utils.GenerateUUID(), ext)
+	fileKey := formUploadPrefix(req.FormID) + fmt.Sprintf("%s/%s_%s.%s", req.Intent, fileNameWithoutExt, utils.GenerateUUID(), ext)
 
-	// Generate presigned upload URL
-	uploadURL, err := storage.GeneratePresignedUploadURL(ctx, s.cfg.AWS.BucketName, fileKey, expirationMins)
+	// Generate presigned upload URL, bound to the declared size
+	uploadURL, err := storage.GeneratePresignedUploadURL(ctx, s.cfg.AWS.BucketName, fileKey, req.FileSize, expirationMins)
 	if err != nil {
 		return nil, errors.Internal(err)
 	}
+	s.usage.Record(ctx, form.TeamID, models.UsageEventStorageBytes, req.FileSize, form.ID)
 
 	expiresAt := time.Now().Add(time.Duration(expirationMins) * time.Minute).Format(time.RFC3339)
 
@@ -101,10 +122,20 @@ func (s *UploadService) GenerateDownloadURL(ctx context.Context, userId string,
 	}
 
 	// Validate that the file key has the correct format (uploads/form_*/*/)
-	if !strings.HasPrefix(key, "uploads/form_") {
+	formId, intent, ok := parseFileKey(key)
+	if !ok {
 		return nil, errors.BadRequest("Invalid file key format")
 	}
 
+	// Files uploaded with responses are only visible to those who can read responses
+	permission := PermissionView
+	if intent == "response" {
+		permission = PermissionViewResponses
+	}
+	if _, err := s.checkFormAccess(ctx, userId, formId, permission); err != nil {
+		return nil, err
+	}
+
 	// Check if file exists
 	exists, err := storage.CheckObjectExists(ctx, s.cfg.AWS.BucketName, key)
 	if err != nil {
@@ -142,18 +173,69 @@ func (s *UploadService) GenerateDownloadURL(ctx context.Context, userId string,
 func (s *UploadService) DeleteFile(ctx context.Context, userId string, req *types.DeleteFileRequest) (*types.DeleteFileResponse, error) {
 
 	// Validate that the file key has the correct format (uploads/form_*/*)
-	if !strings.HasPrefix(req.FileKey, "uploads/form_") {
+	formId, _, ok := parseFileKey(req.FileKey)
+	if !ok {
 		return nil, errors.BadRequest("Invalid file key format")
 	}
 
+	form, err := s.checkFormAccess(ctx, userId, formId, PermissionEditForms)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := storage.ObjectSize(ctx, s.cfg.AWS.BucketName, req.FileKey)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
 	// Delete the file
-	err := storage.DeleteObject(ctx, s.cfg.AWS.BucketName, req.FileKey)
+	err = storage.DeleteObject(ctx, s.cfg.AWS.BucketName, req.FileKey)
 	if err != nil {
 		return nil, errors.Internal(err)
 	}
+	if size > 0 {
+		s.usage.Record(ctx, form.TeamID, models.UsageEventStorageBytes, -size, formId)
+	}
+
+	s.audit.Record(ctx, &AuditEvent{
+		TeamID:       form.TeamID,
+		ResourceType: models.AuditResourceFile,
+		ResourceID:   req.FileKey,
+		Action:       models.AuditActionDelete,
+		Before:       map[string]interface{}{"formId": formId, "fileKey": req.FileKey},
+	})
 
 	return &types.DeleteFileResponse{
 		Success: true,
 		Message: "File deleted successfully",
 	}, nil
 }
+
+// checkFormAccess checks the permission on the team of the form a file belongs to
+func (s *UploadService) checkFormAccess(ctx context.Context, userId string, formId string, permission Permission) (*models.Form, error) {
+
+	form, err := s.formRepo.GetWithTeam(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+	if form == nil {
+		return nil, errors.NotFound("Form")
+	}
+	if err := s.permissions.Check(ctx, userId, &form.Team, permission); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// parseFileKey splits a key of the form uploads/form_{formId}/{intent}/{file}
+func parseFileKey(key string) (formId string, intent string, ok bool) {
+	rest, found := strings.CutPrefix(key, "uploads/form_")
+	if !found {
+		return "", "", false
+	}
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
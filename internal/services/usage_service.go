@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HarshKanjiya/escape-form-api/internal/config"
+	"github.com/HarshKanjiya/escape-form-api/internal/models"
+	"github.com/HarshKanjiya/escape-form-api/internal/repositories"
+	"github.com/HarshKanjiya/escape-form-api/internal/types"
+	"github.com/HarshKanjiya/escape-form-api/pkg/errors"
+	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// usageLookback is how far back aggregation looks for teams with usage events newer than
+// their usage record, so that events recorded while no instance was aggregating are
+// rolled up
+const usageLookback = 31 * 24 * time.Hour
+
+type IUsageService interface {
+	Record(ctx context.Context, teamId string, eventType models.UsageEventType, quantity int64, refId string)
+	RecordAI(ctx context.Context, event *models.AiUsageEvents)
+	FormStorage(ctx context.Context, formId string) (int64, error)
+	ReleaseFormStorage(ctx context.Context, formId string) error
+	Get(ctx context.Context, userId string, teamId string) (*types.UsageResponse, error)
+}
+
+type UsageService struct {
+	usageRepo    repositories.IUsageRepo
+	teamRepo     repositories.ITeamRepo
+	permissions  IPermissionService
+	entitlements IEntitlementService
+	cfg          *config.Config
+}
+
+func NewUsageService(usageRepo repositories.IUsageRepo, teamRepo repositories.ITeamRepo, permissions IPermissionService, entitlements IEntitlementService, cfg *config.Config) *UsageService {
+	return &UsageService{
+		usageRepo:    usageRepo,
+		teamRepo:     teamRepo,
+		permissions:  permissions,
+		entitlements: entitlements,
+		cfg:          cfg,
+	}
+}
+
+// Record stores a usage event. Like audit entries, failing to record is logged rather
+// than returned, since the usage itself already happened.
+func (s *UsageService) Record(ctx context.Context, teamId string, eventType models.UsageEventType, quantity int64, refId string) {
+
+	event := &models.UsageEvents{
+		ID:        utils.GenerateUUID(),
+		TeamID:    teamId,
+		Type:      eventType,
+		Quantity:  int(quantity),
+		CreatedAt: time.Now().UTC(),
+	}
+	if refId != "" {
+		event.RefID = &refId
+	}
+
+	if err := s.usageRepo.CreateEvent(ctx, event); err != nil {
+		log.Error().Err(err).
+			Str("teamId", teamId).
+			Str("type", string(eventType)).
+			Msg("Failed to record usage event")
+	}
+}
+
+// RecordAI stores an AI call and counts its input and output tokens as usage
+func (s *UsageService) RecordAI(ctx context.Context, event *models.AiUsageEvents) {
+
+	now := time.Now().UTC()
+	if event.ID == "" {
+		event.ID = utils.GenerateUUID()
+	}
+	event.CreatedAt = now
+
+	usage := &models.UsageEvents{
+		ID:        utils.GenerateUUID(),
+		TeamID:    event.TeamID,
+		Type:      models.UsageEventAITokens,
+		Quantity:  event.InputTokens + event.OutputTokens,
+		RefID:     &event.ID,
+		CreatedAt: now,
+	}
+
+	if err := s.usageRepo.CreateAIUsage(ctx, event, usage); err != nil {
+		log.Error().Err(err).
+			Str("teamId", event.TeamID).
+			Str("model", event.Model).
+			Msg("Failed to record AI usage")
+	}
+}
+
+// FormStorage is the storage metered for the uploads of a form
+func (s *UsageService) FormStorage(ctx context.Context, formId string) (int64, error) {
+	return s.usageRepo.SumFormStorage(ctx, formId)
+}
+
+// ReleaseFormStorage stops counting the uploads of a form being purged against its team
+func (s *UsageService) ReleaseFormStorage(ctx context.Context, formId string) error {
+	return s.usageRepo.ReleaseFormStorage(ctx, formId)
+}
+
+// Get is the team's usage in its current billing cycle against its limits
+func (s *UsageService) Get(ctx context.Context, userId string, teamId string) (*types.UsageResponse, error) {
+
+	if _, err := s.permissions.CheckTeam(ctx, userId, teamId, PermissionView); err != nil {
+		return nil, err
+	}
+
+	team, err := s.teamRepo.GetWithPlan(ctx, teamId)
+	if err != nil {
+		return nil, err
+	}
+	if team == nil {
+		return nil, errors.NotFound("Team")
+	}
+	return s.entitlements.Report(ctx, team)
+}
+
+// Aggregate rolls the usage events of the current billing cycle up into a usage record
+// for every team with events newer than its record, and keeps the team's subscription
+// response count and over-usage current. Records are recomputed from the events each
+// time, so running on several instances at once is harmless, and a team that fails
+// keeps its old record and is picked up again on the next run.
+func (s *UsageService) Aggregate(ctx context.Context) error {
+
+	teamIds, err := s.usageRepo.GetTeamsToAggregate(ctx, time.Now().Add(-usageLookback))
+	if err != nil {
+		return err
+	}
+
+	for _, teamId := range teamIds {
+		if err := s.aggregateTeam(ctx, teamId); err != nil {
+			log.Error().Err(err).Str("teamId", teamId).Msg("Failed to aggregate usage")
+		}
+	}
+	return nil
+}
+
+func (s *UsageService) aggregateTeam(ctx context.Context, teamId string) error {
+
+	team, err := s.teamRepo.GetWithPlan(ctx, teamId)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return nil
+	}
+
+	// Events from now on are newer than the record, so the next run rolls them up
+	now := time.Now()
+	start := cycleStart(team, now)
+	totals, err := s.usageRepo.SumEvents(ctx, team.ID, start, cycleEnd(team, now))
+	if err != nil {
+		return err
+	}
+
+	// Storage is not reset by a new cycle, so it is summed over every event
+	storage, err := s.usageRepo.SumStorage(ctx, team.ID)
+	if err != nil {
+		return err
+	}
+
+	responses := int(totals[models.UsageEventSubmission])
+	err = s.usageRepo.SaveRecord(ctx, &models.UsageRecord{
+		ID:            utils.GenerateUUID(),
+		TeamID:        team.ID,
+		CycleStart:    start,
+		FormsUsed:     int(totals[models.UsageEventFormCreated]),
+		ProjectsUsed:  int(totals[models.UsageEventProjectCreated]),
+		ResponsesUsed: responses,
+		StorageUsed:   int(storage),
+		TokensUsed:    int(totals[models.UsageEventAITokens]),
+		Type:          models.TransactionTypeDebit,
+		RecordedAt:    now.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if team.TeamUsage == nil {
+		return nil
+	}
+	overUsage := 0
+	if max, limited := effectiveLimits(team)[LimitSubmissions]; limited && int64(responses) > max {
+		overUsage = responses - int(max)
+	}
+	return s.usageRepo.UpdateSubscription(ctx, team.TeamUsage.ID, responses, overUsage)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -11,6 +12,7 @@ import (
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // Global S3Client
@@ -52,14 +54,17 @@ func Connect(cfg *config.Config) error {
 	return nil
 }
 
-func GeneratePresignedUploadURL(ctx context.Context, bucketName, key string, expirationMinutes int64) (string, error) {
+// GeneratePresignedUploadURL signs a PUT of exactly size bytes; the signature covers
+// Content-Length so that a larger file is rejected
+func GeneratePresignedUploadURL(ctx context.Context, bucketName, key string, size int64, expirationMinutes int64) (string, error) {
 	if S3PresignClient == nil {
 		return "", fmt.Errorf("S3 presign client not initialized")
 	}
 
 	request, err := S3PresignClient.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(key),
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(key),
+		ContentLength: aws.Int64(size),
 	}, func(opts *s3.PresignOptions) {
 		opts.Expires = time.Duration(expirationMinutes) * time.Minute
 	})
@@ -124,3 +129,64 @@ func CheckObjectExists(ctx context.Context, bucketName, key string) (bool, error
 
 	return true, nil
 }
+
+// ObjectSize returns the size of an object, or 0 when it does not exist
+func ObjectSize(ctx context.Context, bucketName, key string) (int64, error) {
+	if S3Client == nil {
+		return 0, fmt.Errorf("S3 client not initialized")
+	}
+
+	output, err := S3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	return aws.ToInt64(output.ContentLength), nil
+}
+
+// DeletePrefix deletes every object whose key starts with prefix
+func DeletePrefix(ctx context.Context, bucketName, prefix string) error {
+	if S3Client == nil {
+		return fmt.Errorf("S3 client not initialized")
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(S3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		objects := make([]types.ObjectIdentifier, len(page.Contents))
+		for i, object := range page.Contents {
+			objects[i] = types.ObjectIdentifier{Key: object.Key}
+		}
+
+		output, err := S3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucketName),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete objects: %w", err)
+		}
+		if len(output.Errors) > 0 {
+			return fmt.Errorf("failed to delete object %s: %s", aws.ToString(output.Errors[0].Key), aws.ToString(output.Errors[0].Message))
+		}
+	}
+
+	return nil
+}
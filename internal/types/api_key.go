@@ -0,0 +1,26 @@
+package types
+
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,oneof=forms:read forms:write responses:read responses:write team:manage"`
+	ExpiresAt string   `json:"expiresAt"`
+}
+
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	TeamID     string   `json:"teamId"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"createdBy"`
+	ExpiresAt  string   `json:"expiresAt"`
+	LastUsedAt string   `json:"lastUsedAt"`
+	RevokedAt  string   `json:"revokedAt"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+// CreateAPIKeyResponse carries the key itself, which is only ever returned on creation
+type CreateAPIKeyResponse struct {
+	*APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,27 @@
+package types
+
+import "encoding/json"
+
+// AuditLogQuery filters the audit log; empty fields match everything
+type AuditLogQuery struct {
+	ActorID      string
+	ResourceType string
+	ResourceID   string
+	Action       string
+	From         string
+	To           string
+}
+
+type AuditLogResponse struct {
+	ID           string          `json:"id"`
+	TeamID       string          `json:"teamId"`
+	ActorID      string          `json:"actorId"`
+	APIKeyID     string          `json:"apiKeyId"`
+	ResourceType string          `json:"resourceType"`
+	ResourceID   string          `json:"resourceId"`
+	Action       string          `json:"action"`
+	Changes      json.RawMessage `json:"changes"`
+	IP           string          `json:"ip"`
+	RequestID    string          `json:"requestId"`
+	CreatedAt    string          `json:"createdAt"`
+}
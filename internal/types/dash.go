@@ -16,6 +16,19 @@ type FormAnalytics struct {
 	CompletionRate     int                 `json:"completionRate"`
 	TodayResponseCount int                 `json:"todayResponseCount"`
 	SubmitDataPoints   []MonthlySubmitData `json:"submitDataPoints"`
+	Variables          []VariableSummary   `json:"variables"`
+}
+
+type VariableSummary struct {
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	Avg   float64 `json:"avg"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+type UpdateResponseRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
 }
 
 type PasswordRequest struct {
@@ -0,0 +1,23 @@
+package types
+
+type SetCustomDomainRequest struct {
+	Domain string `json:"domain" validate:"required,max=253"`
+}
+
+// DNSRecord is a record the form owner has to create at their DNS provider
+type DNSRecord struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Purpose string `json:"purpose"`
+}
+
+type CustomDomainResponse struct {
+	Domain          string       `json:"domain"`
+	Status          string       `json:"status"`
+	Records         []*DNSRecord `json:"records"`
+	CNAMEConfigured bool         `json:"cnameConfigured"`
+	LastError       *string      `json:"lastError"`
+	LastCheckedAt   string       `json:"lastCheckedAt"`
+	VerifiedAt      string       `json:"verifiedAt"`
+}
@@ -0,0 +1,22 @@
+package types
+
+// LimitDetails accompanies a 402 response when a plan limit would be exceeded
+type LimitDetails struct {
+	Limit   string `json:"limit"`
+	Current int64  `json:"current"`
+	Max     int64  `json:"max"`
+}
+
+// UsageResponse is a team's usage in its current billing cycle against its limits
+type UsageResponse struct {
+	CycleStart string        `json:"cycleStart"`
+	CycleEnd   string        `json:"cycleEnd"`
+	Limits     []*LimitUsage `json:"limits"`
+}
+
+// LimitUsage is the usage of one limit; Max is null when the limit is not capped
+type LimitUsage struct {
+	Limit   string `json:"limit"`
+	Current int64  `json:"current"`
+	Max     *int64 `json:"max"`
+}
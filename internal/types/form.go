@@ -43,7 +43,7 @@ type CreateFormRequest struct {
 }
 
 type UpdateFormStatusRequest struct {
-	Status models.FormStatus `json:"status" validate:"required,oneof=DRAFT OPEN CLOSED"`
+	Status models.FormStatus `json:"status" validate:"required,oneof=DRAFT PUBLISHED CLOSED ARCHIVED"`
 }
 
 type UpdateSequenceRequest struct {
@@ -54,3 +54,43 @@ type SequenceItem struct {
 	ID       string `json:"id"`
 	NewOrder int    `json:"newOrder"`
 }
+
+// FormVariable is a numeric variable computed on submit. The built-in "score"
+// variable always holds the sum of the selected option scores.
+type FormVariable struct {
+	Name       string  `json:"name" validate:"required"`
+	Initial    float64 `json:"initial"`
+	Expression string  `json:"expression"`
+}
+
+type UpdateVariablesRequest struct {
+	Variables []*FormVariable `json:"variables" validate:"dive"`
+}
+
+// HiddenField is a value captured from the form URL, such as a UTM parameter or CRM ID
+type HiddenField struct {
+	Name         string `json:"name" validate:"required"`
+	DefaultValue string `json:"defaultValue,omitempty"`
+}
+
+type UpdateHiddenFieldsRequest struct {
+	HiddenFields []*HiddenField `json:"hiddenFields" validate:"dive"`
+}
+
+type UpdateSlugRequest struct {
+	Slug string `json:"slug" validate:"required"`
+}
+
+type SlugAvailabilityResponse struct {
+	Slug      string `json:"slug"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type MoveFormRequest struct {
+	ProjectID string `json:"projectId" validate:"required"`
+}
+
+type SchedulePublishRequest struct {
+	RunAt string `json:"runAt" validate:"required"`
+}
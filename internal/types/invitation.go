@@ -0,0 +1,21 @@
+package types
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+	Role  string `json:"role" validate:"required,oneof=ADMIN EDITOR ANALYST VIEWER"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type InvitationResponse struct {
+	ID        string `json:"id"`
+	TeamID    string `json:"teamId"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	InvitedBy string `json:"invitedBy"`
+	ExpiresAt string `json:"expiresAt"`
+	CreatedAt string `json:"createdAt"`
+}
@@ -0,0 +1,23 @@
+package types
+
+type PrefillFieldRequest struct {
+	QuestionID string      `json:"questionId" validate:"required"`
+	Value      interface{} `json:"value"`
+	Locked     bool        `json:"locked"`
+}
+
+type CreatePrefillTokenRequest struct {
+	Fields           []*PrefillFieldRequest `json:"fields" validate:"required,min=1,dive"`
+	ExpiresInSeconds int                    `json:"expiresInSeconds" validate:"omitempty,min=60"`
+}
+
+type PrefillTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// PrefillValues are the answers of a verified prefill link, as returned to the respondent
+type PrefillValues struct {
+	Answers map[string]interface{} `json:"answers"`
+	Locked  []string               `json:"locked"`
+}
@@ -15,6 +15,25 @@ type PublishVersionSnapshot struct {
 	Metadata            datatypes.JSON               `json:"metadata"`
 	Questions           []PublishedQuestion          `json:"questions"`
 	Edges               []PublishedEdge              `json:"edges"`
+	DefaultLocale       string                       `json:"defaultLocale,omitempty"`
+	Locales             []string                     `json:"locales,omitempty"`
+	Translations        map[string]*PublishedTranslation `json:"translations,omitempty"`
+	HiddenFields        []HiddenField                `json:"hiddenFields,omitempty"`
+}
+
+// PublishedTranslation holds the translated strings of one non-default locale.
+// Anything missing falls back to the default locale content.
+type PublishedTranslation struct {
+	Name        *string                                  `json:"name,omitempty"`
+	Description *string                                  `json:"description,omitempty"`
+	Questions   map[string]*PublishedQuestionTranslation `json:"questions,omitempty"`
+	Options     map[string]string                        `json:"options,omitempty"`
+}
+
+type PublishedQuestionTranslation struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
 }
 
 type PublishedQuestion struct {
@@ -36,6 +55,7 @@ type PublishedQuestionOption struct {
 	Label     string `json:"label"`
 	Value     string `json:"value"`
 	SortOrder int    `json:"sortOrder"`
+	Score     *float64 `json:"score,omitempty"`
 }
 
 type PublishedEdge struct {
@@ -6,11 +6,12 @@ import (
 )
 
 type QuestionOptionRequest struct {
-	ID         string `json:"id"`
-	QuestionID string `json:"questionId" validate:"required"`
-	Label      string `json:"label" validate:"required"`
-	Value      string `json:"value" validate:"required"`
-	SortOrder  int    `json:"sortOrder"`
+	ID         string   `json:"id"`
+	QuestionID string   `json:"questionId" validate:"required"`
+	Label      string   `json:"label" validate:"required"`
+	Value      string   `json:"value" validate:"required"`
+	SortOrder  int      `json:"sortOrder"`
+	Score      *float64 `json:"score"`
 }
 
 type QuestionRequest struct {
@@ -45,9 +46,47 @@ type QuestionResponse struct {
 }
 
 type QueOptionResponse struct {
-	ID         string `json:"id"`
+	ID         string   `json:"id"`
+	QuestionID string   `json:"questionId"`
+	Label      string   `json:"label"`
+	Value      string   `json:"value"`
+	SortOrder  int      `json:"sortOrder"`
+	Score      *float64 `json:"score"`
+}
+
+type BulkCreateOptionsRequest struct {
+	Format  string `json:"format" validate:"required,oneof=lines csv"`
+	Content string `json:"content" validate:"required"`
+}
+
+type OptionItemRequest struct {
+	Label string   `json:"label" validate:"required"`
+	Value string   `json:"value"`
+	Score *float64 `json:"score"`
+}
+
+type ReplaceOptionsRequest struct {
+	Options []*OptionItemRequest `json:"options" validate:"required,dive"`
+}
+
+type TransferQuestionsRequest struct {
+	TargetFormID string   `json:"targetFormId" validate:"required"`
+	QuestionIDs  []string `json:"questionIds" validate:"required,min=1,dive,required"`
+	Mode         string   `json:"mode" validate:"required,oneof=copy move"`
+}
+
+type TransferQuestionsResponse struct {
+	Mode              string              `json:"mode"`
+	TargetFormID      string              `json:"targetFormId"`
+	Questions         []*models.Question  `json:"questions"`
+	IDMap             map[string]string   `json:"idMap"`
+	DroppedEdges      []*EdgeResponse     `json:"droppedEdges"`
+	DroppedReferences []*DroppedReference `json:"droppedReferences"`
+}
+
+// DroppedReference is an edge dropped because its condition refers to a question that
+// does not end up in the same form
+type DroppedReference struct {
+	EdgeID     string `json:"edgeId"`
 	QuestionID string `json:"questionId"`
-	Label      string `json:"label"`
-	Value      string `json:"value"`
-	SortOrder  int    `json:"sortOrder"`
 }
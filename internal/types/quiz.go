@@ -0,0 +1,53 @@
+package types
+
+// QuizSettings is stored under Form.Metadata["quiz"]. The answer key in Questions
+// is never sent to respondents.
+type QuizSettings struct {
+	Enabled            bool                     `json:"enabled"`
+	TimeLimitSeconds   int                      `json:"timeLimitSeconds" validate:"min=0,max=86400"`
+	PassPercent        *float64                 `json:"passPercent" validate:"omitempty,min=0,max=100"`
+	ShowCorrectAnswers bool                     `json:"showCorrectAnswers"`
+	Questions          map[string]*QuizQuestion `json:"questions" validate:"dive"`
+}
+
+// QuizQuestion holds the correct option values, or accepted answers for free-form
+// questions, and the points awarded for a correct answer
+type QuizQuestion struct {
+	Correct []string `json:"correct" validate:"required,min=1"`
+	Points  *float64 `json:"points" validate:"omitempty,min=0"`
+}
+
+type QuizGrade struct {
+	Score     float64                       `json:"score"`
+	MaxScore  float64                       `json:"maxScore"`
+	Percent   float64                       `json:"percent"`
+	Passed    *bool                         `json:"passed,omitempty"`
+	Questions map[string]*QuizQuestionGrade `json:"questions"`
+}
+
+type QuizQuestionGrade struct {
+	Correct bool     `json:"correct"`
+	Points  float64  `json:"points"`
+	Answer  []string `json:"answer,omitempty"`
+}
+
+type StartResponseResponse struct {
+	ResponseID string `json:"responseId"`
+	StartedAt  string `json:"startedAt"`
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+}
+
+type QuestionDifficulty struct {
+	QuestionID     string  `json:"questionId"`
+	Title          string  `json:"title"`
+	Attempts       int     `json:"attempts"`
+	Correct        int     `json:"correct"`
+	PercentCorrect float64 `json:"percentCorrect"`
+}
+
+type QuizReport struct {
+	Responses  int                   `json:"responses"`
+	AvgPercent float64               `json:"avgPercent"`
+	PassRate   *float64              `json:"passRate,omitempty"`
+	Questions  []*QuestionDifficulty `json:"questions"`
+}
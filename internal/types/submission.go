@@ -7,6 +7,13 @@ type GetSubmissionFormResponse struct {
 	FormVersion int    `json:"formVersion"`
 	PublishedAt string `json:"publishedAt"`
 
+	Locale        string   `json:"locale,omitempty"`
+	DefaultLocale string   `json:"defaultLocale,omitempty"`
+	Locales       []string `json:"locales,omitempty"`
+
+	HiddenFields []string       `json:"hiddenFields,omitempty"`
+	Prefill      *PrefillValues `json:"prefill,omitempty"`
+
 	FormMetadata *SubmissionFormMetadata `json:"formMetadata"`
 	Questions    []PublishedQuestion     `json:"questions"`
 	Edges        []PublishedEdge         `json:"edges"`
@@ -24,3 +31,40 @@ type SubmissionFormMetadata struct {
 	FormPageType        string         `json:"formPageType"`
 	Metadata            datatypes.JSON `json:"metadata"`
 }
+
+type SubmitResponseRequest struct {
+	ResponseID string                 `json:"responseId,omitempty"`
+	Answers    map[string]interface{} `json:"answers" validate:"required"`
+	Password   string                 `json:"password,omitempty"`
+	Hidden     map[string]string      `json:"hidden,omitempty"`
+	Prefill    string                 `json:"prefill,omitempty"`
+}
+
+type SubmitResponseResponse struct {
+	ResponseID  string     `json:"responseId"`
+	SubmittedAt string     `json:"submittedAt"`
+	Grade       *QuizGrade `json:"grade,omitempty"`
+}
+
+type ValidateAnswerRequest struct {
+	QuestionID string      `json:"questionId" validate:"required"`
+	Value      interface{} `json:"value"`
+}
+
+type ValidateAnswerResponse struct {
+	Valid  bool        `json:"valid"`
+	Value  interface{} `json:"value"`
+	Errors interface{} `json:"errors"`
+}
+
+type NextQuestionRequest struct {
+	CurrentQuestionID string                 `json:"currentQuestionId"`
+	Answers           map[string]interface{} `json:"answers"`
+	Hidden            map[string]string      `json:"hidden"`
+}
+
+type NextQuestionResponse struct {
+	Locale   string             `json:"locale,omitempty"`
+	Finished bool               `json:"finished"`
+	Question *PublishedQuestion `json:"question"`
+}
@@ -23,6 +23,7 @@ type TeamResponse struct {
 	CreatedAt    string `json:"createdAt"`
 	UpdatedAt    string `json:"updatedAt"`
 	ProjectCount int    `json:"projectCount"`
+	Role         string `json:"role"`
 }
 
 type TeamRequest struct {
@@ -30,3 +31,33 @@ type TeamRequest struct {
 	Name    string  `json:"name"`
 	OwnerID *string `json:"ownerId,omitempty"`
 }
+
+type TeamMemberResponse struct {
+	UserID    string `json:"userId"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=ADMIN EDITOR ANALYST VIEWER"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID string `json:"userId" validate:"required"`
+}
+
+type OverrideOwnerRequest struct {
+	UserID string `json:"userId" validate:"required"`
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type OwnershipTransferResponse struct {
+	ID          string `json:"id"`
+	TeamID      string `json:"teamId"`
+	FromUserID  string `json:"fromUserId"`
+	ToUserID    string `json:"toUserId"`
+	Status      string `json:"status"`
+	ExpiresAt   string `json:"expiresAt"`
+	RespondedAt string `json:"respondedAt"`
+	CreatedAt   string `json:"createdAt"`
+}
@@ -0,0 +1,40 @@
+package types
+
+import "github.com/HarshKanjiya/escape-form-api/internal/models"
+
+type CreateLocaleRequest struct {
+	Locale    string `json:"locale" validate:"required"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+type TranslationItem struct {
+	ResourceType models.TranslationResourceType `json:"resourceType" validate:"required,oneof=FORM QUESTION OPTION"`
+	ResourceID   string                         `json:"resourceId" validate:"required"`
+	Field        string                         `json:"field" validate:"required"`
+	Value        string                         `json:"value"`
+}
+
+type UpsertTranslationsRequest struct {
+	Translations []*TranslationItem `json:"translations" validate:"required,dive"`
+}
+
+type TranslationSource struct {
+	ResourceType models.TranslationResourceType `json:"resourceType"`
+	ResourceID   string                         `json:"resourceId"`
+	Field        string                         `json:"field"`
+	Source       string                         `json:"source"`
+}
+
+type LocaleCompleteness struct {
+	Locale     string               `json:"locale"`
+	IsDefault  bool                 `json:"isDefault"`
+	Total      int                  `json:"total"`
+	Translated int                  `json:"translated"`
+	Percent    float64              `json:"percent"`
+	Missing    []*TranslationSource `json:"missing"`
+}
+
+type TranslationCompletenessResponse struct {
+	DefaultLocale string                `json:"defaultLocale"`
+	Locales       []*LocaleCompleteness `json:"locales"`
+}
@@ -0,0 +1,17 @@
+package types
+
+// TrashItem is a soft-deleted team, project or form. PurgeAt is when the retention job
+// deletes it permanently.
+type TrashItem struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	ProjectID string `json:"projectId,omitempty"`
+	DeletedAt string `json:"deletedAt"`
+	PurgeAt   string `json:"purgeAt"`
+}
+
+type TeamTrashResponse struct {
+	Projects []*TrashItem `json:"projects"`
+	Forms    []*TrashItem `json:"forms"`
+}
@@ -4,6 +4,7 @@ package types
 type GenerateUploadURLRequest struct {
 	FileName       string `json:"fileName" validate:"required"`
 	FileType       string `json:"fileType" validate:"required"`
+	FileSize       int64  `json:"fileSize" validate:"required,gt=0"`
 	FormID         string `json:"formId" validate:"required"`
 	Intent         string `json:"intent" validate:"required,oneof=settings response question other"`
 	ExpirationMins int64  `json:"expirationMins,omitempty"`
@@ -1,13 +1,26 @@
 package errors
 
+import (
+	stderrors "errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
 // Application-level error
 type AppError struct {
 	StatusCode int
 	Code       string
 	Message    string
+	Details    interface{}
 	Err        error
 }
 
 func (e *AppError) Error() string {
 	return e.Message
 }
+
+// IsUniqueViolation reports whether a database error was caused by a unique constraint
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return stderrors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -23,11 +23,12 @@ func Unauthorized(msg string) *AppError {
 	}
 }
 
-func PaymentRequired(msg string) *AppError {
+func PaymentRequired(msg string, details interface{}) *AppError {
 	return &AppError{
 		StatusCode: http.StatusPaymentRequired, // 402
 		Code:       "PAYMENT_REQUIRED",
 		Message:    msg,
+		Details:    details,
 	}
 }
 
@@ -42,6 +43,26 @@ func NotFound(name string) *AppError {
 	}
 }
 
+// Moved tells the client the resource now lives under another slug. The redirect is
+// temporary, since the old slug may be taken again, and keeps the request method. The
+// controller sets the Location header, as only it knows the URL.
+func Moved(slug string) *AppError {
+	return &AppError{
+		StatusCode: http.StatusTemporaryRedirect,
+		Code:       "MOVED",
+		Message:    "Form has moved to " + slug,
+		Details:    map[string]string{"slug": slug},
+	}
+}
+
+func Conflict(msg string) *AppError {
+	return &AppError{
+		StatusCode: http.StatusConflict,
+		Code:       "CONFLICT",
+		Message:    msg,
+	}
+}
+
 func Internal(err error) *AppError {
 	return &AppError{
 		StatusCode: http.StatusInternalServerError,
@@ -50,3 +71,15 @@ func Internal(err error) *AppError {
 		Err:        err,
 	}
 }
+
+func ValidationFailed(msg string, details interface{}) *AppError {
+	if msg == "" {
+		msg = "Validation failed"
+	}
+	return &AppError{
+		StatusCode: http.StatusUnprocessableEntity,
+		Code:       "VALIDATION_FAILED",
+		Message:    msg,
+		Details:    details,
+	}
+}
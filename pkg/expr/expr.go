@@ -0,0 +1,444 @@
+// Package expr parses and evaluates the small arithmetic language used for
+// calculated form variables.
+//
+// Expressions support numbers, identifiers, string literals (only as function
+// arguments), the operators + - * / % with the usual precedence, unary minus,
+// parentheses and function calls such as max(score, 10) or answer("<id>").
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxDepth bounds the nesting of parentheses, calls, operands and unary minus, so that
+// parsing and evaluating cannot recurse without limit
+const maxDepth = 64
+
+// Env supplies identifier values and functions while evaluating
+type Env interface {
+	Var(name string) (float64, bool)
+	Call(name string, args []Value) (float64, error)
+}
+
+// Value is a function argument: either a number or a string literal
+type Value struct {
+	Number   float64
+	Str      string
+	IsString bool
+}
+
+// Call describes a function call found in a program
+type Call struct {
+	Name string
+	Args []Value
+}
+
+// Program is a parsed expression
+type Program struct {
+	source string
+	root   node
+}
+
+// Parse compiles an expression
+func Parse(source string) (*Program, error) {
+	p := &parser{lex: newLexer(source)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tok.text, p.tok.pos)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval runs the program. Division or modulo by zero and non-finite results are errors.
+func (p *Program) Eval(env Env) (float64, error) {
+	result, err := p.root.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("expression result is not a finite number")
+	}
+	return result, nil
+}
+
+// Vars returns the identifiers referenced by the program
+func (p *Program) Vars() []string {
+	var vars []string
+	walk(p.root, func(n node) {
+		if v, ok := n.(*varNode); ok {
+			vars = append(vars, v.name)
+		}
+	})
+	return vars
+}
+
+// Calls returns the function calls made by the program
+func (p *Program) Calls() []Call {
+	var calls []Call
+	walk(p.root, func(n node) {
+		c, ok := n.(*callNode)
+		if !ok {
+			return
+		}
+		call := Call{Name: c.name}
+		for _, arg := range c.args {
+			switch a := arg.(type) {
+			case *stringNode:
+				call.Args = append(call.Args, Value{Str: a.value, IsString: true})
+			case *numberNode:
+				call.Args = append(call.Args, Value{Number: a.value})
+			default:
+				call.Args = append(call.Args, Value{})
+			}
+		}
+		calls = append(calls, call)
+	})
+	return calls
+}
+
+// ---------- AST ----------
+
+type node interface {
+	eval(env Env) (float64, error)
+	children() []node
+}
+
+type numberNode struct{ value float64 }
+
+type stringNode struct{ value string }
+
+type varNode struct{ name string }
+
+type unaryNode struct{ operand node }
+
+type binaryNode struct {
+	op          byte
+	left, right node
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *numberNode) eval(Env) (float64, error) { return n.value, nil }
+func (n *numberNode) children() []node          { return nil }
+
+func (n *stringNode) eval(Env) (float64, error) {
+	return 0, fmt.Errorf("string %q can only be used as a function argument", n.value)
+}
+func (n *stringNode) children() []node { return nil }
+
+func (n *varNode) eval(env Env) (float64, error) {
+	value, ok := env.Var(n.name)
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %q", n.name)
+	}
+	return value, nil
+}
+func (n *varNode) children() []node { return nil }
+
+func (n *unaryNode) eval(env Env) (float64, error) {
+	value, err := n.operand.eval(env)
+	return -value, err
+}
+func (n *unaryNode) children() []node { return []node{n.operand} }
+
+func (n *binaryNode) eval(env Env) (float64, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	case '/':
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return left / right, nil
+	case '%':
+		if right == 0 {
+			return 0, fmt.Errorf("modulo by zero")
+		}
+		return math.Mod(left, right), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", n.op)
+}
+func (n *binaryNode) children() []node { return []node{n.left, n.right} }
+
+func (n *callNode) eval(env Env) (float64, error) {
+	args := make([]Value, len(n.args))
+	for i, arg := range n.args {
+		if s, ok := arg.(*stringNode); ok {
+			args[i] = Value{Str: s.value, IsString: true}
+			continue
+		}
+		value, err := arg.eval(env)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = Value{Number: value}
+	}
+	return env.Call(n.name, args)
+}
+func (n *callNode) children() []node { return n.args }
+
+func walk(n node, visit func(node)) {
+	visit(n)
+	for _, child := range n.children() {
+		walk(child, visit)
+	}
+}
+
+// ---------- Parser ----------
+
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+// enter descends one level of nesting, failing past maxDepth; leave must follow
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("expression is nested too deeply at position %d", p.tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func precedence(op string) int {
+	switch op {
+	case "+", "-":
+		return 1
+	case "*", "/", "%":
+		return 2
+	}
+	return 0
+}
+
+func (p *parser) parseExpr(minPrec int) (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind == tokOperator {
+		prec := precedence(p.tok.text)
+		if prec <= minPrec {
+			break
+		}
+		op := p.tok.text[0]
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseExpr(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokOperator && p.tok.text == "-" {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return &numberNode{value: value}, p.advance()
+
+	case tokString:
+		return &stringNode{value: tok.text}, p.advance()
+
+	case tokIdent:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokLParen {
+			return &varNode{name: tok.text}, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		call := &callNode{name: tok.text}
+		for p.tok.kind != tokRParen {
+			arg, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.tok.kind == tokComma {
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if p.tok.kind != tokRParen {
+				return nil, fmt.Errorf("expected , or ) at position %d", p.tok.pos)
+			}
+		}
+		return call, p.advance()
+
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d", p.tok.pos)
+		}
+		return inner, p.advance()
+
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// ---------- Lexer ----------
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type lexer struct {
+	src []rune
+	pos int
+}
+
+func newLexer(source string) *lexer {
+	return &lexer{src: []rune(source)}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	ch := l.src[l.pos]
+	switch {
+	case unicode.IsDigit(ch) || ch == '.':
+		for l.pos < len(l.src) && (unicode.IsDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: string(l.src[start:l.pos]), pos: start}, nil
+
+	case ch == '_' || unicode.IsLetter(ch):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || unicode.IsLetter(l.src[l.pos]) || unicode.IsDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: string(l.src[start:l.pos]), pos: start}, nil
+
+	case ch == '"' || ch == '\'':
+		l.pos++
+		var sb strings.Builder
+		for l.pos < len(l.src) && l.src[l.pos] != ch {
+			sb.WriteRune(l.src[l.pos])
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("unterminated string at position %d", start)
+		}
+		l.pos++
+		return token{kind: tokString, text: sb.String(), pos: start}, nil
+
+	case strings.ContainsRune("+-*/%", ch):
+		l.pos++
+		return token{kind: tokOperator, text: string(ch), pos: start}, nil
+	case ch == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case ch == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case ch == ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", pos: start}, nil
+	}
+	return token{}, fmt.Errorf("unexpected character %q at position %d", ch, start)
+}
@@ -0,0 +1,153 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testEnv map[string]float64
+
+func (e testEnv) Var(name string) (float64, bool) {
+	value, ok := e[name]
+	return value, ok
+}
+
+func (e testEnv) Call(name string, args []Value) (float64, error) {
+	switch name {
+	case "max":
+		if len(args) == 0 {
+			return 0, fmt.Errorf("max needs an argument")
+		}
+		result := args[0].Number
+		for _, arg := range args[1:] {
+			if arg.Number > result {
+				result = arg.Number
+			}
+		}
+		return result, nil
+	case "len":
+		if len(args) != 1 || !args[0].IsString {
+			return 0, fmt.Errorf("len needs a string")
+		}
+		return float64(len(args[0].Str)), nil
+	}
+	return 0, fmt.Errorf("unknown function %q", name)
+}
+
+func TestEval(t *testing.T) {
+	env := testEnv{"score": 7, "bonus": 2.5, "_private": 1}
+
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"42", 42},
+		{"1.5", 1.5},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"100 / 10 / 5", 2},
+		{"7 % 3", 1},
+		{"-3 + 5", 2},
+		{"--3", 3},
+		{"-(2 * 3)", -6},
+		{"score + bonus", 9.5},
+		{"score * 2 - _private", 13},
+		{"max(score, 10, bonus)", 10},
+		{"max(score * 2, 10)", 14},
+		{"len('abc') + len(\"de\")", 5},
+		{"  score  ", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := program.Eval(env)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"empty", ""},
+		{"dangling operator", "1 +"},
+		{"unclosed parenthesis", "(1 + 2"},
+		{"unclosed call", "max(1, 2"},
+		{"missing comma", "max(1 2)"},
+		{"unterminated string", "len('abc)"},
+		{"unexpected character", "1 & 2"},
+		{"trailing token", "1 2"},
+		{"invalid number", "1.2.3"},
+		{"nested too deeply", strings.Repeat("(", maxDepth+1) + "1" + strings.Repeat(")", maxDepth+1)},
+		{"unary minus too deep", strings.Repeat("-", maxDepth+1) + "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.source); err == nil {
+				t.Errorf("Parse(%q) succeeded, want an error", tt.source)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	env := testEnv{"zero": 0}
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"division by zero", "1 / zero"},
+		{"modulo by zero", "5 % 0"},
+		{"unknown variable", "missing + 1"},
+		{"string operand", "'a' + 1"},
+		{"unknown function", "nope(1)"},
+		{"error inside a call", "max(1 / 0, 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if _, err := program.Eval(env); err == nil {
+				t.Errorf("Eval(%q) succeeded, want an error", tt.source)
+			}
+		})
+	}
+}
+
+func TestVarsAndCalls(t *testing.T) {
+	program, err := Parse(`score + max(bonus, answer("q-1"), 3) * weight`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := program.Vars(), []string{"score", "bonus", "weight"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Vars() = %v, want %v", got, want)
+	}
+
+	want := []Call{
+		{Name: "max", Args: []Value{{}, {}, {Number: 3}}},
+		{Name: "answer", Args: []Value{{Str: "q-1", IsString: true}}},
+	}
+	if got := program.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %#v, want %#v", got, want)
+	}
+}
@@ -1,6 +1,9 @@
 package mapper
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/HarshKanjiya/escape-form-api/internal/models"
 	"github.com/HarshKanjiya/escape-form-api/internal/types"
 	"github.com/HarshKanjiya/escape-form-api/pkg/utils"
@@ -45,6 +48,7 @@ func ToQuestionOptionResponse(option *models.QuestionOption) *types.QueOptionRes
 		Label:      option.Label,
 		Value:      option.Value,
 		SortOrder:  option.SortOrder,
+		Score:      option.Score,
 	}
 }
 
@@ -123,3 +127,78 @@ func ToActivePasswordResponse(password *models.ActivePassword) *types.ActivePass
 		ExpireAt:   utils.GetIsoDateTime(password.ExpireAt),
 	}
 }
+
+// ToInvitationResponse reports a pending invitation past its expiry as expired
+func ToInvitationResponse(invitation *models.TeamInvitation) *types.InvitationResponse {
+	status := invitation.Status
+	if status == models.InvitationStatusPending && !invitation.ExpiresAt.After(time.Now()) {
+		status = models.InvitationStatusExpired
+	}
+	return &types.InvitationResponse{
+		ID:        invitation.ID,
+		TeamID:    invitation.TeamID,
+		Email:     invitation.Email,
+		Role:      string(invitation.Role),
+		Status:    string(status),
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: utils.GetIsoDateTime(&invitation.ExpiresAt),
+		CreatedAt: utils.GetIsoDateTime(&invitation.CreatedAt),
+	}
+}
+
+func ToOwnershipTransferResponse(transfer *models.TeamOwnershipTransfer) *types.OwnershipTransferResponse {
+	status := transfer.Status
+	if status == models.OwnershipTransferStatusPending && !transfer.ExpiresAt.After(time.Now()) {
+		status = models.OwnershipTransferStatusExpired
+	}
+	return &types.OwnershipTransferResponse{
+		ID:          transfer.ID,
+		TeamID:      transfer.TeamID,
+		FromUserID:  transfer.FromUserID,
+		ToUserID:    transfer.ToUserID,
+		Status:      string(status),
+		ExpiresAt:   utils.GetIsoDateTime(&transfer.ExpiresAt),
+		RespondedAt: utils.GetIsoDateTime(transfer.RespondedAt),
+		CreatedAt:   utils.GetIsoDateTime(&transfer.CreatedAt),
+	}
+}
+
+func ToAPIKeyResponse(key *models.APIKey) *types.APIKeyResponse {
+	return &types.APIKeyResponse{
+		ID:         key.ID,
+		TeamID:     key.TeamID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  utils.GetIsoDateTime(key.ExpiresAt),
+		LastUsedAt: utils.GetIsoDateTime(key.LastUsedAt),
+		RevokedAt:  utils.GetIsoDateTime(key.RevokedAt),
+		CreatedAt:  utils.GetIsoDateTime(&key.CreatedAt),
+	}
+}
+
+func ToAuditLogResponse(entry *models.AuditLog) *types.AuditLogResponse {
+	resp := &types.AuditLogResponse{
+		ID:           entry.ID,
+		TeamID:       entry.TeamID,
+		ResourceType: string(entry.ResourceType),
+		ResourceID:   entry.ResourceID,
+		Action:       string(entry.Action),
+		Changes:      json.RawMessage(entry.Changes),
+		CreatedAt:    utils.GetIsoDateTime(&entry.CreatedAt),
+	}
+	if entry.ActorID != nil {
+		resp.ActorID = *entry.ActorID
+	}
+	if entry.APIKeyID != nil {
+		resp.APIKeyID = *entry.APIKeyID
+	}
+	if entry.IP != nil {
+		resp.IP = *entry.IP
+	}
+	if entry.RequestID != nil {
+		resp.RequestID = *entry.RequestID
+	}
+	return resp
+}
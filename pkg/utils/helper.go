@@ -27,6 +27,12 @@ func GetUserId(ctx *fiber.Ctx) (string, bool) {
 	return id, true
 }
 
+// GetFormDomain returns the form domain resolved from the Host header, if any
+func GetFormDomain(ctx *fiber.Ctx) string {
+	domain, _ := ctx.Locals("form_domain").(string)
+	return domain
+}
+
 func GetCurrentTime() *time.Time {
 	now := time.Now().UTC()
 	return &now
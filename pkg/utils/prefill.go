@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const prefillAudience = "prefill"
+
+// errPrefillSecret is returned when PREFILL_SECRET is not set, since tokens signed with
+// an empty or well-known key could be forged by anyone
+var errPrefillSecret = errors.New("PREFILL_SECRET is required")
+
+// PrefillClaims represents the claims of a signed prefill link
+type PrefillClaims struct {
+	FormID  string                 `json:"formId"`
+	Answers map[string]interface{} `json:"answers"`
+	Locked  []string               `json:"locked,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GeneratePrefillToken signs prefilled answers for a form
+func GeneratePrefillToken(tokenId, formId string, answers map[string]interface{}, locked []string, secret string, expiry time.Duration) (string, time.Time, error) {
+	if secret == "" {
+		return "", time.Time{}, errPrefillSecret
+	}
+	now := time.Now()
+	expiresAt := now.Add(expiry)
+	claims := PrefillClaims{
+		FormID:  formId,
+		Answers: answers,
+		Locked:  locked,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			Audience:  jwt.ClaimStrings{prefillAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(secret))
+	return signed, expiresAt, err
+}
+
+// ValidatePrefillToken validates a prefill token and returns its claims
+func ValidatePrefillToken(tokenString, secret string) (*PrefillClaims, error) {
+	if secret == "" {
+		return nil, errPrefillSecret
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &PrefillClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// Verify signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secret), nil
+	}, jwt.WithAudience(prefillAudience), jwt.WithExpirationRequired())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*PrefillClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testPrefillSecret = "test-prefill-secret"
+
+func TestPrefillTokenRoundTrip(t *testing.T) {
+	answers := map[string]interface{}{"q1": "hello", "q2": []interface{}{"a", "b"}}
+	locked := []string{"q1"}
+
+	token, expiresAt, err := GeneratePrefillToken("token-1", "form-1", answers, locked, testPrefillSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(expiresAt); until <= 59*time.Minute || until > time.Hour {
+		t.Errorf("expiresAt is %v from now, want an hour", until)
+	}
+
+	claims, err := ValidatePrefillToken(token, testPrefillSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.ID != "token-1" || claims.FormID != "form-1" {
+		t.Errorf("got token %q for form %q", claims.ID, claims.FormID)
+	}
+	if !reflect.DeepEqual(claims.Answers, answers) {
+		t.Errorf("answers: got %v, want %v", claims.Answers, answers)
+	}
+	if !reflect.DeepEqual(claims.Locked, locked) {
+		t.Errorf("locked: got %v, want %v", claims.Locked, locked)
+	}
+}
+
+func TestValidatePrefillTokenRejects(t *testing.T) {
+	valid, _, err := GeneratePrefillToken("token-1", "form-1", map[string]interface{}{"q1": "x"}, nil, testPrefillSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := GeneratePrefillToken("token-2", "form-1", nil, nil, testPrefillSecret, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.Claims) string {
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	future := jwt.NewNumericDate(time.Now().Add(time.Hour))
+	otherAudience := sign(jwt.SigningMethodHS256, []byte(testPrefillSecret), PrefillClaims{
+		FormID:           "form-1",
+		RegisteredClaims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{"session"}, ExpiresAt: future},
+	})
+	noExpiry := sign(jwt.SigningMethodHS256, []byte(testPrefillSecret), PrefillClaims{
+		FormID:           "form-1",
+		RegisteredClaims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{prefillAudience}},
+	})
+	unsigned := sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, PrefillClaims{
+		FormID:           "form-1",
+		RegisteredClaims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{prefillAudience}, ExpiresAt: future},
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+	}{
+		{"wrong secret", valid, "another-secret"},
+		{"empty secret", valid, ""},
+		{"tampered signature", valid[:len(valid)-4] + "AAAA", testPrefillSecret},
+		{"expired", expired, testPrefillSecret},
+		{"other audience", otherAudience, testPrefillSecret},
+		{"no expiry", noExpiry, testPrefillSecret},
+		{"unsigned", unsigned, testPrefillSecret},
+		{"garbage", "not-a-token", testPrefillSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := ValidatePrefillToken(tt.token, tt.secret); err == nil {
+				t.Errorf("accepted token for form %q", claims.FormID)
+			}
+		})
+	}
+}
+
+func TestGeneratePrefillTokenRequiresSecret(t *testing.T) {
+	if _, _, err := GeneratePrefillToken("token-1", "form-1", nil, nil, "", time.Hour); err == nil {
+		t.Error("signed a token without a secret")
+	}
+}